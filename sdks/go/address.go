@@ -0,0 +1,104 @@
+package openibank
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Address is a structured postal address, required for the creditor of
+// international and, increasingly, SEPA payments. Country and TownName are
+// always required; PostCode is required in countries that use post codes.
+type Address struct {
+	StreetName     *string `json:"street_name,omitempty" redact:"full"`
+	BuildingNumber *string `json:"building_number,omitempty" redact:"full"`
+	PostCode       *string `json:"post_code,omitempty"`
+	TownName       string  `json:"town_name"`
+	Country        Country `json:"country"`
+}
+
+// MaxAddressFieldLength is the maximum length of a street or town name, as
+// set by ISO 20022.
+const MaxAddressFieldLength = 35
+
+// postCodeFormats are the post code formats of countries whose post codes
+// are checked; other countries' post codes are only checked for length.
+var postCodeFormats = map[Country]*regexp.Regexp{
+	CountryAustria:       regexp.MustCompile(`^\d{4}$`),
+	CountryBelgium:       regexp.MustCompile(`^\d{4}$`),
+	CountryDenmark:       regexp.MustCompile(`^\d{4}$`),
+	CountryFinland:       regexp.MustCompile(`^\d{5}$`),
+	CountryFrance:        regexp.MustCompile(`^\d{5}$`),
+	CountryGermany:       regexp.MustCompile(`^\d{5}$`),
+	CountryIreland:       regexp.MustCompile(`^[A-Z]\d[\dW] ?[0-9A-Z]{4}$`),
+	CountryItaly:         regexp.MustCompile(`^\d{5}$`),
+	CountryNetherlands:   regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`),
+	CountryNorway:        regexp.MustCompile(`^\d{4}$`),
+	CountryPoland:        regexp.MustCompile(`^\d{2}-\d{3}$`),
+	CountryPortugal:      regexp.MustCompile(`^\d{4}-\d{3}$`),
+	CountrySpain:         regexp.MustCompile(`^\d{5}$`),
+	CountrySweden:        regexp.MustCompile(`^\d{3} ?\d{2}$`),
+	CountrySwitzerland:   regexp.MustCompile(`^\d{4}$`),
+	CountryUnitedKingdom: regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+	"US":                 regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+}
+
+// noPostCodes lists countries that do not use post codes.
+var noPostCodes = map[Country]bool{
+	"AE": true, "AG": true, "AO": true, "AW": true, "BF": true, "BI": true,
+	"BJ": true, "BO": true, "BS": true, "BW": true, "BZ": true, "CD": true,
+	"CF": true, "CG": true, "CI": true, "CK": true, "CM": true, "DJ": true,
+	"DM": true, "ER": true, "FJ": true, "GA": true, "GD": true, "GH": true,
+	"GM": true, "GQ": true, "GY": true, "HK": true, "JM": true, "KI": true,
+	"KM": true, "KN": true, "KP": true, "LC": true, "ML": true, "MO": true,
+	"MR": true, "MW": true, "NR": true, "NU": true, "QA": true, "RW": true,
+	"SB": true, "SC": true, "SL": true, "SR": true, "ST": true, "SY": true,
+	"TD": true, "TF": true, "TG": true, "TK": true, "TL": true, "TO": true,
+	"TT": true, "TV": true, "UG": true, "VU": true, "YE": true, "ZW": true,
+}
+
+// Validate checks the address against the rules of its country. It returns
+// a *ValidationError listing every invalid field, or nil.
+func (a Address) Validate() error {
+	var errs []FieldError
+	add := func(field, message string) {
+		errs = append(errs, FieldError{Field: "creditor.address." + field, Message: message})
+	}
+	switch {
+	case a.Country == "":
+		add("country", "is required")
+	case !a.Country.Valid():
+		add("country", "must be an ISO 3166-1 alpha-2 code")
+	}
+	switch {
+	case strings.TrimSpace(a.TownName) == "":
+		add("town_name", "is required")
+	case utf8.RuneCountInString(a.TownName) > MaxAddressFieldLength:
+		add("town_name", fmt.Sprintf("must be at most %d characters", MaxAddressFieldLength))
+	}
+	if a.StreetName != nil && utf8.RuneCountInString(*a.StreetName) > MaxAddressFieldLength*2 {
+		add("street_name", fmt.Sprintf("must be at most %d characters", MaxAddressFieldLength*2))
+	}
+	if a.BuildingNumber != nil && utf8.RuneCountInString(*a.BuildingNumber) > 16 {
+		add("building_number", "must be at most 16 characters")
+	}
+
+	postCode := ""
+	if a.PostCode != nil {
+		postCode = strings.ToUpper(strings.TrimSpace(*a.PostCode))
+	}
+	switch {
+	case postCode == "" && a.Country != "" && !noPostCodes[a.Country]:
+		add("post_code", fmt.Sprintf("is required in %s", a.Country))
+	case len(postCode) > 16:
+		add("post_code", "must be at most 16 characters")
+	case postCode != "" && postCodeFormats[a.Country] != nil && !postCodeFormats[a.Country].MatchString(postCode):
+		add("post_code", fmt.Sprintf("is not a valid post code in %s", a.Country))
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Message: "invalid address", Code: ErrCodeInvalidAddress, Errors: errs}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package openibank
+
+// BalanceType represents the kind of an account balance, following the
+// Berlin Group model. Types added to the API after this version of the SDK
+// are kept as is; check them with Valid.
+type BalanceType string
+
+const (
+	// BalanceTypeClosingBooked is the booked balance at the end of the last
+	// business day.
+	BalanceTypeClosingBooked BalanceType = "closingBooked"
+	// BalanceTypeExpected is the booked balance plus pending transactions.
+	BalanceTypeExpected BalanceType = "expected"
+	// BalanceTypeOpeningBooked is the booked balance at the start of the
+	// business day.
+	BalanceTypeOpeningBooked BalanceType = "openingBooked"
+	// BalanceTypeInterimAvailable is the amount available for spending now,
+	// including any credit limit the bank includes.
+	BalanceTypeInterimAvailable BalanceType = "interimAvailable"
+	// BalanceTypeInterimBooked is the balance of the transactions booked so
+	// far during the business day.
+	BalanceTypeInterimBooked BalanceType = "interimBooked"
+	// BalanceTypeForwardAvailable is the balance expected to be available on
+	// a future date.
+	BalanceTypeForwardAvailable BalanceType = "forwardAvailable"
+	// BalanceTypeNonInvoiced is the amount spent on a card that has not been
+	// invoiced yet.
+	BalanceTypeNonInvoiced BalanceType = "nonInvoiced"
+)
+
+// Valid reports whether t is one of the types defined by this package.
+func (t BalanceType) Valid() bool {
+	switch t {
+	case BalanceTypeClosingBooked, BalanceTypeExpected, BalanceTypeOpeningBooked,
+		BalanceTypeInterimAvailable, BalanceTypeInterimBooked, BalanceTypeForwardAvailable,
+		BalanceTypeNonInvoiced:
+		return true
+	}
+	return false
+}
+
+// IsKnown reports whether t is one of the types defined by this package.
+// It is the same as Valid.
+func (t BalanceType) IsKnown() bool {
+	return t.Valid()
+}
+
+// Balances is the list of balances of an account, of different types.
+type Balances []Balance
+
+// Available returns the balance best describing the amount available for
+// spending: interimAvailable, or else expected or forwardAvailable.
+func (b Balances) Available() (Balance, bool) {
+	return b.first(BalanceTypeInterimAvailable, BalanceTypeExpected, BalanceTypeForwardAvailable)
+}
+
+// Booked returns the most recent booked balance: interimBooked, or else
+// closingBooked or openingBooked.
+func (b Balances) Booked() (Balance, bool) {
+	return b.first(BalanceTypeInterimBooked, BalanceTypeClosingBooked, BalanceTypeOpeningBooked)
+}
+
+// OfType returns the first balance of the given type.
+func (b Balances) OfType(t BalanceType) (Balance, bool) {
+	return b.first(t)
+}
+
+// first returns the first balance of the earliest of types that is present.
+func (b Balances) first(types ...BalanceType) (Balance, bool) {
+	for _, t := range types {
+		for _, balance := range b {
+			if balance.Type == t {
+				return balance, true
+			}
+		}
+	}
+	return Balance{}, false
+}
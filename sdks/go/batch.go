@@ -0,0 +1,59 @@
+package openibank
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BatchItem is the outcome of one item of a batch operation. Key
+// identifies the item, such as a consent or account ID. Value is set when
+// the item succeeded and Err when it failed.
+type BatchItem[T any] struct {
+	Key   string
+	Value T
+	Err   error
+}
+
+// BatchResult is the outcome of a batch operation that can partially fail,
+// with one item per item of the request, in the same order.
+type BatchResult[T any] struct {
+	Items []BatchItem[T]
+}
+
+// Add appends the outcome of an item.
+func (r *BatchResult[T]) Add(key string, value T, err error) {
+	r.Items = append(r.Items, BatchItem[T]{Key: key, Value: value, Err: err})
+}
+
+// Succeeded returns the items that succeeded.
+func (r BatchResult[T]) Succeeded() []BatchItem[T] {
+	return r.filter(false)
+}
+
+// Failed returns the items that failed.
+func (r BatchResult[T]) Failed() []BatchItem[T] {
+	return r.filter(true)
+}
+
+func (r BatchResult[T]) filter(failed bool) []BatchItem[T] {
+	var items []BatchItem[T]
+	for _, item := range r.Items {
+		if (item.Err != nil) == failed {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Err joins the errors of the failed items with errors.Join, each prefixed
+// with its key, so errors.Is and errors.As match any of them. It returns
+// nil when every item succeeded.
+func (r BatchResult[T]) Err() error {
+	var errs []error
+	for _, item := range r.Items {
+		if item.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", item.Key, item.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
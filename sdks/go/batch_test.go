@@ -0,0 +1,45 @@
+package openibank
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// revokeExecutor answers consent revocations with 204, or 404 for
+// consents not in exists.
+type revokeExecutor struct {
+	exists map[string]bool
+}
+
+func (e *revokeExecutor) Execute(ctx context.Context, call *Call) (*Response, error) {
+	if e.exists[call.Path[len("/consents/"):]] {
+		return &Response{StatusCode: http.StatusNoContent}, nil
+	}
+	return &Response{StatusCode: http.StatusNotFound, Body: []byte(`{"message":"consent not found"}`)}, nil
+}
+
+func TestRevokeManyReturnsBatchResult(t *testing.T) {
+	executor := &revokeExecutor{exists: map[string]bool{"cns_1": true, "cns_3": true}}
+	client := NewClient(WithExecutor(executor), WithAPIKey("test_key"), WithMaxRetries(0))
+
+	result, err := client.Consents.RevokeMany(context.Background(), []string{"cns_1", "cns_2", "cns_3"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+	if len(result.Items) != 3 {
+		t.Fatalf("got %d items, want 3", len(result.Items))
+	}
+	for i, want := range []string{"cns_1", "cns_2", "cns_3"} {
+		if result.Items[i].Key != want {
+			t.Errorf("item %d has key %s, want %s", i, result.Items[i].Key, want)
+		}
+	}
+	if failed := result.Failed(); len(failed) != 1 || failed[0].Key != "cns_2" {
+		t.Errorf("Failed() = %v, want cns_2", failed)
+	}
+	if succeeded := result.Succeeded(); len(succeeded) != 2 {
+		t.Errorf("Succeeded() has %d items, want 2", len(succeeded))
+	}
+}
@@ -0,0 +1,128 @@
+// Package bic parses and validates Business Identifier Codes (ISO 9362),
+// also known as SWIFT codes.
+//
+// Example usage:
+//
+//	code, err := bic.Parse("cobadeffxxx")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(code.BankCode(), code.Country(), code.Location(), code.Branch()) // COBA DE FF XXX
+package bic
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrInvalidLength is returned for a BIC that is not 8 or 11 characters
+	// long.
+	ErrInvalidLength = errors.New("bic: must be 8 or 11 characters")
+	// ErrInvalidFormat is returned for a BIC whose parts contain invalid
+	// characters.
+	ErrInvalidFormat = errors.New("bic: must be a 4-character bank code, 2-letter country code, 2-character location and optional 3-character branch")
+)
+
+// primaryOffice is the branch code of an institution's primary office.
+const primaryOffice = "XXX"
+
+// BIC is a validated BIC in upper case, of 8 or 11 characters.
+type BIC string
+
+// Normalize returns s in upper case with spaces removed. It does not
+// validate s.
+func Normalize(s string) string {
+	return strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+}
+
+// Parse normalizes and validates s.
+func Parse(s string) (BIC, error) {
+	normalized := Normalize(s)
+	if err := validate(normalized); err != nil {
+		return "", err
+	}
+	return BIC(normalized), nil
+}
+
+// Validate reports whether s, once normalized, is a valid BIC.
+func Validate(s string) error {
+	return validate(Normalize(s))
+}
+
+// BankCode returns the 4-character code of the institution. Like the other
+// accessors, it returns "" for a BIC too short to hold its part, such as one
+// converted from a string without Parse.
+func (b BIC) BankCode() string {
+	return b.part(0, 4)
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the institution's country.
+func (b BIC) Country() string {
+	return b.part(4, 6)
+}
+
+// Location returns the 2-character location code.
+func (b BIC) Location() string {
+	return b.part(6, 8)
+}
+
+// Branch returns the 3-character branch code, "XXX" for the primary office.
+func (b BIC) Branch() string {
+	if len(b) == 8 {
+		return primaryOffice
+	}
+	return b.part(8, len(b))
+}
+
+// part returns b[start:end], or "" if b is shorter than end.
+func (b BIC) part(start, end int) string {
+	if len(b) < end || start > end {
+		return ""
+	}
+	return string(b[start:end])
+}
+
+// IsPrimaryOffice reports whether the BIC identifies the primary office of
+// the institution rather than a branch.
+func (b BIC) IsPrimaryOffice() bool {
+	return b.Branch() == primaryOffice
+}
+
+// IsTest reports whether the BIC is a test BIC, which has a location code
+// ending in 0 and is not used for live payments.
+func (b BIC) IsTest() bool {
+	return len(b) >= 8 && b[7] == '0'
+}
+
+// Format returns the BIC in its 11-character form, with the primary office
+// branch code added to 8-character BICs.
+func (b BIC) Format() string {
+	if len(b) == 8 {
+		return string(b) + primaryOffice
+	}
+	return string(b)
+}
+
+// String returns the BIC as given, in 8 or 11 characters.
+func (b BIC) String() string {
+	return string(b)
+}
+
+func validate(bic string) error {
+	if len(bic) != 8 && len(bic) != 11 {
+		return ErrInvalidLength
+	}
+	for i := 0; i < len(bic); i++ {
+		c := bic[i]
+		letter := c >= 'A' && c <= 'Z'
+		digit := c >= '0' && c <= '9'
+		switch {
+		case i >= 4 && i < 6 && !letter:
+			return ErrInvalidFormat
+		case !letter && !digit:
+			return ErrInvalidFormat
+		}
+	}
+	return nil
+}
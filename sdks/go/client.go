@@ -20,7 +20,10 @@ package openibank
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -30,6 +33,10 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/openibank/sdk-go/bic"
+	"github.com/openibank/sdk-go/iban"
+	"github.com/openibank/sdk-go/money"
 )
 
 // Version is the SDK version.
@@ -61,27 +68,52 @@ type Client struct {
 	Auth *AuthService
 	// Realtime provides access to WebSocket functionality.
 	Realtime *RealtimeService
+	// Webhooks provides access to the Webhooks API.
+	Webhooks *WebhooksService
+	// Sandbox provides access to the sandbox's test controls.
+	Sandbox *SandboxService
 
 	config      *Config
 	httpClient  *http.Client
+	executor    Executor
 	accessToken string
 	tokenExpiry time.Time
 	tokenMu     sync.RWMutex
+	quota       quotaTracker
+	rateLimit   rateLimitTracker
 }
 
 // Config holds the client configuration.
 type Config struct {
-	ClientID     string
-	ClientSecret string
-	APIKey       string
-	Environment  Environment
-	APIVersion   string
-	Timeout      time.Duration
-	MaxRetries   int
-	RetryDelay   time.Duration
-	AutoRefresh  bool
-	Debug        bool
-	HTTPClient   *http.Client
+	ClientID             string
+	ClientSecret         string
+	APIKey               string
+	Environment          Environment
+	BaseURL              string
+	APIVersion           string
+	Timeout              time.Duration
+	MaxRetries           int
+	RetryDelay           time.Duration
+	AutoRefresh          bool
+	Debug                bool
+	HTTPClient           *http.Client
+	AutoEndToEndID       bool
+	EnforceConsentQuota  bool
+	InstitutionCacheTTL  time.Duration
+	RealtimeMaxRetries   int
+	RealtimeRetryDelay   time.Duration
+	RealtimePingPeriod   time.Duration
+	RealtimePongWait     time.Duration
+	RealtimePollInterval time.Duration
+	RealtimeTLSConfig    *tls.Config
+	RealtimeProxy        func(*http.Request) (*url.URL, error)
+	RealtimeDialTimeout  time.Duration
+	Metrics              MetricsRecorder
+	StrictDecoding       bool
+	Clock                Clock
+	RecordingPath        string
+	RecordingMode        RecordingMode
+	Executor             Executor
 }
 
 // Option is a function that configures the client.
@@ -109,6 +141,14 @@ func WithEnvironment(env Environment) Option {
 	}
 }
 
+// WithBaseURL sets the base URL of the REST API, overriding the URL of the
+// environment, such as to point the client at an openibanktest.Server.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Config) {
+		c.BaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
 // WithAPIVersion sets the API version.
 func WithAPIVersion(version string) Option {
 	return func(c *Config) {
@@ -158,21 +198,37 @@ func WithHTTPClient(client *http.Client) Option {
 	}
 }
 
+// WithAutoEndToEndID enables or disables automatic end-to-end ID generation
+// and echo validation for created payments.
+func WithAutoEndToEndID(enabled bool) Option {
+	return func(c *Config) {
+		c.AutoEndToEndID = enabled
+	}
+}
+
 // NewClient creates a new OpeniBank client with the given options.
 func NewClient(opts ...Option) *Client {
 	config := &Config{
-		Environment: Sandbox,
-		APIVersion:  "v2",
-		Timeout:     30 * time.Second,
-		MaxRetries:  3,
-		RetryDelay:  time.Second,
-		AutoRefresh: true,
-		Debug:       false,
+		Environment:         Sandbox,
+		APIVersion:          "v2",
+		Timeout:             30 * time.Second,
+		MaxRetries:          3,
+		RetryDelay:          time.Second,
+		AutoRefresh:         true,
+		Debug:               false,
+		InstitutionCacheTTL: 24 * time.Hour,
+		RealtimeMaxRetries:  10,
+		RealtimeRetryDelay:  defaultRealtimeRetryDelay,
+		RealtimePingPeriod:  10 * time.Second,
+		RealtimePongWait:    5 * time.Second,
 	}
 
 	for _, opt := range opts {
 		opt(config)
 	}
+	if config.Clock == nil {
+		config.Clock = systemClock{}
+	}
 
 	httpClient := config.HTTPClient
 	if httpClient == nil {
@@ -180,10 +236,19 @@ func NewClient(opts ...Option) *Client {
 			Timeout: config.Timeout,
 		}
 	}
+	if config.RecordingMode == RecordingRecord || config.RecordingMode == RecordingReplay {
+		recording := *httpClient
+		recording.Transport = recordingTransport(httpClient.Transport, config.RecordingPath, config.RecordingMode)
+		httpClient = &recording
+	}
 
 	client := &Client{
 		config:     config,
 		httpClient: httpClient,
+		executor:   config.Executor,
+	}
+	if client.executor == nil {
+		client.executor = &httpExecutor{client: client}
 	}
 
 	// Initialize services
@@ -194,6 +259,8 @@ func NewClient(opts ...Option) *Client {
 	client.Institutions = &InstitutionsService{client: client}
 	client.Auth = &AuthService{client: client}
 	client.Realtime = &RealtimeService{client: client}
+	client.Webhooks = &WebhooksService{client: client}
+	client.Sandbox = &SandboxService{client: client}
 
 	return client
 }
@@ -208,6 +275,10 @@ func NewClientFromEnv() *Client {
 		WithAPIKey(os.Getenv("OPENIBANK_API_KEY")),
 		WithEnvironment(Environment(os.Getenv("OPENIBANK_ENVIRONMENT"))),
 		WithAPIVersion(getEnvOrDefault("OPENIBANK_API_VERSION", "v2")),
+		WithRecording(
+			getEnvOrDefault("OPENIBANK_RECORDING_PATH", defaultRecordingPath),
+			RecordingMode(os.Getenv("OPENIBANK_RECORDING_MODE")),
+		),
 	)
 }
 
@@ -223,11 +294,15 @@ func (c *Client) SetAccessToken(token string) {
 	c.tokenMu.Lock()
 	defer c.tokenMu.Unlock()
 	c.accessToken = token
-	c.tokenExpiry = time.Now().Add(time.Hour) // Assume 1 hour validity
+	c.tokenExpiry = c.now().Add(time.Hour) // Assume 1 hour validity
 }
 
-// BaseURL returns the base URL for the current environment.
+// BaseURL returns the base URL for the current environment, or the URL set
+// WithBaseURL.
 func (c *Client) BaseURL() string {
+	if c.config.BaseURL != "" {
+		return c.config.BaseURL
+	}
 	if c.config.Environment == Production {
 		return "https://api.openibank.com"
 	}
@@ -245,7 +320,7 @@ func (c *Client) WebSocketURL() string {
 // ensureToken ensures we have a valid access token.
 func (c *Client) ensureToken(ctx context.Context) (string, error) {
 	c.tokenMu.RLock()
-	if c.accessToken != "" && time.Now().Before(c.tokenExpiry) {
+	if c.accessToken != "" && c.now().Before(c.tokenExpiry) {
 		token := c.accessToken
 		c.tokenMu.RUnlock()
 		return token, nil
@@ -266,7 +341,7 @@ func (c *Client) ensureToken(ctx context.Context) (string, error) {
 
 		c.tokenMu.Lock()
 		c.accessToken = tokens.AccessToken
-		c.tokenExpiry = time.Now().Add(time.Duration(tokens.ExpiresIn-60) * time.Second)
+		c.tokenExpiry = c.now().Add(time.Duration(tokens.ExpiresIn-60) * time.Second)
 		c.tokenMu.Unlock()
 
 		return tokens.AccessToken, nil
@@ -275,11 +350,36 @@ func (c *Client) ensureToken(ctx context.Context) (string, error) {
 	return "", &AuthenticationError{Message: "No valid credentials configured"}
 }
 
+// tokenExpiresAt returns when the cached access token should be replaced,
+// or the zero time if there is none.
+func (c *Client) tokenExpiresAt() time.Time {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	if c.accessToken == "" {
+		return time.Time{}
+	}
+	return c.tokenExpiry
+}
+
+// clearToken drops the cached access token so that the next request
+// authenticates again.
+func (c *Client) clearToken() {
+	c.tokenMu.Lock()
+	c.accessToken = ""
+	c.tokenExpiry = time.Time{}
+	c.tokenMu.Unlock()
+}
+
 // RequestOption is an option for individual requests.
 type RequestOption func(*requestConfig)
 
 type requestConfig struct {
 	idempotencyKey string
+	contentType    string
+	consentID      string
+	endpointClass  EndpointClass
+	headers        map[string]string
+	responseHeader *http.Header
 }
 
 // WithIdempotencyKey sets an idempotency key for the request.
@@ -289,72 +389,135 @@ func WithIdempotencyKey(key string) RequestOption {
 	}
 }
 
-// request makes an HTTP request to the API.
+// errNotModified is returned by request for a 304 Not Modified response to a
+// conditional request.
+var errNotModified = errors.New("not modified")
+
+// withHeader sets an additional request header.
+func withHeader(key, value string) RequestOption {
+	return func(c *requestConfig) {
+		if c.headers == nil {
+			c.headers = map[string]string{}
+		}
+		c.headers[key] = value
+	}
+}
+
+// withResponseHeader stores the headers of a successful or not-modified
+// response in dst.
+func withResponseHeader(dst *http.Header) RequestOption {
+	return func(c *requestConfig) {
+		c.responseHeader = dst
+	}
+}
+
+// withContentType sets the content type of a raw request body.
+func withContentType(contentType string) RequestOption {
+	return func(c *requestConfig) {
+		c.contentType = contentType
+	}
+}
+
+// rawResponse receives an undecoded response body when passed as the result
+// of request.
+type rawResponse struct {
+	data        []byte
+	contentType string
+}
+
+// request makes a request to the API with the client's executor. The body is
+// encoded as JSON unless it is an io.Reader, which is sent as-is.
 func (c *Client) request(ctx context.Context, method, path string, params url.Values, body interface{}, result interface{}, opts ...RequestOption) error {
-	reqConfig := &requestConfig{}
+	reqConfig := &requestConfig{contentType: "application/json"}
 	for _, opt := range opts {
 		opt(reqConfig)
 	}
 
-	token, err := c.ensureToken(ctx)
-	if err != nil {
-		return err
-	}
-
-	// Build URL
-	reqURL := fmt.Sprintf("%s/%s%s", c.BaseURL(), c.config.APIVersion, path)
-	if params != nil && len(params) > 0 {
-		reqURL += "?" + params.Encode()
+	trackQuota := reqConfig.consentID != "" && reqConfig.endpointClass != ""
+	if trackQuota && c.config.EnforceConsentQuota {
+		if err := c.quota.check(reqConfig.consentID, reqConfig.endpointClass, c.now()); err != nil {
+			return err
+		}
 	}
 
-	var bodyReader io.Reader
-	if body != nil {
-		bodyBytes, err := json.Marshal(body)
+	var bodyBytes []byte
+	var err error
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		bodyBytes, err = io.ReadAll(b)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+	default:
+		bodyBytes, err = json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		bodyReader = bytes.NewReader(bodyBytes)
+	}
+
+	call := &Call{Method: method, Path: path, Query: params, Header: http.Header{}, Body: bodyBytes}
+	call.Header.Set("Content-Type", reqConfig.contentType)
+	call.Header.Set("Accept", "application/json")
+	if reqConfig.idempotencyKey != "" {
+		call.Header.Set("Idempotency-Key", reqConfig.idempotencyKey)
+	}
+	if reqConfig.consentID != "" {
+		call.Header.Set("Consent-ID", reqConfig.consentID)
+	}
+	for key, value := range reqConfig.headers {
+		call.Header.Set(key, value)
 	}
 
 	var lastErr error
 	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
-		req, err := http.NewRequestWithContext(ctx, method, reqURL, bodyReader)
-		if err != nil {
-			return fmt.Errorf("failed to create request: %w", err)
-		}
-
-		// Set headers
-		req.Header.Set("Authorization", "Bearer "+token)
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Accept", "application/json")
-		req.Header.Set("X-API-Version", c.config.APIVersion)
-		req.Header.Set("User-Agent", "OpeniBank-Go/"+Version)
-
-		if reqConfig.idempotencyKey != "" {
-			req.Header.Set("Idempotency-Key", reqConfig.idempotencyKey)
-		}
-
-		resp, err := c.httpClient.Do(req)
+		resp, err := c.executor.Execute(ctx, call)
 		if err != nil {
-			lastErr = &NetworkError{Message: fmt.Sprintf("request failed: %v", err)}
-			if attempt < c.config.MaxRetries {
-				time.Sleep(c.config.RetryDelay * time.Duration(1<<attempt))
+			var r interface{ Retryable() bool }
+			if errors.As(err, &r) && !r.Retryable() {
+				return err
+			}
+			var netErr *NetworkError
+			if !errors.As(err, &netErr) {
+				netErr = &NetworkError{Message: fmt.Sprintf("request failed: %v", err), Err: err}
+			}
+			lastErr = netErr
+			if attempt < c.config.MaxRetries && c.sleep(ctx, c.config.RetryDelay*time.Duration(1<<attempt)) == nil {
 				continue
 			}
 			return lastErr
 		}
-		defer resp.Body.Close()
 
 		requestID := resp.Header.Get("X-Request-ID")
+		rateLimit := c.rateLimit.record(resp.Header, c.now())
+		if reqConfig.responseHeader != nil {
+			*reqConfig.responseHeader = resp.Header
+		}
+
+		if resp.StatusCode == http.StatusNotModified {
+			return errNotModified
+		}
 
 		// Success
 		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			if trackQuota {
+				c.quota.record(reqConfig.consentID, reqConfig.endpointClass, resp.Header, c.now())
+			}
 			if resp.StatusCode == 204 || result == nil {
 				return nil
 			}
-			if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			if raw, ok := result.(*rawResponse); ok {
+				raw.contentType = resp.Header.Get("Content-Type")
+				raw.data = resp.Body
+				return nil
+			}
+			data := resp.Body
+			if err := DecodeJSON(data, result); err != nil {
 				return fmt.Errorf("failed to decode response: %w", err)
 			}
+			if c.config.StrictDecoding {
+				return checkUnknownFields(data, result)
+			}
 			return nil
 		}
 
@@ -367,17 +530,21 @@ func (c *Client) request(ctx context.Context, method, path string, params url.Va
 			ResourceID     string       `json:"resource_id"`
 			RequiredScopes []string     `json:"required_scopes"`
 		}
-		if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		data, body := readErrorBody(bytes.NewReader(resp.Body))
+		if err := json.Unmarshal(data, &errResp); err != nil {
 			errResp.Message = "Unknown error"
 		}
+		contentType := resp.Header.Get("Content-Type")
 
 		switch resp.StatusCode {
 		case 401:
 			return &AuthenticationError{
-				Message:    errResp.Message,
-				Code:       errResp.Code,
-				StatusCode: resp.StatusCode,
-				RequestID:  requestID,
+				Message:     errResp.Message,
+				Code:        errResp.Code,
+				StatusCode:  resp.StatusCode,
+				RequestID:   requestID,
+				Body:        body,
+				ContentType: contentType,
 			}
 		case 403:
 			return &AuthorizationError{
@@ -385,15 +552,19 @@ func (c *Client) request(ctx context.Context, method, path string, params url.Va
 				Code:           errResp.Code,
 				StatusCode:     resp.StatusCode,
 				RequestID:      requestID,
+				Body:           body,
+				ContentType:    contentType,
 				RequiredScopes: errResp.RequiredScopes,
 			}
 		case 400:
 			return &ValidationError{
-				Message:    errResp.Message,
-				Code:       errResp.Code,
-				StatusCode: resp.StatusCode,
-				RequestID:  requestID,
-				Errors:     errResp.Errors,
+				Message:     errResp.Message,
+				Code:        errResp.Code,
+				StatusCode:  resp.StatusCode,
+				RequestID:   requestID,
+				Body:        body,
+				ContentType: contentType,
+				Errors:      errResp.Errors,
 			}
 		case 404:
 			return &NotFoundError{
@@ -401,15 +572,19 @@ func (c *Client) request(ctx context.Context, method, path string, params url.Va
 				Code:         errResp.Code,
 				StatusCode:   resp.StatusCode,
 				RequestID:    requestID,
+				Body:         body,
+				ContentType:  contentType,
 				ResourceType: errResp.ResourceType,
 				ResourceID:   errResp.ResourceID,
 			}
 		case 409:
 			return &ConflictError{
-				Message:    errResp.Message,
-				Code:       errResp.Code,
-				StatusCode: resp.StatusCode,
-				RequestID:  requestID,
+				Message:     errResp.Message,
+				Code:        errResp.Code,
+				StatusCode:  resp.StatusCode,
+				RequestID:   requestID,
+				Body:        body,
+				ContentType: contentType,
 			}
 		case 429:
 			retryAfter := 60 * time.Second
@@ -419,36 +594,41 @@ func (c *Client) request(ctx context.Context, method, path string, params url.Va
 				}
 			}
 			lastErr = &RateLimitError{
-				Message:    errResp.Message,
-				Code:       errResp.Code,
-				StatusCode: resp.StatusCode,
-				RequestID:  requestID,
-				RetryAfter: retryAfter,
+				Message:     errResp.Message,
+				Code:        errResp.Code,
+				StatusCode:  resp.StatusCode,
+				RequestID:   requestID,
+				Body:        body,
+				ContentType: contentType,
+				RetryAfter:  retryAfter,
+				RateLimit:   rateLimit,
 			}
-			if attempt < c.config.MaxRetries {
-				time.Sleep(retryAfter)
+			if attempt < c.config.MaxRetries && c.sleep(ctx, retryAfter) == nil {
 				continue
 			}
 			return lastErr
 		default:
-			if resp.StatusCode >= 500 {
+			if retryableStatus(resp.StatusCode) {
 				lastErr = &ServerError{
-					Message:    errResp.Message,
-					Code:       errResp.Code,
-					StatusCode: resp.StatusCode,
-					RequestID:  requestID,
+					Message:     errResp.Message,
+					Code:        errResp.Code,
+					StatusCode:  resp.StatusCode,
+					RequestID:   requestID,
+					Body:        body,
+					ContentType: contentType,
 				}
-				if attempt < c.config.MaxRetries {
-					time.Sleep(c.config.RetryDelay * time.Duration(1<<attempt))
+				if attempt < c.config.MaxRetries && c.sleep(ctx, c.config.RetryDelay*time.Duration(1<<attempt)) == nil {
 					continue
 				}
 				return lastErr
 			}
 			return &Error{
-				Message:    errResp.Message,
-				Code:       errResp.Code,
-				StatusCode: resp.StatusCode,
-				RequestID:  requestID,
+				Message:     errResp.Message,
+				Code:        errResp.Code,
+				StatusCode:  resp.StatusCode,
+				RequestID:   requestID,
+				Body:        body,
+				ContentType: contentType,
 			}
 		}
 	}
@@ -490,6 +670,19 @@ func Time(t time.Time) *time.Time {
 	return &t
 }
 
+// NewEndToEndID returns a random UUID (version 4) as 32 lowercase hex
+// characters without hyphens, to fit the 35 characters SEPA allows for a
+// payment end-to-end ID.
+func NewEndToEndID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("openibank: failed to generate end-to-end ID: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x", b)
+}
+
 // =============================================================================
 // Models
 // =============================================================================
@@ -502,30 +695,120 @@ type Amount struct {
 
 // Balance represents an account balance.
 type Balance struct {
-	Amount      string     `json:"amount"`
-	Currency    string     `json:"currency"`
-	Type        string     `json:"type,omitempty"`
-	CreditLimit *string    `json:"credit_limit,omitempty"`
-	LastUpdated *time.Time `json:"last_updated,omitempty"`
+	Amount      string      `json:"amount"`
+	Currency    string      `json:"currency"`
+	Type        BalanceType `json:"type,omitempty"`
+	CreditLimit *string     `json:"credit_limit,omitempty"`
+	LastUpdated *time.Time  `json:"last_updated,omitempty"`
+
+	rawJSON
+}
+
+// AccountStatus represents the status of an account. Statuses added to the
+// API after this version of the SDK are kept as is; check them with Valid.
+type AccountStatus string
+
+const (
+	// AccountStatusActive means the account can be used.
+	AccountStatusActive AccountStatus = "active"
+	// AccountStatusBlocked means the bank has blocked the account.
+	AccountStatusBlocked AccountStatus = "blocked"
+	// AccountStatusClosed means the account has been closed.
+	AccountStatusClosed AccountStatus = "closed"
+)
+
+// Valid reports whether s is one of the statuses defined by this package.
+func (s AccountStatus) Valid() bool {
+	switch s {
+	case AccountStatusActive, AccountStatusBlocked, AccountStatusClosed:
+		return true
+	}
+	return false
+}
+
+// IsKnown reports whether s is one of the statuses defined by this package.
+// It is the same as Valid.
+func (s AccountStatus) IsKnown() bool {
+	return s.Valid()
 }
 
 // Account represents a bank account.
 type Account struct {
-	ID            string     `json:"id"`
-	Name          string     `json:"name"`
-	IBAN          *string    `json:"iban,omitempty"`
-	BBAN          *string    `json:"bban,omitempty"`
-	Currency      string     `json:"currency"`
-	AccountType   string     `json:"account_type"`
-	Status        string     `json:"status"`
-	Balance       *Balance   `json:"balance,omitempty"`
-	InstitutionID *string    `json:"institution_id,omitempty"`
-	OwnerName     *string    `json:"owner_name,omitempty"`
-	CreatedAt     *time.Time `json:"created_at,omitempty"`
-	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+	ID            string        `json:"id"`
+	Name          string        `json:"name"`
+	IBAN          *string       `json:"iban,omitempty" redact:"iban"`
+	BBAN          *string       `json:"bban,omitempty" redact:"account"`
+	Currency      string        `json:"currency"`
+	AccountType   string        `json:"account_type"`
+	Status        AccountStatus `json:"status"`
+	Balance       *Balance      `json:"balance,omitempty"`
+	InstitutionID *string       `json:"institution_id,omitempty"`
+	OwnerName     *string       `json:"owner_name,omitempty" redact:"name"`
+	CreatedAt     *time.Time    `json:"created_at,omitempty"`
+	UpdatedAt     *time.Time    `json:"updated_at,omitempty"`
+
+	rawJSON
+}
+
+// TransactionStatus represents the booking status of a transaction.
+// Statuses added to the API after this version of the SDK are kept as is;
+// check them with Valid.
+type TransactionStatus string
+
+const (
+	// TransactionStatusPending means the transaction is not yet booked and
+	// may still change or be cancelled.
+	TransactionStatusPending TransactionStatus = "pending"
+	// TransactionStatusBooked means the transaction is booked to the account.
+	TransactionStatusBooked TransactionStatus = "booked"
+	// TransactionStatusCancelled means a pending transaction was cancelled
+	// before it was booked.
+	TransactionStatusCancelled TransactionStatus = "cancelled"
+)
+
+// Valid reports whether s is one of the statuses defined by this package.
+func (s TransactionStatus) Valid() bool {
+	switch s {
+	case TransactionStatusPending, TransactionStatusBooked, TransactionStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// IsKnown reports whether s is one of the statuses defined by this package.
+// It is the same as Valid.
+func (s TransactionStatus) IsKnown() bool {
+	return s.Valid()
+}
+
+// TransactionType represents the direction of a transaction. Types added to
+// the API after this version of the SDK are kept as is; check them with
+// Valid.
+type TransactionType string
+
+const (
+	// TransactionTypeCredit means money was paid into the account.
+	TransactionTypeCredit TransactionType = "credit"
+	// TransactionTypeDebit means money was paid out of the account.
+	TransactionTypeDebit TransactionType = "debit"
+)
+
+// Valid reports whether t is one of the types defined by this package.
+func (t TransactionType) Valid() bool {
+	return t == TransactionTypeCredit || t == TransactionTypeDebit
+}
+
+// IsKnown reports whether t is one of the types defined by this package.
+// It is the same as Valid.
+func (t TransactionType) IsKnown() bool {
+	return t.Valid()
 }
 
 // Transaction represents a bank transaction.
+//
+// The ISO 20022 details, from BankTransactionCode on, are set when the bank
+// reports them: MandateID for SEPA direct debits, and CreditorAgent and
+// DebtorAgent for the banks of the two parties.
 type Transaction struct {
 	ID               string                 `json:"id"`
 	AccountID        string                 `json:"account_id"`
@@ -533,61 +816,732 @@ type Transaction struct {
 	Currency         string                 `json:"currency"`
 	Description      string                 `json:"description"`
 	Reference        *string                `json:"reference,omitempty"`
-	BookingDate      *time.Time             `json:"booking_date,omitempty"`
-	ValueDate        *time.Time             `json:"value_date,omitempty"`
-	TransactionType  string                 `json:"transaction_type"`
-	Status           string                 `json:"status"`
-	CounterpartyName *string                `json:"counterparty_name,omitempty"`
-	CounterpartyIBAN *string                `json:"counterparty_iban,omitempty"`
+	EndToEndID       *string                `json:"end_to_end_id,omitempty"`
+	BookingDate      *Date                  `json:"booking_date,omitempty"`
+	ValueDate        *Date                  `json:"value_date,omitempty"`
+	TransactionType  TransactionType        `json:"transaction_type"`
+	Status           TransactionStatus      `json:"status"`
+	CounterpartyName *string                `json:"counterparty_name,omitempty" redact:"name"`
+	CounterpartyIBAN *string                `json:"counterparty_iban,omitempty" redact:"iban"`
 	Category         *string                `json:"category,omitempty"`
 	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+
+	BankTransactionCode            *BankTransactionCode            `json:"bank_transaction_code,omitempty"`
+	ProprietaryBankTransactionCode *ProprietaryBankTransactionCode `json:"proprietary_bank_transaction_code,omitempty"`
+	RemittanceInformation          *RemittanceInformation          `json:"remittance_information,omitempty"`
+	MandateID                      *string                         `json:"mandate_id,omitempty"`
+	CreditorAgent                  *FinancialInstitutionID         `json:"creditor_agent,omitempty"`
+	DebtorAgent                    *FinancialInstitutionID         `json:"debtor_agent,omitempty"`
+
+	rawJSON
 }
 
-// CreditorAccount represents a creditor's account for payments.
+// CreditorAccount represents a creditor's account for payments. BIC
+// identifies the creditor's bank; it is optional for SEPA payments.
 type CreditorAccount struct {
-	IBAN          *string `json:"iban,omitempty"`
-	BBAN          *string `json:"bban,omitempty"`
+	IBAN          *string `json:"iban,omitempty" redact:"iban"`
+	BBAN          *string `json:"bban,omitempty" redact:"account"`
 	SortCode      *string `json:"sort_code,omitempty"`
-	AccountNumber *string `json:"account_number,omitempty"`
+	AccountNumber *string `json:"account_number,omitempty" redact:"account"`
+	BIC           *string `json:"bic,omitempty"`
 }
 
 // Creditor represents a payment creditor.
 type Creditor struct {
-	Name    string          `json:"name"`
+	Name    string          `json:"name" redact:"name"`
 	Account CreditorAccount `json:"account"`
+	Address *Address        `json:"address,omitempty"`
+}
+
+// Scheme represents the payment scheme (rail) used to execute a payment.
+// Schemes added to the API after this version of the SDK are kept as is;
+// check them with Valid.
+type Scheme string
+
+const (
+	// SchemeSEPA is a regular SEPA Credit Transfer.
+	SchemeSEPA Scheme = "sepa"
+	// SchemeSEPAInstant is a SEPA Instant Credit Transfer.
+	SchemeSEPAInstant Scheme = "sepa_instant"
+	// SchemeFPS is a UK Faster Payments transfer.
+	SchemeFPS Scheme = "fps"
+	// SchemeBACS is a UK Bacs Direct Credit.
+	SchemeBACS Scheme = "bacs"
+	// SchemeCHAPS is a UK CHAPS payment.
+	SchemeCHAPS Scheme = "chaps"
+	// SchemeSWIFT is an international SWIFT transfer.
+	SchemeSWIFT Scheme = "swift"
+)
+
+// Valid reports whether s is one of the schemes defined by this package.
+func (s Scheme) Valid() bool {
+	switch s {
+	case SchemeSEPA, SchemeSEPAInstant, SchemeFPS, SchemeBACS, SchemeCHAPS, SchemeSWIFT:
+		return true
+	}
+	return false
+}
+
+// IsKnown reports whether s is one of the schemes defined by this package.
+// It is the same as Valid.
+func (s Scheme) IsKnown() bool {
+	return s.Valid()
+}
+
+// IsInstant reports whether the scheme settles in real time.
+func (s Scheme) IsInstant() bool {
+	return s == SchemeSEPAInstant || s == SchemeFPS
+}
+
+// PaymentStatus represents an ISO 20022 payment status code. Statuses added
+// to the API after this version of the SDK are kept as is; check them with
+// Valid.
+type PaymentStatus string
+
+const (
+	// PaymentStatusReceived means the payment was received but not yet checked.
+	PaymentStatusReceived PaymentStatus = "RCVD"
+	// PaymentStatusPending means the payment is awaiting further checks or
+	// authorisation.
+	PaymentStatusPending PaymentStatus = "PDNG"
+	// PaymentStatusAcceptedTechnicalValidation means the payment passed
+	// syntactical and semantic validation.
+	PaymentStatusAcceptedTechnicalValidation PaymentStatus = "ACTC"
+	// PaymentStatusAcceptedCustomerProfile means the payment passed customer
+	// profile checks.
+	PaymentStatusAcceptedCustomerProfile PaymentStatus = "ACCP"
+	// PaymentStatusAcceptedFundsChecked means funds availability was confirmed.
+	PaymentStatusAcceptedFundsChecked PaymentStatus = "ACFC"
+	// PaymentStatusAcceptedWithChange means the payment was accepted with
+	// changes, for example to the execution date.
+	PaymentStatusAcceptedWithChange PaymentStatus = "ACWC"
+	// PaymentStatusAcceptedWithoutPosting means the payment was accepted but
+	// not yet posted to the debtor account.
+	PaymentStatusAcceptedWithoutPosting PaymentStatus = "ACWP"
+	// PaymentStatusAcceptedSettlementInProcess means settlement has started.
+	PaymentStatusAcceptedSettlementInProcess PaymentStatus = "ACSP"
+	// PaymentStatusAcceptedSettlementCompleted means the debtor account was
+	// debited.
+	PaymentStatusAcceptedSettlementCompleted PaymentStatus = "ACSC"
+	// PaymentStatusAcceptedCreditSettlementCompleted means the creditor
+	// account was credited.
+	PaymentStatusAcceptedCreditSettlementCompleted PaymentStatus = "ACCC"
+	// PaymentStatusPartiallyAcceptedTechnicalCorrect means some but not all
+	// required authorisations were given.
+	PaymentStatusPartiallyAcceptedTechnicalCorrect PaymentStatus = "PATC"
+	// PaymentStatusPartiallyAccepted means some payments of a bulk were
+	// accepted.
+	PaymentStatusPartiallyAccepted PaymentStatus = "PART"
+	// PaymentStatusRejected means the payment was rejected.
+	PaymentStatusRejected PaymentStatus = "RJCT"
+	// PaymentStatusCancelled means the payment was cancelled.
+	PaymentStatusCancelled PaymentStatus = "CANC"
+)
+
+// Valid reports whether s is one of the statuses defined by this package.
+func (s PaymentStatus) Valid() bool {
+	switch s {
+	case PaymentStatusReceived, PaymentStatusPending,
+		PaymentStatusAcceptedTechnicalValidation, PaymentStatusAcceptedCustomerProfile,
+		PaymentStatusAcceptedFundsChecked, PaymentStatusAcceptedWithChange,
+		PaymentStatusAcceptedWithoutPosting, PaymentStatusAcceptedSettlementInProcess,
+		PaymentStatusAcceptedSettlementCompleted,
+		PaymentStatusAcceptedCreditSettlementCompleted,
+		PaymentStatusPartiallyAcceptedTechnicalCorrect, PaymentStatusPartiallyAccepted,
+		PaymentStatusRejected, PaymentStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// IsKnown reports whether s is one of the statuses defined by this package.
+// It is the same as Valid.
+func (s PaymentStatus) IsKnown() bool {
+	return s.Valid()
+}
+
+// IsTerminal reports whether the status is final and will not change.
+func (s PaymentStatus) IsTerminal() bool {
+	switch s {
+	case PaymentStatusAcceptedSettlementCompleted, PaymentStatusAcceptedCreditSettlementCompleted,
+		PaymentStatusRejected, PaymentStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// IsSuccessful reports whether the payment settled.
+func (s PaymentStatus) IsSuccessful() bool {
+	return s == PaymentStatusAcceptedSettlementCompleted || s == PaymentStatusAcceptedCreditSettlementCompleted
+}
+
+// ReasonCode represents an ISO 20022 status reason code explaining a
+// rejection or cancellation.
+type ReasonCode string
+
+const (
+	// ReasonIncorrectAccountNumber means the creditor account is invalid.
+	ReasonIncorrectAccountNumber ReasonCode = "AC01"
+	// ReasonClosedAccount means the account is closed.
+	ReasonClosedAccount ReasonCode = "AC04"
+	// ReasonBlockedAccount means the account is blocked.
+	ReasonBlockedAccount ReasonCode = "AC06"
+	// ReasonTransactionForbidden means the transaction type is not allowed
+	// on the account.
+	ReasonTransactionForbidden ReasonCode = "AG01"
+	// ReasonInsufficientFunds means the debtor account lacks funds.
+	ReasonInsufficientFunds ReasonCode = "AM04"
+	// ReasonDuplication means the payment is a duplicate.
+	ReasonDuplication ReasonCode = "AM05"
+	// ReasonDuplicatePayment means cancellation was requested for a
+	// duplicate payment.
+	ReasonDuplicatePayment ReasonCode = "DUPL"
+	// ReasonRequestedByCustomer means the customer requested cancellation.
+	ReasonRequestedByCustomer ReasonCode = "CUST"
+	// ReasonFraud means the payment was stopped as fraudulent.
+	ReasonFraud ReasonCode = "FRAD"
+	// ReasonTechnicalProblem means a technical problem prevented execution.
+	ReasonTechnicalProblem ReasonCode = "TECH"
+	// ReasonRegulatory means the payment was stopped for regulatory reasons.
+	ReasonRegulatory ReasonCode = "RR04"
+	// ReasonNotSpecifiedCustomer means the customer gave no reason.
+	ReasonNotSpecifiedCustomer ReasonCode = "MS02"
+	// ReasonNotSpecifiedAgent means the bank gave no reason.
+	ReasonNotSpecifiedAgent ReasonCode = "MS03"
+)
+
+// StatusReason explains why a payment was rejected or cancelled.
+type StatusReason struct {
+	Code       ReasonCode `json:"code"`
+	Message    *string    `json:"message,omitempty"`
+	Originator *string    `json:"originator,omitempty"`
 }
 
 // Payment represents a payment.
+//
+// Scheme is the rail the payment was executed on. RequestedScheme differs from
+// it when the platform fell back to another rail. SchemeStatusCode carries the
+// raw status or reason code reported by the scheme (for example an ISO 20022
+// reason code from SEPA Instant) with SchemeStatusReason as its description.
+// StatusReason is set when the payment was rejected or cancelled.
 type Payment struct {
-	ID           string     `json:"id"`
-	Status       string     `json:"status"`
-	Amount       string     `json:"amount"`
-	Currency     string     `json:"currency"`
-	CreditorName string     `json:"creditor_name"`
-	CreditorIBAN *string    `json:"creditor_iban,omitempty"`
-	Reference    *string    `json:"reference,omitempty"`
-	CreatedAt    *time.Time `json:"created_at,omitempty"`
-	ExecutedAt   *time.Time `json:"executed_at,omitempty"`
+	ID                 string        `json:"id"`
+	Status             PaymentStatus `json:"status"`
+	StatusReason       *StatusReason `json:"status_reason,omitempty"`
+	Amount             string        `json:"amount"`
+	Currency           string        `json:"currency"`
+	CreditorName       string        `json:"creditor_name" redact:"name"`
+	CreditorIBAN       *string       `json:"creditor_iban,omitempty" redact:"iban"`
+	Reference          *string       `json:"reference,omitempty"`
+	EndToEndID         *string       `json:"end_to_end_id,omitempty"`
+	Scheme             Scheme        `json:"scheme,omitempty"`
+	RequestedScheme    *Scheme       `json:"requested_scheme,omitempty"`
+	SchemeStatusCode   *string       `json:"scheme_status_code,omitempty"`
+	SchemeStatusReason *string       `json:"scheme_status_reason,omitempty"`
+	ExecutionDate      *Date         `json:"execution_date,omitempty"`
+	CreatedAt          *time.Time    `json:"created_at,omitempty"`
+	ExecutedAt         *time.Time    `json:"executed_at,omitempty"`
+
+	rawJSON
+}
+
+// FellBack reports whether the payment was executed on a different scheme
+// than the one requested.
+func (p *Payment) FellBack() bool {
+	return p.RequestedScheme != nil && p.Scheme != "" && *p.RequestedScheme != p.Scheme
+}
+
+// Fee represents a fee charged for a payment.
+type Fee struct {
+	Type        string  `json:"type"`
+	Amount      string  `json:"amount"`
+	Currency    string  `json:"currency"`
+	Description *string `json:"description,omitempty"`
+}
+
+// Quote represents a fee and exchange rate quote for a payment.
+type Quote struct {
+	ID             string    `json:"id"`
+	Amount         string    `json:"amount"`
+	Currency       string    `json:"currency"`
+	TargetAmount   string    `json:"target_amount"`
+	TargetCurrency string    `json:"target_currency"`
+	ExchangeRate   *string   `json:"exchange_rate,omitempty"`
+	Scheme         Scheme    `json:"scheme,omitempty"`
+	Fees           []Fee     `json:"fees"`
+	TotalFee       *Amount   `json:"total_fee,omitempty"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the quote can no longer be used to lock a rate.
+func (q *Quote) Expired() bool {
+	return q.ExpiredAt(time.Now())
+}
+
+// ExpiredAt reports whether the quote can no longer be used at now, such as
+// the time of a Clock.
+func (q *Quote) ExpiredAt(now time.Time) bool {
+	return !now.Before(q.ExpiresAt)
+}
+
+// PaymentLimits represents the payment limits of a debtor account. Amounts
+// are in Currency; a nil limit means no limit applies.
+type PaymentLimits struct {
+	AccountID      string     `json:"account_id"`
+	Currency       string     `json:"currency"`
+	PerTransaction *string    `json:"per_transaction,omitempty"`
+	Daily          *string    `json:"daily,omitempty"`
+	DailyRemaining *string    `json:"daily_remaining,omitempty"`
+	ResetsAt       *time.Time `json:"resets_at,omitempty"`
+}
+
+// Check reports whether a payment of the given amount fits within the
+// limits. It returns a *ValidationError describing the exceeded limit, or nil.
+// The amount must be a positive decimal amount as parsed by
+// money.ParseDecimal.
+func (l *PaymentLimits) Check(amount string) error {
+	value, err := money.ParseDecimal(amount)
+	if err != nil || value.Sign() <= 0 {
+		return &ValidationError{
+			Message: "invalid amount",
+			Code:    ErrCodeInvalidAmount,
+			Errors:  []FieldError{{Field: "amount.amount", Message: fmt.Sprintf("%q is not a positive decimal amount", amount)}},
+		}
+	}
+	checks := []struct {
+		limit   *string
+		message string
+	}{
+		{l.PerTransaction, "exceeds the per-transaction limit of %s %s"},
+		{l.DailyRemaining, "exceeds the remaining daily limit of %s %s"},
+	}
+	for _, c := range checks {
+		if c.limit == nil {
+			continue
+		}
+		limit, err := money.ParseDecimal(*c.limit)
+		if err == nil && value.Cmp(limit) > 0 {
+			return &ValidationError{
+				Message: "payment limit exceeded",
+				Code:    ErrCodeLimitExceeded,
+				Errors:  []FieldError{{Field: "amount.amount", Message: fmt.Sprintf(c.message, *c.limit, l.Currency)}},
+			}
+		}
+	}
+	return nil
+}
+
+// BulkPayment represents a bulk payment submitted as a payment file.
+type BulkPayment struct {
+	ID                   string        `json:"id"`
+	Status               PaymentStatus `json:"status"`
+	MessageID            *string       `json:"message_id,omitempty"`
+	NumberOfTransactions int           `json:"number_of_transactions"`
+	ControlSum           *string       `json:"control_sum,omitempty"`
+	PaymentIDs           []string      `json:"payment_ids,omitempty"`
+	CreatedAt            *time.Time    `json:"created_at,omitempty"`
+}
+
+// Refund represents a refund of a payment.
+type Refund struct {
+	ID          string     `json:"id"`
+	PaymentID   string     `json:"payment_id"`
+	Status      string     `json:"status"`
+	Amount      string     `json:"amount"`
+	Currency    string     `json:"currency"`
+	Reason      *string    `json:"reason,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// ConsentType represents the kind of access a consent grants. Types added to
+// the API after this version of the SDK are kept as is; check them with
+// Valid.
+type ConsentType string
+
+const (
+	// ConsentTypeAccountInformation grants read access to account data.
+	ConsentTypeAccountInformation ConsentType = "account_information"
+	// ConsentTypePaymentInitiation grants permission to initiate payments.
+	ConsentTypePaymentInitiation ConsentType = "payment_initiation"
+)
+
+// Valid reports whether t is one of the types defined by this package.
+func (t ConsentType) Valid() bool {
+	return t == ConsentTypeAccountInformation || t == ConsentTypePaymentInitiation
+}
+
+// IsKnown reports whether t is one of the types defined by this package.
+// It is the same as Valid.
+func (t ConsentType) IsKnown() bool {
+	return t.Valid()
+}
+
+// ConsentStatus represents the status of a consent. Statuses added to the
+// API after this version of the SDK are kept as is; check them with Valid.
+type ConsentStatus string
+
+const (
+	// ConsentStatusReceived means the consent was created but not yet
+	// authorized by the PSU.
+	ConsentStatusReceived ConsentStatus = "received"
+	// ConsentStatusPartiallyAuthorised means some but not all required
+	// authorizations were given.
+	ConsentStatusPartiallyAuthorised ConsentStatus = "partiallyAuthorised"
+	// ConsentStatusValid means the consent is authorized and usable.
+	ConsentStatusValid ConsentStatus = "valid"
+	// ConsentStatusRejected means the PSU or bank rejected the consent.
+	ConsentStatusRejected ConsentStatus = "rejected"
+	// ConsentStatusExpired means the consent passed its validity date.
+	ConsentStatusExpired ConsentStatus = "expired"
+	// ConsentStatusRevokedByPSU means the PSU revoked the consent at their
+	// bank.
+	ConsentStatusRevokedByPSU ConsentStatus = "revokedByPsu"
+	// ConsentStatusTerminatedByTPP means the consent was revoked through the
+	// API.
+	ConsentStatusTerminatedByTPP ConsentStatus = "terminatedByTpp"
+)
+
+var consentStatuses = []ConsentStatus{
+	ConsentStatusReceived,
+	ConsentStatusPartiallyAuthorised,
+	ConsentStatusValid,
+	ConsentStatusRejected,
+	ConsentStatusExpired,
+	ConsentStatusRevokedByPSU,
+	ConsentStatusTerminatedByTPP,
+}
+
+// Valid reports whether s is one of the statuses defined by this package.
+func (s ConsentStatus) Valid() bool {
+	for _, known := range consentStatuses {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsKnown reports whether s is one of the statuses defined by this package.
+// It is the same as Valid.
+func (s ConsentStatus) IsKnown() bool {
+	return s.Valid()
+}
+
+// IsUsable reports whether data can be accessed under a consent with this
+// status.
+func (s ConsentStatus) IsUsable() bool {
+	return s == ConsentStatusValid
+}
+
+// IsTerminal reports whether the status is final and will not change.
+func (s ConsentStatus) IsTerminal() bool {
+	switch s {
+	case ConsentStatusRejected, ConsentStatusExpired, ConsentStatusRevokedByPSU, ConsentStatusTerminatedByTPP:
+		return true
+	}
+	return false
+}
+
+// UnmarshalJSON decodes a status, accepting snake_case and differently cased
+// spellings of the known statuses. Unknown statuses are kept as-is rather
+// than failing the decode.
+func (s *ConsentStatus) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("invalid consent status %s: %w", data, err)
+	}
+	folded := strings.ToLower(strings.ReplaceAll(raw, "_", ""))
+	for _, known := range consentStatuses {
+		if folded == strings.ToLower(string(known)) {
+			*s = known
+			return nil
+		}
+	}
+	*s = ConsentStatus(raw)
+	return nil
 }
 
 // Consent represents a consent.
+//
+// ReconfirmBy is the deadline by which the PSU must reconfirm the consent to
+// keep access without strong customer authentication, and LastSCAAt is when
+// the PSU last authenticated strongly for it. ReplacesConsentID and
+// ReplacedByConsentID link a consent renewed with RenewWithSameAccess to its
+// predecessor and successor.
 type Consent struct {
-	ID               string     `json:"id"`
-	Status           string     `json:"status"`
-	Access           []string   `json:"access"`
-	ValidUntil       *time.Time `json:"valid_until,omitempty"`
-	AuthorizationURL *string    `json:"authorization_url,omitempty"`
-	CreatedAt        *time.Time `json:"created_at,omitempty"`
+	ID                  string        `json:"id"`
+	Type                ConsentType   `json:"type,omitempty"`
+	Status              ConsentStatus `json:"status"`
+	PSUID               *string       `json:"psu_id,omitempty"`
+	Access              ConsentAccess `json:"access"`
+	ValidUntil          *time.Time    `json:"valid_until,omitempty"`
+	RecurringIndicator  *bool         `json:"recurring_indicator,omitempty"`
+	FrequencyPerDay     *int          `json:"frequency_per_day,omitempty"`
+	AuthorizationURL    *string       `json:"authorization_url,omitempty"`
+	ReconfirmBy         *time.Time    `json:"reconfirm_by,omitempty"`
+	LastSCAAt           *time.Time    `json:"last_sca_at,omitempty"`
+	ReplacesConsentID   *string       `json:"replaces_consent_id,omitempty"`
+	ReplacedByConsentID *string       `json:"replaced_by_consent_id,omitempty"`
+	CreatedAt           *time.Time    `json:"created_at,omitempty"`
+
+	rawJSON
+}
+
+// IsUsable reports whether data can currently be accessed under the consent.
+func (c *Consent) IsUsable() bool {
+	return c.UsableAt(time.Now())
+}
+
+// UsableAt reports whether data can be accessed under the consent at now,
+// such as the time of a Clock.
+func (c *Consent) UsableAt(now time.Time) bool {
+	return c.Status.IsUsable() && (c.ValidUntil == nil || now.Before(*c.ValidUntil))
+}
+
+// ExpiresWithin reports whether the consent expires within the given
+// duration. Consents without an expiry never do.
+func (c *Consent) ExpiresWithin(d time.Duration) bool {
+	return c.ExpiresBy(time.Now().Add(d))
+}
+
+// ExpiresBy reports whether the consent expires before t. Consents without
+// an expiry never do.
+func (c *Consent) ExpiresBy(t time.Time) bool {
+	return c.ValidUntil != nil && c.ValidUntil.Before(t)
+}
+
+// ReconfirmationDue reports whether the consent must be reconfirmed within
+// the given duration.
+func (c *Consent) ReconfirmationDue(within time.Duration) bool {
+	return c.ReconfirmationDueBy(time.Now().Add(within))
+}
+
+// ReconfirmationDueBy reports whether the consent must be reconfirmed
+// before t.
+func (c *Consent) ReconfirmationDueBy(t time.Time) bool {
+	return c.ReconfirmBy != nil && c.ReconfirmBy.Before(t)
+}
+
+// ConsentHistoryEventType represents the kind of entry in a consent's
+// history. Types added to the API after this version of the SDK are kept as
+// is; check them with Valid.
+type ConsentHistoryEventType string
+
+const (
+	// ConsentHistoryStatusChanged records a status transition.
+	ConsentHistoryStatusChanged ConsentHistoryEventType = "status_changed"
+	// ConsentHistorySCACompleted records a successful strong customer
+	// authentication.
+	ConsentHistorySCACompleted ConsentHistoryEventType = "sca_completed"
+	// ConsentHistorySCAFailed records a failed strong customer
+	// authentication.
+	ConsentHistorySCAFailed ConsentHistoryEventType = "sca_failed"
+	// ConsentHistoryAccessed records data being accessed under the consent.
+	ConsentHistoryAccessed ConsentHistoryEventType = "accessed"
+	// ConsentHistoryReconfirmed records a PSU reconfirmation.
+	ConsentHistoryReconfirmed ConsentHistoryEventType = "reconfirmed"
+	// ConsentHistoryExtended records a change of the validity date.
+	ConsentHistoryExtended ConsentHistoryEventType = "extended"
+)
+
+// Valid reports whether t is one of the types defined by this package.
+func (t ConsentHistoryEventType) Valid() bool {
+	switch t {
+	case ConsentHistoryStatusChanged, ConsentHistorySCACompleted,
+		ConsentHistorySCAFailed, ConsentHistoryAccessed, ConsentHistoryReconfirmed,
+		ConsentHistoryExtended:
+		return true
+	}
+	return false
+}
+
+// IsKnown reports whether t is one of the types defined by this package.
+// It is the same as Valid.
+func (t ConsentHistoryEventType) IsKnown() bool {
+	return t.Valid()
+}
+
+// ConsentHistoryEntry is one event in a consent's lifecycle. Which of the
+// optional fields are set depends on Type: FromStatus and ToStatus for
+// status changes, SCAMethod for SCA events and EndpointClass for access.
+type ConsentHistoryEntry struct {
+	Type          ConsentHistoryEventType `json:"type"`
+	OccurredAt    time.Time               `json:"occurred_at"`
+	Actor         *string                 `json:"actor,omitempty"`
+	FromStatus    *ConsentStatus          `json:"from_status,omitempty"`
+	ToStatus      *ConsentStatus          `json:"to_status,omitempty"`
+	SCAMethod     *string                 `json:"sca_method,omitempty"`
+	EndpointClass *EndpointClass          `json:"endpoint_class,omitempty"`
+	RequestID     *string                 `json:"request_id,omitempty"`
+	Description   *string                 `json:"description,omitempty"`
+}
+
+// Capability represents a feature an institution supports. Capabilities
+// added to the API after this version of the SDK are kept as is; check them
+// with Valid.
+type Capability string
+
+const (
+	// CapabilityAccounts is access to account details.
+	CapabilityAccounts Capability = "accounts"
+	// CapabilityBalances is access to balances.
+	CapabilityBalances Capability = "balances"
+	// CapabilityTransactions is access to transactions.
+	CapabilityTransactions Capability = "transactions"
+	// CapabilityPendingTransactions is access to pending transactions.
+	CapabilityPendingTransactions Capability = "pending_transactions"
+	// CapabilityPayments is single payment initiation.
+	CapabilityPayments Capability = "payments"
+	// CapabilityBulkPayments is bulk payment initiation.
+	CapabilityBulkPayments Capability = "bulk_payments"
+	// CapabilityPeriodicPayments is periodic payments and standing orders.
+	CapabilityPeriodicPayments Capability = "periodic_payments"
+	// CapabilityInstantPayments is instant payment initiation.
+	CapabilityInstantPayments Capability = "instant_payments"
+	// CapabilityRefunds is refunds of received payments.
+	CapabilityRefunds Capability = "refunds"
+	// CapabilityVRP is variable recurring payments.
+	CapabilityVRP Capability = "vrp"
+	// CapabilityFundsConfirmation is confirmation of funds.
+	CapabilityFundsConfirmation Capability = "funds_confirmation"
+)
+
+// Valid reports whether c is one of the capabilities defined by this package.
+func (c Capability) Valid() bool {
+	switch c {
+	case CapabilityAccounts, CapabilityBalances, CapabilityTransactions,
+		CapabilityPendingTransactions, CapabilityPayments, CapabilityBulkPayments,
+		CapabilityPeriodicPayments, CapabilityInstantPayments, CapabilityRefunds,
+		CapabilityVRP, CapabilityFundsConfirmation:
+		return true
+	}
+	return false
+}
+
+// IsKnown reports whether c is one of the capabilities defined by this package.
+// It is the same as Valid.
+func (c Capability) IsKnown() bool {
+	return c.Valid()
+}
+
+// SCAApproach represents how a bank performs strong customer
+// authentication. Approaches added to the API after this version of the SDK
+// are kept as is; check them with Valid.
+type SCAApproach string
+
+const (
+	// SCARedirect sends the PSU to the bank's website or app.
+	SCARedirect SCAApproach = "redirect"
+	// SCADecoupled asks the PSU to approve in the bank's app while the TPP
+	// polls for the result.
+	SCADecoupled SCAApproach = "decoupled"
+	// SCAEmbedded collects credentials and one-time passwords in the TPP's
+	// own interface.
+	SCAEmbedded SCAApproach = "embedded"
+	// SCAOAuth uses an OAuth2 authorization code flow.
+	SCAOAuth SCAApproach = "oauth"
+)
+
+// Valid reports whether a is one of the approaches defined by this package.
+func (a SCAApproach) Valid() bool {
+	switch a {
+	case SCARedirect, SCADecoupled, SCAEmbedded, SCAOAuth:
+		return true
+	}
+	return false
+}
+
+// IsKnown reports whether a is one of the approaches defined by this package.
+// It is the same as Valid.
+func (a SCAApproach) IsKnown() bool {
+	return a.Valid()
+}
+
+// ConnectionInfo describes institution-specific limits and behavior that
+// clients may need to adapt their UX to.
+type ConnectionInfo struct {
+	MaxConsentValidityDays      *int          `json:"max_consent_validity_days,omitempty"`
+	TransactionHistoryDays      *int          `json:"transaction_history_days,omitempty"`
+	SupportsPendingTransactions bool          `json:"supports_pending_transactions"`
+	SCAApproaches               []SCAApproach `json:"sca_approaches,omitempty"`
+}
+
+// MaxConsentValidity returns the longest validity the institution accepts
+// for a consent, or 0 if it has no documented limit.
+func (c *ConnectionInfo) MaxConsentValidity() time.Duration {
+	if c.MaxConsentValidityDays == nil {
+		return 0
+	}
+	return time.Duration(*c.MaxConsentValidityDays) * 24 * time.Hour
+}
+
+// TransactionHistoryStart returns the earliest booking date that can be
+// requested from the institution, or the zero time if it has no limit.
+func (c *ConnectionInfo) TransactionHistoryStart() time.Time {
+	return c.TransactionHistoryStartAt(time.Now())
+}
+
+// TransactionHistoryStartAt returns the earliest booking date that can be
+// requested from the institution at now, such as the time of a Clock.
+func (c *ConnectionInfo) TransactionHistoryStartAt(now time.Time) time.Time {
+	if c.TransactionHistoryDays == nil {
+		return time.Time{}
+	}
+	return dateOf(now).AddDate(0, 0, -*c.TransactionHistoryDays)
 }
 
 // Institution represents a financial institution.
 type Institution struct {
-	ID                string   `json:"id"`
-	Name              string   `json:"name"`
-	BIC               *string  `json:"bic,omitempty"`
-	Country           string   `json:"country"`
-	LogoURL           *string  `json:"logo_url,omitempty"`
-	SupportedFeatures []string `json:"supported_features"`
+	ID                string          `json:"id"`
+	Name              string          `json:"name"`
+	BIC               *string         `json:"bic,omitempty"`
+	Country           Country         `json:"country"`
+	LogoURL           *string         `json:"logo_url,omitempty"`
+	SupportedFeatures []Capability    `json:"supported_features"`
+	SupportedSchemes  []Scheme        `json:"supported_schemes,omitempty"`
+	Connection        *ConnectionInfo `json:"connection,omitempty"`
+
+	rawJSON
+}
+
+// Supports reports whether the institution supports all of the given
+// capabilities.
+func (i *Institution) Supports(capabilities ...Capability) bool {
+	for _, c := range capabilities {
+		found := false
+		for _, f := range i.SupportedFeatures {
+			if f == c {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// SupportsScheme reports whether the institution can execute payments on the
+// given scheme.
+func (i *Institution) SupportsScheme(scheme Scheme) bool {
+	for _, s := range i.SupportedSchemes {
+		if s == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+// SandboxCredential is a test PSU login for the sandbox variant of an
+// institution. Description explains the scenario the PSU exercises, such as
+// an account with insufficient funds.
+type SandboxCredential struct {
+	Username    string  `json:"username"`
+	Password    string  `json:"password"`
+	OTP         *string `json:"otp,omitempty"`
+	Description *string `json:"description,omitempty"`
 }
 
 // TokenResponse represents an OAuth token response.
@@ -603,12 +1557,16 @@ type TokenResponse struct {
 // Errors
 // =============================================================================
 
-// Error is the base error type for all API errors.
+// Error is the base error type for all API errors. In all API errors, Body
+// holds the response body, truncated to 4 KB, and ContentType its content
+// type, for debugging error formats the SDK does not decode.
 type Error struct {
-	Message    string `json:"message"`
-	Code       string `json:"code,omitempty"`
-	StatusCode int    `json:"status_code,omitempty"`
-	RequestID  string `json:"request_id,omitempty"`
+	Message     string `json:"message"`
+	Code        string `json:"code,omitempty"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	RequestID   string `json:"request_id,omitempty"`
+	Body        string `json:"-"`
+	ContentType string `json:"-"`
 }
 
 func (e *Error) Error() string {
@@ -628,12 +1586,18 @@ type FieldError struct {
 	Code    string `json:"code,omitempty"`
 }
 
+func (e FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
 // AuthenticationError indicates authentication failure.
 type AuthenticationError struct {
-	Message    string `json:"message"`
-	Code       string `json:"code,omitempty"`
-	StatusCode int    `json:"status_code,omitempty"`
-	RequestID  string `json:"request_id,omitempty"`
+	Message     string `json:"message"`
+	Code        string `json:"code,omitempty"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	RequestID   string `json:"request_id,omitempty"`
+	Body        string `json:"-"`
+	ContentType string `json:"-"`
 }
 
 func (e *AuthenticationError) Error() string {
@@ -647,6 +1611,8 @@ type AuthorizationError struct {
 	StatusCode     int      `json:"status_code,omitempty"`
 	RequestID      string   `json:"request_id,omitempty"`
 	RequiredScopes []string `json:"required_scopes,omitempty"`
+	Body           string   `json:"-"`
+	ContentType    string   `json:"-"`
 }
 
 func (e *AuthorizationError) Error() string {
@@ -655,17 +1621,42 @@ func (e *AuthorizationError) Error() string {
 
 // ValidationError indicates request validation failure.
 type ValidationError struct {
-	Message    string       `json:"message"`
-	Code       string       `json:"code,omitempty"`
-	StatusCode int          `json:"status_code,omitempty"`
-	RequestID  string       `json:"request_id,omitempty"`
-	Errors     []FieldError `json:"errors,omitempty"`
+	Message     string       `json:"message"`
+	Code        string       `json:"code,omitempty"`
+	StatusCode  int          `json:"status_code,omitempty"`
+	RequestID   string       `json:"request_id,omitempty"`
+	Errors      []FieldError `json:"errors,omitempty"`
+	Body        string       `json:"-"`
+	ContentType string       `json:"-"`
 }
 
 func (e *ValidationError) Error() string {
 	return fmt.Sprintf("validation error: %s", e.Message)
 }
 
+// Unwrap returns the field errors, so errors.As finds a FieldError.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, fe := range e.Errors {
+		errs[i] = fe
+	}
+	return errs
+}
+
+// FieldErrorsFor returns the errors of a field, such as
+// "creditor.account.iban", including those of its nested fields and
+// elements, such as "creditor.account.iban" for "creditor" or
+// "countries[1]" for "countries".
+func (e *ValidationError) FieldErrorsFor(field string) []FieldError {
+	var errs []FieldError
+	for _, fe := range e.Errors {
+		if fe.Field == field || strings.HasPrefix(fe.Field, field+".") || strings.HasPrefix(fe.Field, field+"[") {
+			errs = append(errs, fe)
+		}
+	}
+	return errs
+}
+
 // NotFoundError indicates resource not found.
 type NotFoundError struct {
 	Message      string `json:"message"`
@@ -674,19 +1665,25 @@ type NotFoundError struct {
 	RequestID    string `json:"request_id,omitempty"`
 	ResourceType string `json:"resource_type,omitempty"`
 	ResourceID   string `json:"resource_id,omitempty"`
+	Body         string `json:"-"`
+	ContentType  string `json:"-"`
 }
 
 func (e *NotFoundError) Error() string {
 	return fmt.Sprintf("not found: %s", e.Message)
 }
 
-// RateLimitError indicates rate limit exceeded.
+// RateLimitError indicates rate limit exceeded. RateLimit is the limit
+// reported with the response, if any.
 type RateLimitError struct {
-	Message    string        `json:"message"`
-	Code       string        `json:"code,omitempty"`
-	StatusCode int           `json:"status_code,omitempty"`
-	RequestID  string        `json:"request_id,omitempty"`
-	RetryAfter time.Duration `json:"retry_after,omitempty"`
+	Message     string          `json:"message"`
+	Code        string          `json:"code,omitempty"`
+	StatusCode  int             `json:"status_code,omitempty"`
+	RequestID   string          `json:"request_id,omitempty"`
+	RetryAfter  time.Duration   `json:"retry_after,omitempty"`
+	Body        string          `json:"-"`
+	ContentType string          `json:"-"`
+	RateLimit   RateLimitStatus `json:"-"`
 }
 
 func (e *RateLimitError) Error() string {
@@ -695,10 +1692,12 @@ func (e *RateLimitError) Error() string {
 
 // ConflictError indicates resource conflict.
 type ConflictError struct {
-	Message    string `json:"message"`
-	Code       string `json:"code,omitempty"`
-	StatusCode int    `json:"status_code,omitempty"`
-	RequestID  string `json:"request_id,omitempty"`
+	Message     string `json:"message"`
+	Code        string `json:"code,omitempty"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	RequestID   string `json:"request_id,omitempty"`
+	Body        string `json:"-"`
+	ContentType string `json:"-"`
 }
 
 func (e *ConflictError) Error() string {
@@ -707,25 +1706,66 @@ func (e *ConflictError) Error() string {
 
 // ServerError indicates internal server error.
 type ServerError struct {
-	Message    string `json:"message"`
-	Code       string `json:"code,omitempty"`
-	StatusCode int    `json:"status_code,omitempty"`
-	RequestID  string `json:"request_id,omitempty"`
+	Message     string `json:"message"`
+	Code        string `json:"code,omitempty"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	RequestID   string `json:"request_id,omitempty"`
+	Body        string `json:"-"`
+	ContentType string `json:"-"`
 }
 
 func (e *ServerError) Error() string {
 	return fmt.Sprintf("server error: %s (request_id: %s)", e.Message, e.RequestID)
 }
 
-// NetworkError indicates network or connection error.
+// NetworkError indicates network or connection error. Err is the
+// underlying error, if any, so that errors.Is and errors.As match it, for
+// example against context.DeadlineExceeded or a net.Error.
 type NetworkError struct {
 	Message string `json:"message"`
+	Err     error  `json:"-"`
 }
 
 func (e *NetworkError) Error() string {
 	return fmt.Sprintf("network error: %s", e.Message)
 }
 
+// Unwrap returns the underlying error.
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// EndToEndIDMismatchError indicates that a created payment did not echo the
+// end-to-end ID it was submitted with.
+type EndToEndIDMismatchError struct {
+	PaymentID string  `json:"payment_id"`
+	Expected  string  `json:"expected"`
+	Actual    *string `json:"actual,omitempty"`
+}
+
+func (e *EndToEndIDMismatchError) Error() string {
+	if e.Actual == nil {
+		return fmt.Sprintf("end-to-end ID mismatch: payment %s did not echo %s", e.PaymentID, e.Expected)
+	}
+	return fmt.Sprintf("end-to-end ID mismatch: payment %s returned %s, expected %s", e.PaymentID, *e.Actual, e.Expected)
+}
+
+// ConsentAuthorizationError indicates that the PSU declined a consent or the
+// bank failed its authorization.
+type ConsentAuthorizationError struct {
+	ConsentID   string `json:"consent_id,omitempty"`
+	ErrorCode   string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+	State       string `json:"state,omitempty"`
+}
+
+func (e *ConsentAuthorizationError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("consent authorization failed: %s (%s)", e.Description, e.ErrorCode)
+	}
+	return fmt.Sprintf("consent authorization failed: %s", e.ErrorCode)
+}
+
 // =============================================================================
 // Services
 // =============================================================================
@@ -737,54 +1777,43 @@ type AccountsService struct {
 
 // AccountListParams contains parameters for listing accounts.
 type AccountListParams struct {
-	Status      *string
-	AccountType *string
-	Limit       *int
-	Offset      *int
+	Status      *string `url:"status"`
+	AccountType *string `url:"account_type"`
+	Limit       *int    `url:"limit"`
+	Offset      *int    `url:"offset"`
 }
 
 // List lists all accounts.
-func (s *AccountsService) List(ctx context.Context, params *AccountListParams) ([]Account, error) {
-	values := url.Values{}
-	if params != nil {
-		if params.Status != nil {
-			values.Set("status", *params.Status)
-		}
-		if params.AccountType != nil {
-			values.Set("account_type", *params.AccountType)
-		}
-		if params.Limit != nil {
-			values.Set("limit", strconv.Itoa(*params.Limit))
-		}
-		if params.Offset != nil {
-			values.Set("offset", strconv.Itoa(*params.Offset))
-		}
-	}
+func (s *AccountsService) List(ctx context.Context, params *AccountListParams, opts ...RequestOption) ([]Account, error) {
+	values := encodeQuery(params)
 
 	var result struct {
 		Accounts []Account `json:"accounts"`
 	}
-	if err := s.client.request(ctx, "GET", "/accounts", values, nil, &result); err != nil {
+	opts = append(opts, withEndpointClass(EndpointAccounts))
+	if err := s.client.request(ctx, "GET", "/accounts", values, nil, &result, opts...); err != nil {
 		return nil, err
 	}
 	return result.Accounts, nil
 }
 
 // Get gets a single account.
-func (s *AccountsService) Get(ctx context.Context, accountID string) (*Account, error) {
+func (s *AccountsService) Get(ctx context.Context, accountID string, opts ...RequestOption) (*Account, error) {
 	var account Account
-	if err := s.client.request(ctx, "GET", "/accounts/"+accountID, nil, nil, &account); err != nil {
+	opts = append(opts, withEndpointClass(EndpointAccounts))
+	if err := s.client.request(ctx, "GET", "/accounts/"+accountID, nil, nil, &account, opts...); err != nil {
 		return nil, err
 	}
 	return &account, nil
 }
 
 // GetBalances gets account balances.
-func (s *AccountsService) GetBalances(ctx context.Context, accountID string) ([]Balance, error) {
+func (s *AccountsService) GetBalances(ctx context.Context, accountID string, opts ...RequestOption) (Balances, error) {
 	var result struct {
-		Balances []Balance `json:"balances"`
+		Balances Balances `json:"balances"`
 	}
-	if err := s.client.request(ctx, "GET", "/accounts/"+accountID+"/balances", nil, nil, &result); err != nil {
+	opts = append(opts, withEndpointClass(EndpointBalances))
+	if err := s.client.request(ctx, "GET", "/accounts/"+accountID+"/balances", nil, nil, &result, opts...); err != nil {
 		return nil, err
 	}
 	return result.Balances, nil
@@ -797,55 +1826,35 @@ type TransactionsService struct {
 
 // TransactionListParams contains parameters for listing transactions.
 type TransactionListParams struct {
-	DateFrom      *time.Time
-	DateTo        *time.Time
-	AmountMin     *float64
-	AmountMax     *float64
-	BookingStatus *string
-	Limit         *int
-	Offset        *int
+	UpdatedSince  *time.Time `url:"updated_since"`
+	DateFrom      *time.Time `url:"date_from,date"`
+	DateTo        *time.Time `url:"date_to,date"`
+	AmountMin     *float64   `url:"amount_min,amount"`
+	AmountMax     *float64   `url:"amount_max,amount"`
+	BookingStatus *string    `url:"booking_status"`
+	Limit         *int       `url:"limit"`
+	Offset        *int       `url:"offset"`
 }
 
 // List lists transactions for an account.
-func (s *TransactionsService) List(ctx context.Context, accountID string, params *TransactionListParams) ([]Transaction, error) {
-	values := url.Values{}
-	if params != nil {
-		if params.DateFrom != nil {
-			values.Set("date_from", params.DateFrom.Format("2006-01-02"))
-		}
-		if params.DateTo != nil {
-			values.Set("date_to", params.DateTo.Format("2006-01-02"))
-		}
-		if params.AmountMin != nil {
-			values.Set("amount_min", strconv.FormatFloat(*params.AmountMin, 'f', 2, 64))
-		}
-		if params.AmountMax != nil {
-			values.Set("amount_max", strconv.FormatFloat(*params.AmountMax, 'f', 2, 64))
-		}
-		if params.BookingStatus != nil {
-			values.Set("booking_status", *params.BookingStatus)
-		}
-		if params.Limit != nil {
-			values.Set("limit", strconv.Itoa(*params.Limit))
-		}
-		if params.Offset != nil {
-			values.Set("offset", strconv.Itoa(*params.Offset))
-		}
-	}
+func (s *TransactionsService) List(ctx context.Context, accountID string, params *TransactionListParams, opts ...RequestOption) ([]Transaction, error) {
+	values := encodeQuery(params)
 
 	var result struct {
 		Transactions []Transaction `json:"transactions"`
 	}
-	if err := s.client.request(ctx, "GET", "/accounts/"+accountID+"/transactions", values, nil, &result); err != nil {
+	opts = append(opts, withEndpointClass(EndpointTransactions))
+	if err := s.client.request(ctx, "GET", "/accounts/"+accountID+"/transactions", values, nil, &result, opts...); err != nil {
 		return nil, err
 	}
 	return result.Transactions, nil
 }
 
 // Get gets a single transaction.
-func (s *TransactionsService) Get(ctx context.Context, accountID, transactionID string) (*Transaction, error) {
+func (s *TransactionsService) Get(ctx context.Context, accountID, transactionID string, opts ...RequestOption) (*Transaction, error) {
 	var transaction Transaction
-	if err := s.client.request(ctx, "GET", "/accounts/"+accountID+"/transactions/"+transactionID, nil, nil, &transaction); err != nil {
+	opts = append(opts, withEndpointClass(EndpointTransactions))
+	if err := s.client.request(ctx, "GET", "/accounts/"+accountID+"/transactions/"+transactionID, nil, nil, &transaction, opts...); err != nil {
 		return nil, err
 	}
 	return &transaction, nil
@@ -862,10 +1871,11 @@ type TransactionIterator struct {
 	index     int
 	err       error
 	done      bool
+	opts      []RequestOption
 }
 
 // Iter returns an iterator for transactions.
-func (s *TransactionsService) Iter(ctx context.Context, accountID string, params *TransactionListParams) *TransactionIterator {
+func (s *TransactionsService) Iter(ctx context.Context, accountID string, params *TransactionListParams, opts ...RequestOption) *TransactionIterator {
 	limit := 50
 	if params != nil && params.Limit != nil {
 		limit = *params.Limit
@@ -876,6 +1886,7 @@ func (s *TransactionsService) Iter(ctx context.Context, accountID string, params
 		params:    params,
 		limit:     limit,
 		offset:    0,
+		opts:      opts,
 	}
 }
 
@@ -890,20 +1901,16 @@ func (it *TransactionIterator) Next() bool {
 		return true
 	}
 
-	// Fetch next page
-	params := &TransactionListParams{
-		Limit:  &it.limit,
-		Offset: &it.offset,
-	}
+	// Fetch next page with the caller's filters and the iterator's cursor
+	var params TransactionListParams
 	if it.params != nil {
-		params.DateFrom = it.params.DateFrom
-		params.DateTo = it.params.DateTo
-		params.AmountMin = it.params.AmountMin
-		params.AmountMax = it.params.AmountMax
-		params.BookingStatus = it.params.BookingStatus
+		params = *it.params
 	}
+	limit, offset := it.limit, it.offset
+	params.Limit = &limit
+	params.Offset = &offset
 
-	transactions, err := it.client.Transactions.List(context.Background(), it.accountID, params)
+	transactions, err := it.client.Transactions.List(context.Background(), it.accountID, &params, it.opts...)
 	if err != nil {
 		it.err = err
 		return false
@@ -944,24 +1951,70 @@ type PaymentsService struct {
 }
 
 // PaymentCreateParams contains parameters for creating a payment.
+//
+// Scheme requests a specific payment scheme; when nil the platform selects
+// one. AllowSchemeFallback lets the platform use a non-instant scheme when the
+// requested instant scheme is unavailable. Without it, such payments are
+// rejected with a scheme-specific status code. QuoteID locks the fees and
+// exchange rate of a quote obtained from GetQuote. Schedule turns the payment
+// into a periodic payment.
 type PaymentCreateParams struct {
-	Creditor        Creditor   `json:"creditor"`
-	Amount          Amount     `json:"amount"`
-	DebtorAccountID string     `json:"debtor_account_id"`
-	Reference       *string    `json:"reference,omitempty"`
-	EndToEndID      *string    `json:"end_to_end_id,omitempty"`
-	ExecutionDate   *time.Time `json:"execution_date,omitempty"`
+	Creditor            Creditor  `json:"creditor"`
+	Amount              Amount    `json:"amount"`
+	DebtorAccountID     string    `json:"debtor_account_id"`
+	Reference           *string   `json:"reference,omitempty"`
+	EndToEndID          *string   `json:"end_to_end_id,omitempty"`
+	ExecutionDate       *Date     `json:"execution_date,omitempty"`
+	Scheme              *Scheme   `json:"scheme,omitempty"`
+	AllowSchemeFallback *bool     `json:"allow_scheme_fallback,omitempty"`
+	QuoteID             *string   `json:"quote_id,omitempty"`
+	Schedule            *Schedule `json:"schedule,omitempty"`
 }
 
 // Create creates a new payment.
+//
+// When the client is configured WithAutoEndToEndID, a missing EndToEndID is
+// generated and the returned payment is checked to carry the same ID. A
+// mismatch is reported as an *EndToEndIDMismatchError alongside the created
+// payment.
+//
+// A creditor IBAN that is malformed or whose checksum does not match is
+// rejected with a *ValidationError without a request being sent.
 func (s *PaymentsService) Create(ctx context.Context, params PaymentCreateParams, opts ...RequestOption) (*Payment, error) {
+	if number := params.Creditor.Account.IBAN; number != nil && *number != "" {
+		if err := iban.Validate(*number); err != nil {
+			return nil, &ValidationError{
+				Message: "invalid IBAN",
+				Code:    ErrCodeInvalidIBAN,
+				Errors:  []FieldError{{Field: "creditor.account.iban", Message: ibanMessage(err)}},
+			}
+		}
+	}
+	if params.Schedule != nil {
+		if err := params.Schedule.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if params.Creditor.Address != nil {
+		if err := params.Creditor.Address.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if s.client.config.AutoEndToEndID && params.EndToEndID == nil {
+		params.EndToEndID = String(NewEndToEndID())
+	}
+
 	body := map[string]interface{}{
 		"creditor": map[string]interface{}{
 			"name": params.Creditor.Name,
 			"account": map[string]interface{}{
-				"iban": params.Creditor.Account.IBAN,
-				"bban": params.Creditor.Account.BBAN,
+				"iban":           params.Creditor.Account.IBAN,
+				"bban":           params.Creditor.Account.BBAN,
+				"sort_code":      params.Creditor.Account.SortCode,
+				"account_number": params.Creditor.Account.AccountNumber,
+				"bic":            params.Creditor.Account.BIC,
 			},
+			"address": params.Creditor.Address,
 		},
 		"amount": map[string]interface{}{
 			"amount":   params.Amount.Amount,
@@ -976,16 +2029,106 @@ func (s *PaymentsService) Create(ctx context.Context, params PaymentCreateParams
 		body["end_to_end_id"] = *params.EndToEndID
 	}
 	if params.ExecutionDate != nil {
-		body["execution_date"] = params.ExecutionDate.Format("2006-01-02")
+		body["execution_date"] = params.ExecutionDate.String()
+	}
+	if params.Scheme != nil {
+		body["scheme"] = *params.Scheme
+	}
+	if params.AllowSchemeFallback != nil {
+		body["allow_scheme_fallback"] = *params.AllowSchemeFallback
+	}
+	if params.QuoteID != nil {
+		body["quote_id"] = *params.QuoteID
+	}
+	if params.Schedule != nil {
+		body["schedule"] = params.Schedule
 	}
 
 	var payment Payment
 	if err := s.client.request(ctx, "POST", "/payments", nil, body, &payment, opts...); err != nil {
 		return nil, err
 	}
+	if s.client.config.AutoEndToEndID {
+		if payment.EndToEndID == nil || *payment.EndToEndID != *params.EndToEndID {
+			return &payment, &EndToEndIDMismatchError{
+				PaymentID: payment.ID,
+				Expected:  *params.EndToEndID,
+				Actual:    payment.EndToEndID,
+			}
+		}
+	}
 	return &payment, nil
 }
 
+// ibanMessage describes why an IBAN failed validation.
+func ibanMessage(err error) string {
+	switch {
+	case errors.Is(err, iban.ErrInvalidFormat):
+		return "must start with a 2-letter country code and 2 check digits"
+	case errors.Is(err, iban.ErrInvalidCharacters):
+		return "must contain only letters and digits"
+	case errors.Is(err, iban.ErrUnknownCountry):
+		return "has a country code that does not use IBANs"
+	case errors.Is(err, iban.ErrInvalidLength):
+		return "has the wrong length for its country"
+	default:
+		return "has an invalid checksum"
+	}
+}
+
+// QuoteParams contains parameters for requesting a payment quote.
+type QuoteParams struct {
+	Amount          string  `json:"amount"`
+	Currency        string  `json:"currency"`
+	TargetCurrency  *string `json:"target_currency,omitempty"`
+	Scheme          *Scheme `json:"scheme,omitempty"`
+	DebtorAccountID *string `json:"debtor_account_id,omitempty"`
+}
+
+// GetQuote returns the fees and exchange rate that would apply to a payment.
+// Pass the quote ID as PaymentCreateParams.QuoteID to lock the rate until the
+// quote expires.
+func (s *PaymentsService) GetQuote(ctx context.Context, params QuoteParams) (*Quote, error) {
+	var quote Quote
+	if err := s.client.request(ctx, "POST", "/payments/quotes", nil, params, &quote); err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// UploadBulkFile submits an ISO 20022 pain.001 payment initiation file, such
+// as one produced by iso20022.BuildPain001, for bulk execution.
+func (s *PaymentsService) UploadBulkFile(ctx context.Context, r io.Reader, opts ...RequestOption) (*BulkPayment, error) {
+	opts = append([]RequestOption{withContentType("application/xml")}, opts...)
+	var bulk BulkPayment
+	if err := s.client.request(ctx, "POST", "/payments/bulk", nil, r, &bulk, opts...); err != nil {
+		return nil, err
+	}
+	return &bulk, nil
+}
+
+// GetBulk gets the status of a bulk payment.
+func (s *PaymentsService) GetBulk(ctx context.Context, bulkID string) (*BulkPayment, error) {
+	var bulk BulkPayment
+	if err := s.client.request(ctx, "GET", "/payments/bulk/"+bulkID, nil, nil, &bulk); err != nil {
+		return nil, err
+	}
+	return &bulk, nil
+}
+
+// GetLimits gets the payment limits of a debtor account, so amounts can be
+// checked before a payment is submitted.
+func (s *PaymentsService) GetLimits(ctx context.Context, debtorAccountID string) (*PaymentLimits, error) {
+	values := url.Values{}
+	values.Set("debtor_account_id", debtorAccountID)
+
+	var limits PaymentLimits
+	if err := s.client.request(ctx, "GET", "/payments/limits", values, nil, &limits); err != nil {
+		return nil, err
+	}
+	return &limits, nil
+}
+
 // Get gets payment status.
 func (s *PaymentsService) Get(ctx context.Context, paymentID string) (*Payment, error) {
 	var payment Payment
@@ -997,25 +2140,15 @@ func (s *PaymentsService) Get(ctx context.Context, paymentID string) (*Payment,
 
 // PaymentListParams contains parameters for listing payments.
 type PaymentListParams struct {
-	Status *string
-	Limit  *int
-	Offset *int
+	Status       *PaymentStatus `url:"status"`
+	UpdatedSince *time.Time     `url:"updated_since"`
+	Limit        *int           `url:"limit"`
+	Offset       *int           `url:"offset"`
 }
 
 // List lists payments.
 func (s *PaymentsService) List(ctx context.Context, params *PaymentListParams) ([]Payment, error) {
-	values := url.Values{}
-	if params != nil {
-		if params.Status != nil {
-			values.Set("status", *params.Status)
-		}
-		if params.Limit != nil {
-			values.Set("limit", strconv.Itoa(*params.Limit))
-		}
-		if params.Offset != nil {
-			values.Set("offset", strconv.Itoa(*params.Offset))
-		}
-	}
+	values := encodeQuery(params)
 
 	var result struct {
 		Payments []Payment `json:"payments"`
@@ -1035,6 +2168,139 @@ func (s *PaymentsService) Cancel(ctx context.Context, paymentID string) (*Paymen
 	return &payment, nil
 }
 
+// RefundParams contains parameters for refunding a payment.
+type RefundParams struct {
+	// Amount is the amount to refund. A nil Amount refunds the full payment.
+	Amount *Amount `json:"amount,omitempty"`
+	Reason *string `json:"reason,omitempty"`
+}
+
+// Refund issues a full or partial refund for an executed payment.
+func (s *PaymentsService) Refund(ctx context.Context, paymentID string, params RefundParams, opts ...RequestOption) (*Refund, error) {
+	var refund Refund
+	if err := s.client.request(ctx, "POST", "/payments/"+paymentID+"/refunds", nil, params, &refund, opts...); err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// ListRefunds lists refunds issued for a payment.
+func (s *PaymentsService) ListRefunds(ctx context.Context, paymentID string) ([]Refund, error) {
+	var result struct {
+		Refunds []Refund `json:"refunds"`
+	}
+	if err := s.client.request(ctx, "GET", "/payments/"+paymentID+"/refunds", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Refunds, nil
+}
+
+// WatchOption configures Watch.
+type WatchOption func(*watchConfig)
+
+type watchConfig struct {
+	pollInterval time.Duration
+}
+
+// WithPollInterval sets how often Watch polls the payment while waiting for
+// real-time events. The default is 5 seconds.
+func WithPollInterval(interval time.Duration) WatchOption {
+	return func(c *watchConfig) {
+		c.pollInterval = interval
+	}
+}
+
+// Watch streams status changes of a single payment. The first event carries
+// the current status. Updates arrive over the Realtime service and are backed
+// by polling, so events are delivered even if the real-time connection is
+// unavailable. The channel is closed once the payment reaches a terminal
+// status or ctx is done.
+func (s *PaymentsService) Watch(ctx context.Context, paymentID string, opts ...WatchOption) (<-chan PaymentEvent, error) {
+	cfg := &watchConfig{pollInterval: 5 * time.Second}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	payment, err := s.Get(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan PaymentEvent)
+	updates := make(chan PaymentEvent)
+	stop := make(chan struct{})
+
+	sub, err := s.client.Realtime.Subscribe(ctx, SubscribeParams{
+		Events: []EventType{EventPaymentStatusChanged},
+		Handlers: EventHandlers{
+			OnPaymentStatusChanged: func(event PaymentEvent) {
+				if event.Data.ID != paymentID {
+					return
+				}
+				select {
+				case updates <- event:
+				case <-stop:
+				}
+			},
+		},
+	})
+	if err != nil {
+		// Fall back to polling only.
+		sub = nil
+	}
+
+	go func() {
+		defer close(events)
+		if sub != nil {
+			defer sub.Close(context.Background())
+		}
+		// Unblock the handler first, as closing the subscription waits for it.
+		defer close(stop)
+
+		var last PaymentStatus
+		// emit forwards a status change and reports whether to keep watching.
+		emit := func(event PaymentEvent) bool {
+			if event.Data.Status == last {
+				return true
+			}
+			last = event.Data.Status
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return false
+			}
+			return !event.Data.Status.IsTerminal()
+		}
+
+		if !emit(PaymentEvent{Type: EventPaymentStatusChanged, Data: *payment, Timestamp: s.client.now()}) {
+			return
+		}
+		poll := s.client.config.Clock.After(cfg.pollInterval)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-updates:
+				if !emit(event) {
+					return
+				}
+			case <-poll:
+				poll = s.client.config.Clock.After(cfg.pollInterval)
+				payment, err := s.Get(ctx, paymentID)
+				if err != nil {
+					// Transient failures are retried on the next poll.
+					continue
+				}
+				if !emit(PaymentEvent{Type: EventPaymentStatusChanged, Data: *payment, Timestamp: s.client.now()}) {
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
 // ConsentsService provides access to the Consents API.
 type ConsentsService struct {
 	client *Client
@@ -1042,10 +2308,13 @@ type ConsentsService struct {
 
 // ConsentCreateParams contains parameters for creating a consent.
 type ConsentCreateParams struct {
-	Access             []string `json:"access"`
-	ValidUntil         *string  `json:"valid_until,omitempty"`
-	RecurringIndicator *bool    `json:"recurring_indicator,omitempty"`
-	FrequencyPerDay    *int     `json:"frequency_per_day,omitempty"`
+	Type               *ConsentType  `json:"type,omitempty"`
+	Access             ConsentAccess `json:"access"`
+	ValidUntil         *string       `json:"valid_until,omitempty"`
+	RecurringIndicator *bool         `json:"recurring_indicator,omitempty"`
+	FrequencyPerDay    *int          `json:"frequency_per_day,omitempty"`
+	ReplacesConsentID  *string       `json:"replaces_consent_id,omitempty"`
+	PSUID              *string       `json:"psu_id,omitempty"`
 }
 
 // Create creates a new consent.
@@ -1082,36 +2351,227 @@ func (s *ConsentsService) List(ctx context.Context) ([]Consent, error) {
 	return result.Consents, nil
 }
 
+// Extend moves the expiry of a valid consent to newValidUntil. Banks that do
+// not support extension reject the request; use RenewWithSameAccess instead.
+func (s *ConsentsService) Extend(ctx context.Context, consentID string, newValidUntil time.Time) (*Consent, error) {
+	body := map[string]interface{}{
+		"valid_until": newValidUntil.Format("2006-01-02"),
+	}
+	var consent Consent
+	if err := s.client.request(ctx, "POST", "/consents/"+consentID+"/extend", nil, body, &consent); err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// RenewWithSameAccess creates a consent with the same type, PSU and access as
+// an existing one, valid until validUntil and linked to it as its
+// replacement. The new consent must be authorized by the PSU before the old
+// one expires to keep access uninterrupted.
+func (s *ConsentsService) RenewWithSameAccess(ctx context.Context, consentID string, validUntil time.Time) (*Consent, error) {
+	old, err := s.Get(ctx, consentID)
+	if err != nil {
+		return nil, err
+	}
+	params := ConsentCreateParams{
+		Access:             old.Access,
+		ValidUntil:         String(validUntil.Format("2006-01-02")),
+		RecurringIndicator: old.RecurringIndicator,
+		FrequencyPerDay:    old.FrequencyPerDay,
+		ReplacesConsentID:  String(old.ID),
+		PSUID:              old.PSUID,
+	}
+	if old.Type != "" {
+		params.Type = &old.Type
+	}
+	return s.Create(ctx, params)
+}
+
+// Reconfirm records the PSU's reconfirmation of a consent, extending access
+// without strong customer authentication for another reconfirmation period.
+func (s *ConsentsService) Reconfirm(ctx context.Context, consentID string) (*Consent, error) {
+	var consent Consent
+	if err := s.client.request(ctx, "POST", "/consents/"+consentID+"/reconfirm", nil, nil, &consent); err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// ListDueForReconfirmation lists consents that must be reconfirmed within the
+// given duration, such as 7*24*time.Hour for the next week.
+func (s *ConsentsService) ListDueForReconfirmation(ctx context.Context, within time.Duration) ([]Consent, error) {
+	consents, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	by := s.client.now().Add(within)
+	var due []Consent
+	for _, c := range consents {
+		if c.ReconfirmationDueBy(by) {
+			due = append(due, c)
+		}
+	}
+	return due, nil
+}
+
+// Authorize starts the PSU authorization of a consent and returns the URL of
+// the hosted authorization page. After the PSU completes it, they are
+// redirected to redirectURI with the given state; pass that request to
+// HandleCallback.
+func (s *ConsentsService) Authorize(ctx context.Context, consentID, redirectURI, state string) (string, error) {
+	body := map[string]interface{}{
+		"redirect_uri": redirectURI,
+	}
+	if state != "" {
+		body["state"] = state
+	}
+
+	var result struct {
+		AuthorizationURL string `json:"authorization_url"`
+	}
+	if err := s.client.request(ctx, "POST", "/consents/"+consentID+"/authorize", nil, body, &result); err != nil {
+		return "", err
+	}
+	return result.AuthorizationURL, nil
+}
+
+// HandleCallback completes a consent authorization from the redirect request
+// the PSU's browser made to the redirect URI, and returns the authorized
+// consent. The platform checks the state against the one passed to
+// Authorize. If the PSU declined or the bank failed the authorization, a
+// *ConsentAuthorizationError is returned.
+func (s *ConsentsService) HandleCallback(ctx context.Context, r *http.Request) (*Consent, error) {
+	query := r.URL.Query()
+	consentID := query.Get("consent_id")
+	state := query.Get("state")
+
+	if code := query.Get("error"); code != "" {
+		return nil, &ConsentAuthorizationError{
+			ConsentID:   consentID,
+			ErrorCode:   code,
+			Description: query.Get("error_description"),
+			State:       state,
+		}
+	}
+
+	var errs []FieldError
+	if consentID == "" {
+		errs = append(errs, FieldError{Field: "consent_id", Message: "missing from callback"})
+	}
+	code := query.Get("code")
+	if code == "" {
+		errs = append(errs, FieldError{Field: "code", Message: "missing from callback"})
+	}
+	if len(errs) > 0 {
+		return nil, &ValidationError{Message: "invalid consent callback", Code: ErrCodeInvalidCallback, Errors: errs}
+	}
+
+	body := map[string]interface{}{
+		"code":  code,
+		"state": state,
+	}
+	var consent Consent
+	if err := s.client.request(ctx, "POST", "/consents/"+consentID+"/authorize/callback", nil, body, &consent); err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// GetHistory gets the lifecycle of a consent in chronological order: status
+// transitions, strong customer authentication events and data access.
+func (s *ConsentsService) GetHistory(ctx context.Context, consentID string) ([]ConsentHistoryEntry, error) {
+	var result struct {
+		History []ConsentHistoryEntry `json:"history"`
+	}
+	if err := s.client.request(ctx, "GET", "/consents/"+consentID+"/history", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result.History, nil
+}
+
+// ListForPSU lists the consents given by a PSU.
+func (s *ConsentsService) ListForPSU(ctx context.Context, psuID string) ([]Consent, error) {
+	values := url.Values{}
+	values.Set("psu_id", psuID)
+
+	var result struct {
+		Consents []Consent `json:"consents"`
+	}
+	if err := s.client.request(ctx, "GET", "/consents", values, nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Consents, nil
+}
+
+// RevokeMany revokes the given consents and returns an item per consent,
+// keyed by consent ID, in the same order. The returned error is the
+// result's Err: it joins every failure and is nil when all consents were
+// revoked.
+func (s *ConsentsService) RevokeMany(ctx context.Context, ids []string) (BatchResult[struct{}], error) {
+	var result BatchResult[struct{}]
+	for _, id := range ids {
+		err := ctx.Err()
+		if err == nil {
+			err = s.Revoke(ctx, id)
+		}
+		result.Add(id, struct{}{}, err)
+	}
+	return result, result.Err()
+}
+
+// RevokeAllForPSU revokes every active consent given by a PSU, for example
+// when handling an erasure request or closing their account. Consents that
+// already ended are skipped.
+func (s *ConsentsService) RevokeAllForPSU(ctx context.Context, psuID string) (BatchResult[struct{}], error) {
+	consents, err := s.ListForPSU(ctx, psuID)
+	if err != nil {
+		return BatchResult[struct{}]{}, err
+	}
+	var ids []string
+	for _, c := range consents {
+		if !c.Status.IsTerminal() {
+			ids = append(ids, c.ID)
+		}
+	}
+	return s.RevokeMany(ctx, ids)
+}
+
 // InstitutionsService provides access to the Institutions API.
 type InstitutionsService struct {
-	client *Client
+	client  *Client
+	catalog institutionCatalog
+	logos   logoCache
 }
 
 // InstitutionListParams contains parameters for listing institutions.
+// Institutions in any of the given Countries are listed.
 type InstitutionListParams struct {
-	Country *string
-	Query   *string
-	Limit   *int
-	Offset  *int
+	// Deprecated: Use Countries.
+	Country         *string      `url:"country"`
+	Countries       []Country    `url:"country"`
+	Query           *string      `url:"query"`
+	SupportsFeature []Capability `url:"supports_feature"`
+	Limit           *int         `url:"limit"`
+	Offset          *int         `url:"offset"`
 }
 
 // List lists financial institutions.
 func (s *InstitutionsService) List(ctx context.Context, params *InstitutionListParams) ([]Institution, error) {
-	values := url.Values{}
 	if params != nil {
-		if params.Country != nil {
-			values.Set("country", *params.Country)
-		}
-		if params.Query != nil {
-			values.Set("query", *params.Query)
-		}
-		if params.Limit != nil {
-			values.Set("limit", strconv.Itoa(*params.Limit))
+		var errs []FieldError
+		for i, c := range params.Countries {
+			if !c.Valid() {
+				errs = append(errs, FieldError{
+					Field:   fmt.Sprintf("countries[%d]", i),
+					Message: fmt.Sprintf("%q is not an ISO 3166-1 alpha-2 country code", string(c)),
+				})
+			}
 		}
-		if params.Offset != nil {
-			values.Set("offset", strconv.Itoa(*params.Offset))
+		if len(errs) > 0 {
+			return nil, &ValidationError{Message: "invalid country", Code: ErrCodeInvalidCountry, Errors: errs}
 		}
 	}
+	values := encodeQuery(params)
 
 	var result struct {
 		Institutions []Institution `json:"institutions"`
@@ -1131,6 +2591,67 @@ func (s *InstitutionsService) Get(ctx context.Context, institutionID string) (*I
 	return &institution, nil
 }
 
+// LookupByBIC finds the institution identified by a BIC. A malformed BIC is
+// rejected with a *ValidationError without a request being sent.
+func (s *InstitutionsService) LookupByBIC(ctx context.Context, code string) (*Institution, error) {
+	parsed, err := bic.Parse(code)
+	if err != nil {
+		message := "must be a 4-character bank code, 2-letter country code, 2-character location and optional 3-character branch"
+		if errors.Is(err, bic.ErrInvalidLength) {
+			message = "must be 8 or 11 characters"
+		}
+		return nil, &ValidationError{
+			Message: "invalid BIC",
+			Code:    ErrCodeInvalidBIC,
+			Errors:  []FieldError{{Field: "bic", Message: message}},
+		}
+	}
+	values := url.Values{}
+	values.Set("bic", parsed.String())
+	return s.lookup(ctx, values)
+}
+
+// LookupByIBAN finds the institution that holds the account with the given
+// IBAN, so account-linking flows can pre-select the PSU's bank.
+func (s *InstitutionsService) LookupByIBAN(ctx context.Context, iban string) (*Institution, error) {
+	values := url.Values{}
+	values.Set("iban", strings.ToUpper(strings.ReplaceAll(iban, " ", "")))
+	return s.lookup(ctx, values)
+}
+
+func (s *InstitutionsService) lookup(ctx context.Context, values url.Values) (*Institution, error) {
+	var institution Institution
+	if err := s.client.request(ctx, "GET", "/institutions/lookup", values, nil, &institution); err != nil {
+		return nil, err
+	}
+	return &institution, nil
+}
+
+// GetSandboxCredentials gets the test PSU logins for the sandbox variant of
+// an institution. It is only available in the sandbox environment.
+func (s *InstitutionsService) GetSandboxCredentials(ctx context.Context, institutionID string) ([]SandboxCredential, error) {
+	if s.client.config.Environment == Production {
+		return nil, &Error{Message: "sandbox credentials are only available in the sandbox environment", Code: ErrCodeSandboxOnly}
+	}
+	var result struct {
+		Credentials []SandboxCredential `json:"credentials"`
+	}
+	if err := s.client.request(ctx, "GET", "/sandbox/institutions/"+institutionID+"/credentials", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Credentials, nil
+}
+
+// SupportsScheme reports whether an institution can execute payments on the
+// given scheme.
+func (s *InstitutionsService) SupportsScheme(ctx context.Context, institutionID string, scheme Scheme) (bool, error) {
+	institution, err := s.Get(ctx, institutionID)
+	if err != nil {
+		return false, err
+	}
+	return institution.SupportsScheme(scheme), nil
+}
+
 // AuthService provides authentication methods.
 type AuthService struct {
 	client *Client
@@ -1173,7 +2694,7 @@ func (s *AuthService) ExchangeCode(ctx context.Context, params ExchangeCodeParam
 
 	resp, err := s.client.httpClient.Do(req)
 	if err != nil {
-		return nil, &NetworkError{Message: err.Error()}
+		return nil, &NetworkError{Message: err.Error(), Err: err}
 	}
 	defer resp.Body.Close()
 
@@ -1205,7 +2726,7 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*T
 
 	resp, err := s.client.httpClient.Do(req)
 	if err != nil {
-		return nil, &NetworkError{Message: err.Error()}
+		return nil, &NetworkError{Message: err.Error(), Err: err}
 	}
 	defer resp.Body.Close()
 
@@ -1236,7 +2757,7 @@ func (s *AuthService) requestToken(ctx context.Context) (*TokenResponse, error)
 
 	resp, err := s.client.httpClient.Do(req)
 	if err != nil {
-		return nil, &NetworkError{Message: err.Error()}
+		return nil, &NetworkError{Message: err.Error(), Err: err}
 	}
 	defer resp.Body.Close()
 
@@ -1250,92 +2771,3 @@ func (s *AuthService) requestToken(ctx context.Context) (*TokenResponse, error)
 	}
 	return &tokens, nil
 }
-
-// RealtimeService provides WebSocket functionality.
-type RealtimeService struct {
-	client *Client
-}
-
-// EventType represents a real-time event type.
-type EventType string
-
-const (
-	// EventTransactionCreated is fired when a new transaction is created.
-	EventTransactionCreated EventType = "transaction.created"
-	// EventTransactionUpdated is fired when a transaction is updated.
-	EventTransactionUpdated EventType = "transaction.updated"
-	// EventBalanceUpdated is fired when an account balance changes.
-	EventBalanceUpdated EventType = "balance.updated"
-	// EventPaymentStatusChanged is fired when a payment status changes.
-	EventPaymentStatusChanged EventType = "payment.status_changed"
-	// EventConsentRevoked is fired when a consent is revoked.
-	EventConsentRevoked EventType = "consent.revoked"
-)
-
-// TransactionEvent represents a transaction event.
-type TransactionEvent struct {
-	Type      EventType    `json:"type"`
-	Data      Transaction  `json:"data"`
-	Timestamp time.Time    `json:"timestamp"`
-}
-
-// BalanceEvent represents a balance event.
-type BalanceEvent struct {
-	Type      EventType `json:"type"`
-	Data      Balance   `json:"data"`
-	Timestamp time.Time `json:"timestamp"`
-}
-
-// PaymentEvent represents a payment event.
-type PaymentEvent struct {
-	Type      EventType `json:"type"`
-	Data      Payment   `json:"data"`
-	Timestamp time.Time `json:"timestamp"`
-}
-
-// EventHandlers contains handlers for real-time events.
-type EventHandlers struct {
-	OnTransactionCreated   func(TransactionEvent)
-	OnTransactionUpdated   func(TransactionEvent)
-	OnBalanceUpdated       func(BalanceEvent)
-	OnPaymentStatusChanged func(PaymentEvent)
-	OnConsentRevoked       func(event struct{ ConsentID string })
-	OnError                func(error)
-}
-
-// SubscribeParams contains parameters for subscribing to events.
-type SubscribeParams struct {
-	AccountID string
-	Events    []EventType
-	Handlers  EventHandlers
-}
-
-// Subscription represents a WebSocket subscription.
-type Subscription struct {
-	done chan struct{}
-}
-
-// Wait waits for the subscription to complete.
-func (s *Subscription) Wait() error {
-	<-s.done
-	return nil
-}
-
-// Close closes the subscription.
-func (s *Subscription) Close() {
-	close(s.done)
-}
-
-// Subscribe subscribes to real-time events.
-// Note: This is a placeholder implementation. Full WebSocket implementation
-// would require a WebSocket library like gorilla/websocket.
-func (s *RealtimeService) Subscribe(ctx context.Context, params SubscribeParams) (*Subscription, error) {
-	// In a full implementation, this would:
-	// 1. Connect to WebSocket endpoint
-	// 2. Send subscription message
-	// 3. Handle incoming events
-
-	return &Subscription{
-		done: make(chan struct{}),
-	}, nil
-}
@@ -0,0 +1,54 @@
+package openibank
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the client the time and waits for durations to pass. The
+// client uses it for access token expiry, waits between retries, including
+// those a Retry-After header asks for, rate limit and consent quota resets,
+// the institution catalog's lifetime, consent reconfirmation deadlines, the
+// polling of PaymentsService.Watch, and the reconnection and polling of
+// real-time subscriptions. Tests can set a fake clock WithClock, such as
+// openibanktest.Clock, to fast-forward time instead of waiting. Model
+// helpers that depend on the time, such as Consent.IsUsable, read the system
+// clock; their variants taking a time, such as Consent.UsableAt, accept the
+// clock's.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel that receives the current time once d has
+	// passed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// WithClock sets the clock the client tells the time and waits with. The
+// default is the system clock.
+func WithClock(clock Clock) Option {
+	return func(c *Config) {
+		c.Clock = clock
+	}
+}
+
+// systemClock is the Clock of the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// now returns the current time of the client's clock.
+func (c *Client) now() time.Time {
+	return c.config.Clock.Now()
+}
+
+// sleep waits for d to pass on the client's clock, and returns ctx's error
+// if ctx is done first.
+func (c *Client) sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-c.config.Clock.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
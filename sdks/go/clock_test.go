@@ -0,0 +1,80 @@
+package openibank_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	openibank "github.com/openibank/sdk-go"
+	"github.com/openibank/sdk-go/openibanktest"
+)
+
+func TestWatchPollsOnClock(t *testing.T) {
+	srv := openibanktest.NewServer()
+	defer srv.Close()
+	srv.AddPayment(openibank.Payment{ID: "pay_1", Status: openibank.PaymentStatusReceived})
+	clock := openibanktest.NewClock(time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC))
+	client := srv.Client(openibank.WithClock(clock), openibank.WithRealtimeReconnect(0, time.Second))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	events, err := client.Payments.Watch(ctx, "pay_1", openibank.WithPollInterval(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := <-events
+	if first.Data.Status != openibank.PaymentStatusReceived || !first.Timestamp.Equal(clock.Now()) {
+		t.Fatalf("first event = %s at %v", first.Data.Status, first.Timestamp)
+	}
+
+	if _, err := client.Sandbox.SimulatePaymentStatus(ctx, "pay_1", openibank.PaymentStatusAcceptedSettlementCompleted, ""); err != nil {
+		t.Fatal(err)
+	}
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	select {
+	case event := <-events:
+		if event.Data.Status != openibank.PaymentStatusAcceptedSettlementCompleted {
+			t.Errorf("status = %s, want ACSC", event.Data.Status)
+		}
+	case <-ctx.Done():
+		t.Fatal("no event after advancing the clock past the poll interval")
+	}
+	if _, ok := <-events; ok {
+		t.Error("channel not closed after a terminal status")
+	}
+}
+
+func TestListDueForReconfirmationUsesClock(t *testing.T) {
+	srv := openibanktest.NewServer()
+	defer srv.Close()
+	now := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	soon, later := now.Add(3*24*time.Hour), now.Add(30*24*time.Hour)
+	srv.AddConsent(openibank.Consent{ID: "con_soon", ReconfirmBy: &soon})
+	srv.AddConsent(openibank.Consent{ID: "con_later", ReconfirmBy: &later})
+	client := srv.Client(openibank.WithClock(openibanktest.NewClock(now)))
+
+	due, err := client.Consents.ListDueForReconfirmation(context.Background(), 7*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 || due[0].ID != "con_soon" {
+		t.Errorf("due = %v, want con_soon only", due)
+	}
+}
+
+func TestConsentTimeVariants(t *testing.T) {
+	now := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	validUntil := now.Add(time.Hour)
+	consent := openibank.Consent{Status: openibank.ConsentStatusValid, ValidUntil: &validUntil}
+	if !consent.UsableAt(now) || consent.UsableAt(validUntil) {
+		t.Error("UsableAt does not follow ValidUntil")
+	}
+	if consent.ExpiresBy(now) || !consent.ExpiresBy(now.Add(2*time.Hour)) {
+		t.Error("ExpiresBy does not follow ValidUntil")
+	}
+	quote := openibank.Quote{ExpiresAt: validUntil}
+	if quote.ExpiredAt(now) || !quote.ExpiredAt(validUntil) {
+		t.Error("ExpiredAt does not follow ExpiresAt")
+	}
+}
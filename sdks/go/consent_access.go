@@ -0,0 +1,158 @@
+package openibank
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// AccountReference identifies an account a consent grants access to.
+type AccountReference struct {
+	IBAN      *string `json:"iban,omitempty"`
+	BBAN      *string `json:"bban,omitempty"`
+	MaskedPAN *string `json:"masked_pan,omitempty"`
+	AccountID *string `json:"account_id,omitempty"`
+	Currency  *string `json:"currency,omitempty"`
+}
+
+// AvailableAccounts requests access to the list of the PSU's accounts
+// without naming them.
+type AvailableAccounts string
+
+const (
+	// AllAccounts requests the list of all accounts.
+	AllAccounts AvailableAccounts = "all_accounts"
+	// AllAccountsWithBalances requests the list of all accounts with their
+	// balances.
+	AllAccountsWithBalances AvailableAccounts = "all_accounts_with_balances"
+)
+
+// Legacy access scopes accepted by ConsentAccessFromScopes.
+const (
+	ScopeAccounts     = "accounts"
+	ScopeBalances     = "balances"
+	ScopeTransactions = "transactions"
+)
+
+// ConsentAccess describes what a consent grants access to, following the
+// Berlin Group model.
+//
+// Accounts, Balances and Transactions list the accounts whose details,
+// balances and transactions may be read. A nil list requests no access of
+// that kind; an empty, non-nil list requests it for accounts the PSU selects
+// at their bank. AvailableAccounts requests only the list of accounts and
+// AllPSD2 requests every kind of access on all accounts.
+type ConsentAccess struct {
+	Accounts          []AccountReference
+	Balances          []AccountReference
+	Transactions      []AccountReference
+	AvailableAccounts *AvailableAccounts
+	AllPSD2           *AvailableAccounts
+}
+
+// ConsentAccessFromScopes converts legacy access scopes such as
+// []string{"accounts", "transactions"} to a ConsentAccess for accounts the
+// PSU selects at their bank.
+func ConsentAccessFromScopes(scopes []string) ConsentAccess {
+	var access ConsentAccess
+	for _, scope := range scopes {
+		switch scope {
+		case ScopeAccounts:
+			access.Accounts = []AccountReference{}
+		case ScopeBalances:
+			access.Balances = []AccountReference{}
+		case ScopeTransactions:
+			access.Transactions = []AccountReference{}
+		}
+	}
+	return access
+}
+
+// Scopes returns the legacy access scopes covered by the access.
+func (a ConsentAccess) Scopes() []string {
+	var scopes []string
+	if a.Accounts != nil || a.AllPSD2 != nil {
+		scopes = append(scopes, ScopeAccounts)
+	}
+	if a.Balances != nil || a.AllPSD2 != nil {
+		scopes = append(scopes, ScopeBalances)
+	}
+	if a.Transactions != nil || a.AllPSD2 != nil {
+		scopes = append(scopes, ScopeTransactions)
+	}
+	return scopes
+}
+
+type consentAccessJSON struct {
+	Accounts          *[]AccountReference `json:"accounts,omitempty"`
+	Balances          *[]AccountReference `json:"balances,omitempty"`
+	Transactions      *[]AccountReference `json:"transactions,omitempty"`
+	AvailableAccounts *AvailableAccounts  `json:"available_accounts,omitempty"`
+	AllPSD2           *AvailableAccounts  `json:"all_psd2,omitempty"`
+}
+
+// MarshalJSON encodes the access, keeping empty lists so that access to
+// PSU-selected accounts is distinguished from no access.
+func (a ConsentAccess) MarshalJSON() ([]byte, error) {
+	v := consentAccessJSON{
+		AvailableAccounts: a.AvailableAccounts,
+		AllPSD2:           a.AllPSD2,
+	}
+	if a.Accounts != nil {
+		v.Accounts = &a.Accounts
+	}
+	if a.Balances != nil {
+		v.Balances = &a.Balances
+	}
+	if a.Transactions != nil {
+		v.Transactions = &a.Transactions
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes the access object, and also accepts the legacy form
+// of a list of scopes.
+func (a *ConsentAccess) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '[' {
+		var scopes []string
+		if err := json.Unmarshal(data, &scopes); err != nil {
+			return fmt.Errorf("invalid consent access scopes: %w", err)
+		}
+		*a = ConsentAccessFromScopes(scopes)
+		return nil
+	}
+
+	var v consentAccessJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*a = ConsentAccess{
+		AvailableAccounts: v.AvailableAccounts,
+		AllPSD2:           v.AllPSD2,
+	}
+	if v.Accounts != nil {
+		a.Accounts = nonNil(*v.Accounts)
+	}
+	if v.Balances != nil {
+		a.Balances = nonNil(*v.Balances)
+	}
+	if v.Transactions != nil {
+		a.Transactions = nonNil(*v.Transactions)
+	}
+	return nil
+}
+
+// nonNil returns refs, or an empty list if refs is nil, so that a present
+// list always decodes to a non-nil slice. A JSON null never reaches it: it
+// leaves the pointer nil and decodes to no access, while [] decodes to
+// access for PSU-selected accounts.
+func nonNil(refs []AccountReference) []AccountReference {
+	if refs == nil {
+		return []AccountReference{}
+	}
+	return refs
+}
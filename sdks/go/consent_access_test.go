@@ -0,0 +1,33 @@
+package openibank
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestConsentAccessUnmarshal(t *testing.T) {
+	iban := "DE89370400440532013000"
+	tests := []struct {
+		name string
+		json string
+		want ConsentAccess
+	}{
+		{"null list means no access", `{"accounts":null,"balances":[]}`, ConsentAccess{Balances: []AccountReference{}}},
+		{"empty list means PSU-selected accounts", `{"accounts":[]}`, ConsentAccess{Accounts: []AccountReference{}}},
+		{"missing list means no access", `{"transactions":[{"iban":"` + iban + `"}]}`, ConsentAccess{Transactions: []AccountReference{{IBAN: &iban}}}},
+		{"legacy scopes", `["accounts","transactions"]`, ConsentAccess{Accounts: []AccountReference{}, Transactions: []AccountReference{}}},
+	}
+	// DeepEqual tells nil lists from empty ones.
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ConsentAccess
+			if err := json.Unmarshal([]byte(tt.json), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decoded %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package openibank_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	openibank "github.com/openibank/sdk-go"
+)
+
+// renewExecutor serves a consent and records the consent created from it.
+type renewExecutor struct {
+	consent string
+	created map[string]interface{}
+}
+
+func (e *renewExecutor) Execute(ctx context.Context, call *openibank.Call) (*openibank.Response, error) {
+	header := http.Header{"Content-Type": {"application/json"}}
+	if call.Method == http.MethodPost {
+		if err := json.Unmarshal(call.Body, &e.created); err != nil {
+			return nil, err
+		}
+		return &openibank.Response{StatusCode: http.StatusCreated, Header: header, Body: []byte(`{"id":"con_2","status":"received"}`)}, nil
+	}
+	return &openibank.Response{StatusCode: http.StatusOK, Header: header, Body: []byte(e.consent)}, nil
+}
+
+func TestRenewWithSameAccess(t *testing.T) {
+	executor := &renewExecutor{consent: `{
+		"id": "con_1",
+		"type": "account_information",
+		"status": "valid",
+		"psu_id": "psu_42",
+		"access": {"accounts": [{"account_id": "acc_1"}]},
+		"recurring_indicator": true,
+		"frequency_per_day": 4
+	}`}
+	client := openibank.NewClient(openibank.WithExecutor(executor))
+	validUntil := time.Date(2030, 6, 30, 0, 0, 0, 0, time.UTC)
+	if _, err := client.Consents.RenewWithSameAccess(context.Background(), "con_1", validUntil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"type":                "account_information",
+		"psu_id":              "psu_42",
+		"valid_until":         "2030-06-30",
+		"recurring_indicator": true,
+		"frequency_per_day":   float64(4),
+		"replaces_consent_id": "con_1",
+	}
+	for field, value := range want {
+		if got := executor.created[field]; got != value {
+			t.Errorf("%s = %v, want %v", field, got, value)
+		}
+	}
+	if executor.created["access"] == nil {
+		t.Error("access not sent")
+	}
+}
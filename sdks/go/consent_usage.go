@@ -0,0 +1,152 @@
+package openibank
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// EndpointClass groups account information endpoints that share a daily
+// access quota under a consent's frequency_per_day.
+type EndpointClass string
+
+const (
+	// EndpointAccounts covers account list and details calls.
+	EndpointAccounts EndpointClass = "accounts"
+	// EndpointBalances covers balance calls.
+	EndpointBalances EndpointClass = "balances"
+	// EndpointTransactions covers transaction list and details calls.
+	EndpointTransactions EndpointClass = "transactions"
+)
+
+// UsageCount is the number of calls used and remaining today for one
+// endpoint class.
+type UsageCount struct {
+	Used      int `json:"used"`
+	Remaining int `json:"remaining"`
+}
+
+// ConsentUsage reports how much of a consent's daily access quota has been
+// consumed.
+type ConsentUsage struct {
+	ConsentID       string                       `json:"consent_id"`
+	FrequencyPerDay int                          `json:"frequency_per_day"`
+	Usage           map[EndpointClass]UsageCount `json:"usage"`
+	ResetsAt        time.Time                    `json:"resets_at"`
+}
+
+// GetUsage gets the calls used and remaining today under a consent, per
+// endpoint class.
+func (s *ConsentsService) GetUsage(ctx context.Context, consentID string) (*ConsentUsage, error) {
+	var usage ConsentUsage
+	if err := s.client.request(ctx, "GET", "/consents/"+consentID+"/usage", nil, nil, &usage); err != nil {
+		return nil, err
+	}
+	for class, count := range usage.Usage {
+		s.client.quota.set(consentID, class, count.Remaining, usage.ResetsAt)
+	}
+	return &usage, nil
+}
+
+// WithConsent makes an account information request under the given consent.
+// The consent ID is sent in the Consent-ID header.
+func WithConsent(consentID string) RequestOption {
+	return func(c *requestConfig) {
+		c.consentID = consentID
+	}
+}
+
+// withEndpointClass tags a request with the quota class it counts against.
+func withEndpointClass(class EndpointClass) RequestOption {
+	return func(c *requestConfig) {
+		c.endpointClass = class
+	}
+}
+
+// WithConsentQuotaEnforcement makes the client refuse account information
+// requests made WithConsent once the consent's daily quota for the endpoint
+// class is known to be used up, instead of sending them to the bank. Quota
+// state is learned from GetUsage and from the usage headers on responses.
+func WithConsentQuotaEnforcement(enabled bool) Option {
+	return func(c *Config) {
+		c.EnforceConsentQuota = enabled
+	}
+}
+
+// ConsentQuotaExceededError indicates that a request was refused locally
+// because the consent's daily quota for the endpoint class is used up.
+type ConsentQuotaExceededError struct {
+	ConsentID string        `json:"consent_id"`
+	Class     EndpointClass `json:"endpoint_class"`
+	ResetsAt  time.Time     `json:"resets_at"`
+}
+
+func (e *ConsentQuotaExceededError) Error() string {
+	return fmt.Sprintf("consent %s quota for %s exhausted until %s", e.ConsentID, e.Class, e.ResetsAt.Format(time.RFC3339))
+}
+
+type quotaKey struct {
+	consentID string
+	class     EndpointClass
+}
+
+type quotaState struct {
+	remaining int
+	resetsAt  time.Time
+}
+
+// quotaTracker remembers the remaining daily calls per consent and endpoint
+// class.
+type quotaTracker struct {
+	mu     sync.Mutex
+	states map[quotaKey]quotaState
+}
+
+func (t *quotaTracker) set(consentID string, class EndpointClass, remaining int, resetsAt time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.states == nil {
+		t.states = map[quotaKey]quotaState{}
+	}
+	t.states[quotaKey{consentID, class}] = quotaState{remaining: remaining, resetsAt: resetsAt}
+}
+
+// check returns an error if the quota is known to be exhausted at now.
+func (t *quotaTracker) check(consentID string, class EndpointClass, now time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state, ok := t.states[quotaKey{consentID, class}]
+	if !ok || now.After(state.resetsAt) || state.remaining > 0 {
+		return nil
+	}
+	return &ConsentQuotaExceededError{ConsentID: consentID, Class: class, ResetsAt: state.resetsAt}
+}
+
+// record updates the quota from the X-Consent-Usage-Remaining and
+// X-Consent-Usage-Reset response headers, or counts the call down when they
+// are absent. now is the time the response was received.
+func (t *quotaTracker) record(consentID string, class EndpointClass, header http.Header, now time.Time) {
+	if remaining, err := strconv.Atoi(header.Get("X-Consent-Usage-Remaining")); err == nil {
+		resetsAt, err := time.Parse(time.RFC3339, header.Get("X-Consent-Usage-Reset"))
+		if err != nil {
+			resetsAt = nextMidnightUTC(now)
+		}
+		t.set(consentID, class, remaining, resetsAt)
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := quotaKey{consentID, class}
+	if state, ok := t.states[key]; ok && state.remaining > 0 {
+		state.remaining--
+		t.states[key] = state
+	}
+}
+
+func nextMidnightUTC(now time.Time) time.Time {
+	return dateOf(now.UTC()).AddDate(0, 0, 1)
+}
@@ -0,0 +1,126 @@
+// Package consents provides ready-made consent parameters for common
+// products.
+//
+// Example usage:
+//
+//	consent, err := client.Consents.Create(ctx, consents.TemplateAISReadOnly(90*24*time.Hour))
+package consents
+
+import (
+	"fmt"
+	"time"
+
+	openibank "github.com/openibank/sdk-go"
+)
+
+// MaxUnattendedFrequencyPerDay is the number of daily account information
+// calls allowed without the PSU being present under PSD2.
+const MaxUnattendedFrequencyPerDay = 4
+
+// TemplateAISReadOnly returns parameters for a recurring, read-only consent
+// to the details, balances and transactions of the accounts the PSU selects
+// at their bank, valid for the given duration.
+func TemplateAISReadOnly(validFor time.Duration) openibank.ConsentCreateParams {
+	return openibank.ConsentCreateParams{
+		Type: consentType(openibank.ConsentTypeAccountInformation),
+		Access: openibank.ConsentAccess{
+			Accounts:     []openibank.AccountReference{},
+			Balances:     []openibank.AccountReference{},
+			Transactions: []openibank.AccountReference{},
+		},
+		ValidUntil:         validUntil(validFor),
+		RecurringIndicator: openibank.Bool(true),
+		FrequencyPerDay:    openibank.Int(MaxUnattendedFrequencyPerDay),
+	}
+}
+
+// TemplateAISAccounts returns parameters for a recurring, read-only consent
+// to the details, balances and transactions of the given IBANs, valid for
+// the given duration.
+func TemplateAISAccounts(validFor time.Duration, ibans ...string) openibank.ConsentCreateParams {
+	refs := make([]openibank.AccountReference, len(ibans))
+	for i, iban := range ibans {
+		refs[i] = openibank.AccountReference{IBAN: openibank.String(iban)}
+	}
+	params := TemplateAISReadOnly(validFor)
+	params.Access = openibank.ConsentAccess{
+		Accounts:     refs,
+		Balances:     refs,
+		Transactions: refs,
+	}
+	return params
+}
+
+// TemplateAISOneOff returns parameters for a one-off consent to read the
+// details, balances and transactions of PSU-selected accounts once, while
+// the PSU is present.
+func TemplateAISOneOff() openibank.ConsentCreateParams {
+	params := TemplateAISReadOnly(24 * time.Hour)
+	params.RecurringIndicator = openibank.Bool(false)
+	params.FrequencyPerDay = openibank.Int(1)
+	return params
+}
+
+// TemplateAvailableAccounts returns parameters for a one-off consent to list
+// the PSU's accounts, typically used to let the PSU pick accounts before a
+// detailed consent is requested.
+func TemplateAvailableAccounts() openibank.ConsentCreateParams {
+	all := openibank.AllAccounts
+	return openibank.ConsentCreateParams{
+		Type:               consentType(openibank.ConsentTypeAccountInformation),
+		Access:             openibank.ConsentAccess{AvailableAccounts: &all},
+		ValidUntil:         validUntil(24 * time.Hour),
+		RecurringIndicator: openibank.Bool(false),
+		FrequencyPerDay:    openibank.Int(1),
+	}
+}
+
+// TemplatePIS returns parameters for a payment initiation consent. A single
+// payment consent authorizes exactly one payment; otherwise the consent
+// authorizes recurring payments until it is revoked.
+func TemplatePIS(singlePayment bool) openibank.ConsentCreateParams {
+	params := openibank.ConsentCreateParams{
+		Type:               consentType(openibank.ConsentTypePaymentInitiation),
+		RecurringIndicator: openibank.Bool(!singlePayment),
+	}
+	if singlePayment {
+		params.FrequencyPerDay = openibank.Int(1)
+	}
+	return params
+}
+
+func consentType(t openibank.ConsentType) *openibank.ConsentType {
+	return &t
+}
+
+func validUntil(validFor time.Duration) *string {
+	return openibank.String(time.Now().Add(validFor).Format("2006-01-02"))
+}
+
+// Validate checks consent parameters locally before they are sent, such as
+// the currencies of the account references. Validation failures are reported
+// as a *openibank.ValidationError listing every invalid field.
+func Validate(params openibank.ConsentCreateParams) error {
+	var errs []openibank.FieldError
+	check := func(field string, refs []openibank.AccountReference) {
+		for i, ref := range refs {
+			if ref.Currency != nil && !openibank.Currency(*ref.Currency).Valid() {
+				errs = append(errs, openibank.FieldError{
+					Field:   fmt.Sprintf("access.%s[%d].currency", field, i),
+					Message: "must be an ISO 4217 code",
+				})
+			}
+		}
+	}
+	check("accounts", params.Access.Accounts)
+	check("balances", params.Access.Balances)
+	check("transactions", params.Access.Transactions)
+	if len(errs) == 0 {
+		return nil
+	}
+	return &openibank.ValidationError{
+		Message: "invalid consent",
+		Code:    openibank.ErrCodeInvalidConsent,
+		Errors:  errs,
+	}
+}
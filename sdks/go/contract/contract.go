@@ -0,0 +1,521 @@
+// Package contract checks the client's requests and responses against an
+// OpenAPI 3 specification of the API, to catch drift between the SDK's
+// models and the platform before a release. A Checker is an HTTP transport
+// that records, for every request it carries, the ways the request and its
+// response do not match the specification: undocumented paths, methods,
+// query parameters and status codes, missing required fields, values of the
+// wrong type and values outside an enum.
+//
+// Example usage, in a test run against the sandbox:
+//
+//	spec, err := contract.Load(specJSON)
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	checker := contract.NewChecker(spec, nil)
+//	client := openibank.NewClient(
+//	    openibank.WithHTTPClient(&http.Client{Transport: checker}),
+//	    openibank.WithClientCredentials(id, secret),
+//	)
+//	// ... exercise the client ...
+//	for _, v := range checker.Violations() {
+//	    t.Error(v)
+//	}
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Spec is a parsed OpenAPI 3 specification. Only what the checks need is
+// parsed: paths, operations, parameters, request and response bodies, and
+// the schemas they refer to.
+type Spec struct {
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+
+	// basePath is the path of the first server's URL, such as "/v2".
+	basePath string
+}
+
+// Operation is an operation of a path.
+type Operation struct {
+	Parameters  []Parameter `json:"parameters"`
+	RequestBody *struct {
+		Required bool                 `json:"required"`
+		Content  map[string]MediaType `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]MediaType `json:"content"`
+	} `json:"responses"`
+}
+
+// Parameter is a parameter of an operation.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// MediaType is the content of a request or response body.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is a JSON schema. Type is a string in OpenAPI 3.0, and may be a
+// list of strings, including "null", in OpenAPI 3.1.
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 interface{}        `json:"type"`
+	Nullable             bool               `json:"nullable"`
+	Enum                 []interface{}      `json:"enum"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*Schema `json:"properties"`
+	AdditionalProperties interface{}        `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	AllOf                []*Schema          `json:"allOf"`
+	OneOf                []*Schema          `json:"oneOf"`
+	AnyOf                []*Schema          `json:"anyOf"`
+}
+
+// Load parses a specification in JSON.
+func Load(data []byte) (*Spec, error) {
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("contract: invalid specification: %w", err)
+	}
+	if len(spec.Paths) == 0 {
+		return nil, errors.New("contract: specification has no paths")
+	}
+	if len(spec.Servers) > 0 {
+		if u, err := url.Parse(spec.Servers[0].URL); err == nil {
+			spec.basePath = strings.TrimSuffix(u.Path, "/")
+		}
+	}
+	return &spec, nil
+}
+
+// Violation is a way a request or its response does not match the
+// specification. Location is "request", "query" or "response"; Field is the
+// JSON path of the value, such as "creditor.account.iban", or empty.
+type Violation struct {
+	Method   string
+	Path     string
+	Status   int
+	Location string
+	Field    string
+	Message  string
+}
+
+func (v Violation) Error() string {
+	where := v.Location
+	if v.Status != 0 {
+		where += " " + strconv.Itoa(v.Status)
+	}
+	if v.Field != "" {
+		where += " " + v.Field
+	}
+	return fmt.Sprintf("%s %s: %s: %s", v.Method, v.Path, where, v.Message)
+}
+
+// Checker is an http.RoundTripper that checks requests and responses
+// against a specification. It never fails a request; the violations it
+// finds are returned by Violations. A Checker is safe for concurrent use.
+type Checker struct {
+	spec *Spec
+	base http.RoundTripper
+
+	mu         sync.Mutex
+	violations []Violation
+}
+
+// NewChecker returns a Checker that sends requests with base, or with
+// http.DefaultTransport if base is nil.
+func NewChecker(spec *Spec, base http.RoundTripper) *Checker {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Checker{spec: spec, base: base}
+}
+
+// Violations returns the violations found so far, in the order they were
+// found.
+func (c *Checker) Violations() []Violation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Violation(nil), c.violations...)
+}
+
+// Err returns the violations found so far joined as one error, or nil if
+// there are none.
+func (c *Checker) Err() error {
+	var errs []error
+	for _, v := range c.Violations() {
+		errs = append(errs, v)
+	}
+	return errors.Join(errs...)
+}
+
+// Reset forgets the violations found so far.
+func (c *Checker) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.violations = nil
+}
+
+func (c *Checker) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	// Token requests are part of OAuth, not of the specification.
+	if strings.HasSuffix(req.URL.Path, "/oauth/token") || req.Header.Get("Accept") == "text/event-stream" {
+		return c.base.RoundTrip(req)
+	}
+
+	op, template := c.spec.operation(req.Method, req.URL.Path)
+	report := func(status int, location, field, message string) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		path := template
+		if path == "" {
+			path = req.URL.Path
+		}
+		c.violations = append(c.violations, Violation{
+			Method: req.Method, Path: path, Status: status,
+			Location: location, Field: field, Message: message,
+		})
+	}
+	if op == nil {
+		if template == "" {
+			report(0, "request", "", "path is not in the specification")
+		} else {
+			report(0, "request", "", "method is not in the specification")
+		}
+		return c.base.RoundTrip(req)
+	}
+	c.checkRequest(op, req, body, func(location, field, message string) { report(0, location, field, message) })
+
+	resp, err := c.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	c.checkResponse(op, resp, respBody, func(field, message string) { report(resp.StatusCode, "response", field, message) })
+	return resp, nil
+}
+
+func (c *Checker) checkRequest(op *Operation, req *http.Request, body []byte, report func(location, field, message string)) {
+	query := req.URL.Query()
+	declared := map[string]bool{}
+	for _, p := range op.Parameters {
+		if p.In != "query" {
+			continue
+		}
+		declared[p.Name] = true
+		values, ok := query[p.Name]
+		if !ok {
+			if p.Required {
+				report("query", p.Name, "required parameter is missing")
+			}
+			continue
+		}
+		if p.Schema != nil {
+			for _, v := range values {
+				if s := c.spec.resolve(p.Schema); len(s.Enum) > 0 && !inEnum(v, s.Enum) {
+					report("query", p.Name, fmt.Sprintf("value %q is not one of %v", v, s.Enum))
+				}
+			}
+		}
+	}
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !declared[name] {
+			report("query", name, "parameter is not in the specification")
+		}
+	}
+
+	if op.RequestBody == nil {
+		if len(body) > 0 {
+			report("request", "", "operation takes no body")
+		}
+		return
+	}
+	if len(body) == 0 {
+		if op.RequestBody.Required {
+			report("request", "", "required body is missing")
+		}
+		return
+	}
+	media, ok := op.RequestBody.Content["application/json"]
+	if !ok || media.Schema == nil || !strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		return
+	}
+	c.checkJSON(body, media.Schema, func(field, message string) { report("request", field, message) })
+}
+
+func (c *Checker) checkResponse(op *Operation, resp *http.Response, body []byte, report func(field, message string)) {
+	status := strconv.Itoa(resp.StatusCode)
+	response, ok := op.Responses[status]
+	if !ok {
+		response, ok = op.Responses[status[:1]+"XX"]
+	}
+	if !ok {
+		response, ok = op.Responses["default"]
+	}
+	if !ok {
+		report("", "status is not in the specification")
+		return
+	}
+	media, ok := response.Content["application/json"]
+	if !ok || media.Schema == nil || len(body) == 0 {
+		return
+	}
+	c.checkJSON(body, media.Schema, report)
+}
+
+func (c *Checker) checkJSON(body []byte, schema *Schema, report func(field, message string)) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		report("", "body is not valid JSON")
+		return
+	}
+	c.spec.validate(value, schema, "", report, 0)
+}
+
+// maxDepth bounds the nesting of schemas followed, for recursive schemas.
+const maxDepth = 32
+
+// validate reports the ways value does not match schema.
+func (s *Spec) validate(value interface{}, schema *Schema, field string, report func(field, message string), depth int) {
+	if schema == nil || depth > maxDepth {
+		return
+	}
+	schema = s.resolve(schema)
+	for _, sub := range schema.AllOf {
+		s.validate(value, sub, field, report, depth+1)
+	}
+	if alternatives := append(append([]*Schema(nil), schema.OneOf...), schema.AnyOf...); len(alternatives) > 0 {
+		matched := false
+		for _, sub := range alternatives {
+			ok := true
+			s.validate(value, sub, field, func(string, string) { ok = false }, depth+1)
+			if ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			report(field, "value matches none of the alternatives")
+		}
+	}
+
+	if value == nil {
+		if !schema.Nullable && !hasType(schema, "null") && schemaType(schema) != "" {
+			report(field, "value is null")
+		}
+		return
+	}
+	if len(schema.Enum) > 0 && !inEnum(value, schema.Enum) {
+		report(field, fmt.Sprintf("value %v is not one of %v", value, schema.Enum))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if !hasType(schema, "object") && schemaType(schema) != "" {
+			report(field, "value is an object, expected "+schemaType(schema))
+			return
+		}
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				report(join(field, name), "required field is missing")
+			}
+		}
+		for name, item := range v {
+			if sub, ok := schema.Properties[name]; ok {
+				s.validate(item, sub, join(field, name), report, depth+1)
+			} else if sub, ok := schema.AdditionalProperties.(map[string]interface{}); ok {
+				var additional Schema
+				if data, err := json.Marshal(sub); err == nil && json.Unmarshal(data, &additional) == nil {
+					s.validate(item, &additional, join(field, name), report, depth+1)
+				}
+			}
+		}
+	case []interface{}:
+		if !hasType(schema, "array") && schemaType(schema) != "" {
+			report(field, "value is an array, expected "+schemaType(schema))
+			return
+		}
+		for i, item := range v {
+			s.validate(item, schema.Items, fmt.Sprintf("%s[%d]", field, i), report, depth+1)
+		}
+	case string:
+		if !hasType(schema, "string") && schemaType(schema) != "" {
+			report(field, "value is a string, expected "+schemaType(schema))
+		}
+	case json.Number:
+		switch {
+		case hasType(schema, "number"):
+		case hasType(schema, "integer"):
+			if _, err := v.Int64(); err != nil {
+				report(field, "value is not an integer")
+			}
+		case schemaType(schema) != "":
+			report(field, "value is a number, expected "+schemaType(schema))
+		}
+	case bool:
+		if !hasType(schema, "boolean") && schemaType(schema) != "" {
+			report(field, "value is a boolean, expected "+schemaType(schema))
+		}
+	}
+}
+
+// resolve follows the $ref of schema, if any, to a component schema.
+func (s *Spec) resolve(schema *Schema) *Schema {
+	for i := 0; schema.Ref != "" && i < maxDepth; i++ {
+		name, ok := strings.CutPrefix(schema.Ref, "#/components/schemas/")
+		target := s.Components.Schemas[name]
+		if !ok || target == nil {
+			return &Schema{}
+		}
+		schema = target
+	}
+	return schema
+}
+
+// schemaType returns the type of schema other than "null", or "" if it has
+// none.
+func schemaType(schema *Schema) string {
+	switch t := schema.Type.(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// hasType reports whether schema allows values of type name.
+func hasType(schema *Schema, name string) bool {
+	switch t := schema.Type.(type) {
+	case string:
+		return t == name
+	case []interface{}:
+		for _, item := range t {
+			if item == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inEnum reports whether value is one of enum, comparing as text.
+func inEnum(value interface{}, enum []interface{}) bool {
+	text := fmt.Sprint(value)
+	for _, e := range enum {
+		if fmt.Sprint(e) == text {
+			return true
+		}
+	}
+	return false
+}
+
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+var versionSegment = regexp.MustCompile(`^/v[0-9]+`)
+
+// operation returns the operation for method and path, and the path
+// template it matched, such as "/accounts/{account_id}". Literal segments
+// are preferred to parameters. The template is returned without an
+// operation if the path matched but the method did not.
+func (s *Spec) operation(method, path string) (*Operation, string) {
+	candidates := []string{path}
+	if s.basePath != "" {
+		if trimmed, ok := strings.CutPrefix(path, s.basePath); ok {
+			candidates = []string{trimmed}
+		}
+	} else if loc := versionSegment.FindStringIndex(path); loc != nil {
+		candidates = append(candidates, path[loc[1]:])
+	}
+
+	best, bestScore := "", -1
+	for _, candidate := range candidates {
+		segments := strings.Split(strings.Trim(candidate, "/"), "/")
+		for template := range s.Paths {
+			if score := matchTemplate(template, segments); score > bestScore || (score == bestScore && template < best) {
+				best, bestScore = template, score
+			}
+		}
+	}
+	if bestScore < 0 {
+		return nil, ""
+	}
+	return s.Paths[best][strings.ToLower(method)], best
+}
+
+// matchTemplate returns the number of literal segments of template if it
+// matches segments, or -1 if it does not.
+func matchTemplate(template string, segments []string) int {
+	parts := strings.Split(strings.Trim(template, "/"), "/")
+	if len(parts) != len(segments) {
+		return -1
+	}
+	score := 0
+	for i, part := range parts {
+		switch {
+		case strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}"):
+			if segments[i] == "" {
+				return -1
+			}
+		case part == segments[i]:
+			score++
+		default:
+			return -1
+		}
+	}
+	return score
+}
@@ -0,0 +1,288 @@
+package contract_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	openibank "github.com/openibank/sdk-go"
+	"github.com/openibank/sdk-go/contract"
+	"github.com/openibank/sdk-go/fixtures"
+)
+
+// stub answers every request with status and body, and records the path of
+// the last one.
+type stub struct {
+	status int
+	body   []byte
+	path   string
+}
+
+func (s *stub) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	s.path = req.URL.Path
+	if req.URL.RawQuery != "" {
+		s.path += "?" + req.URL.RawQuery
+	}
+	return &http.Response{
+		StatusCode: s.status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(s.body)),
+		Request:    req,
+	}, nil
+}
+
+// newClient returns a client whose requests are checked against the bundled
+// specification and answered by base.
+func newClient(t *testing.T, base http.RoundTripper) (*openibank.Client, *contract.Checker) {
+	t.Helper()
+	data, err := os.ReadFile("testdata/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := contract.Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker := contract.NewChecker(spec, base)
+	client := openibank.NewClient(
+		openibank.WithBaseURL("https://api.openibank.com"),
+		openibank.WithAPIKey("test_key"),
+		openibank.WithHTTPClient(&http.Client{Transport: checker}),
+		openibank.WithMaxRetries(0),
+	)
+	return client, checker
+}
+
+// wrap returns the fixture of kind and name inside a list response under
+// key, or as is if key is empty.
+func wrap(t *testing.T, kind fixtures.Kind, name, key string) []byte {
+	t.Helper()
+	raw := fixtures.Raw(kind, name)
+	if key == "" {
+		return raw
+	}
+	var items json.RawMessage = raw
+	if raw[0] != '[' {
+		items = append(append([]byte("["), raw...), ']')
+	}
+	body, err := json.Marshal(map[string]json.RawMessage{key: items})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+// reads are the requests that return each kind of fixture.
+var reads = map[fixtures.Kind]struct {
+	path string
+	key  string
+	call func(ctx context.Context, c *openibank.Client) error
+}{
+	fixtures.Accounts: {"/v2/accounts/acc_1", "", func(ctx context.Context, c *openibank.Client) error {
+		_, err := c.Accounts.Get(ctx, "acc_1")
+		return err
+	}},
+	fixtures.Balances: {"/v2/accounts/acc_1/balances", "balances", func(ctx context.Context, c *openibank.Client) error {
+		_, err := c.Accounts.GetBalances(ctx, "acc_1")
+		return err
+	}},
+	fixtures.Transactions: {"/v2/accounts/acc_1/transactions/tx_1", "", func(ctx context.Context, c *openibank.Client) error {
+		_, err := c.Transactions.Get(ctx, "acc_1", "tx_1")
+		return err
+	}},
+	fixtures.Payments: {"/v2/payments/pay_1", "", func(ctx context.Context, c *openibank.Client) error {
+		_, err := c.Payments.Get(ctx, "pay_1")
+		return err
+	}},
+	fixtures.Refunds: {"/v2/payments/pay_1/refunds", "refunds", func(ctx context.Context, c *openibank.Client) error {
+		_, err := c.Payments.ListRefunds(ctx, "pay_1")
+		return err
+	}},
+	fixtures.Quotes: {"/v2/payments/quotes", "", func(ctx context.Context, c *openibank.Client) error {
+		scheme := openibank.SchemeSWIFT
+		_, err := c.Payments.GetQuote(ctx, openibank.QuoteParams{
+			Amount:          "100.00",
+			Currency:        "EUR",
+			TargetCurrency:  openibank.String("GBP"),
+			Scheme:          &scheme,
+			DebtorAccountID: openibank.String("acc_1"),
+		})
+		return err
+	}},
+	fixtures.PaymentLimits: {"/v2/payments/limits?debtor_account_id=acc_1", "", func(ctx context.Context, c *openibank.Client) error {
+		_, err := c.Payments.GetLimits(ctx, "acc_1")
+		return err
+	}},
+	fixtures.BulkPayments: {"/v2/payments/bulk/blk_1", "", func(ctx context.Context, c *openibank.Client) error {
+		_, err := c.Payments.GetBulk(ctx, "blk_1")
+		return err
+	}},
+	fixtures.Consents: {"/v2/consents/con_1", "", func(ctx context.Context, c *openibank.Client) error {
+		_, err := c.Consents.Get(ctx, "con_1")
+		return err
+	}},
+	fixtures.ConsentHistory: {"/v2/consents/con_1/history", "history", func(ctx context.Context, c *openibank.Client) error {
+		_, err := c.Consents.GetHistory(ctx, "con_1")
+		return err
+	}},
+	fixtures.ConsentUsages: {"/v2/consents/con_1/usage", "", func(ctx context.Context, c *openibank.Client) error {
+		_, err := c.Consents.GetUsage(ctx, "con_1")
+		return err
+	}},
+	fixtures.Institutions: {"/v2/institutions/inst_1", "", func(ctx context.Context, c *openibank.Client) error {
+		_, err := c.Institutions.Get(ctx, "inst_1")
+		return err
+	}},
+	fixtures.WebhookEndpoints: {"/v2/webhooks/wh_1", "", func(ctx context.Context, c *openibank.Client) error {
+		_, err := c.Webhooks.Get(ctx, "wh_1")
+		return err
+	}},
+	fixtures.WebhookDeliveries: {"/v2/webhooks/wh_1/deliveries/whd_1", "", func(ctx context.Context, c *openibank.Client) error {
+		_, err := c.Webhooks.GetDelivery(ctx, "wh_1", "whd_1")
+		return err
+	}},
+}
+
+func TestFixturesMatchSpec(t *testing.T) {
+	for _, kind := range fixtures.Kinds() {
+		read, ok := reads[kind]
+		if !ok {
+			t.Errorf("no request returns %s fixtures", kind)
+			continue
+		}
+		for _, name := range fixtures.Names(kind) {
+			t.Run(string(kind)+"/"+name, func(t *testing.T) {
+				base := &stub{status: http.StatusOK, body: wrap(t, kind, name, read.key)}
+				client, checker := newClient(t, base)
+				if err := read.call(context.Background(), client); err != nil {
+					t.Fatal(err)
+				}
+				if base.path != read.path {
+					t.Errorf("request sent to %s, want %s", base.path, read.path)
+				}
+				for _, v := range checker.Violations() {
+					t.Error(v)
+				}
+			})
+		}
+	}
+}
+
+func TestRequestsMatchSpec(t *testing.T) {
+	start := time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC)
+	executionDate := openibank.DateOf(start)
+	scheme := openibank.SchemeSEPAInstant
+	consentType := openibank.ConsentTypeAccountInformation
+	tests := []struct {
+		name   string
+		status int
+		body   []byte
+		call   func(ctx context.Context, c *openibank.Client) error
+	}{
+		{"create payment", http.StatusCreated, fixtures.Raw(fixtures.Payments, "received_sepa"), func(ctx context.Context, c *openibank.Client) error {
+			_, err := c.Payments.Create(ctx, openibank.PaymentCreateParams{
+				Creditor: openibank.Creditor{
+					Name:    "Max Mustermann",
+					Account: openibank.CreditorAccount{IBAN: openibank.String("DE89370400440532013000"), BIC: openibank.String("COBADEFFXXX")},
+					Address: &openibank.Address{TownName: "Berlin", PostCode: openibank.String("10115"), Country: "DE"},
+				},
+				Amount:              openibank.Amount{Amount: "150.00", Currency: "EUR"},
+				DebtorAccountID:     "acc_1",
+				Reference:           openibank.String("Invoice 2024-001"),
+				EndToEndID:          openibank.String("E2E-20240315-0001"),
+				ExecutionDate:       &executionDate,
+				Scheme:              &scheme,
+				AllowSchemeFallback: openibank.Bool(true),
+				QuoteID:             openibank.String("quo_1"),
+				Schedule:            &openibank.Schedule{Frequency: openibank.FrequencyMonthly, StartDate: start, DayOfMonth: openibank.Int(-1)},
+			})
+			return err
+		}},
+		{"create UK payment", http.StatusCreated, fixtures.Raw(fixtures.Payments, "received_sepa"), func(ctx context.Context, c *openibank.Client) error {
+			_, err := c.Payments.Create(ctx, openibank.PaymentCreateParams{
+				Creditor: openibank.Creditor{
+					Name:    "John Smith",
+					Account: openibank.CreditorAccount{SortCode: openibank.String("200000"), AccountNumber: openibank.String("55779911")},
+				},
+				Amount:          openibank.Amount{Amount: "20.00", Currency: "GBP"},
+				DebtorAccountID: "acc_2",
+			})
+			return err
+		}},
+		{"refund payment", http.StatusCreated, fixtures.Raw(fixtures.Refunds, "partial"), func(ctx context.Context, c *openibank.Client) error {
+			_, err := c.Payments.Refund(ctx, "pay_1", openibank.RefundParams{
+				Amount: &openibank.Amount{Amount: "10.00", Currency: "EUR"},
+				Reason: openibank.String("Damaged item"),
+			})
+			return err
+		}},
+		{"create consent", http.StatusCreated, fixtures.Raw(fixtures.Consents, "received_all_accounts"), func(ctx context.Context, c *openibank.Client) error {
+			_, err := c.Consents.Create(ctx, openibank.ConsentCreateParams{
+				Type:               &consentType,
+				Access:             openibank.ConsentAccessFromScopes([]string{"accounts", "balances", "transactions"}),
+				ValidUntil:         openibank.String("2030-06-30"),
+				RecurringIndicator: openibank.Bool(true),
+				FrequencyPerDay:    openibank.Int(4),
+				PSUID:              openibank.String("psu_1"),
+			})
+			return err
+		}},
+		{"create webhook", http.StatusCreated, fixtures.Raw(fixtures.WebhookEndpoints, "all_events"), func(ctx context.Context, c *openibank.Client) error {
+			_, err := c.Webhooks.Create(ctx, openibank.WebhookCreateParams{
+				URL:         "https://example.com/webhooks/openibank",
+				Events:      []openibank.EventType{openibank.EventTransactionCreated, openibank.EventPaymentStatusChanged},
+				Description: openibank.String("Production receiver"),
+			})
+			return err
+		}},
+		{"list transactions", http.StatusOK, wrap(t, fixtures.Transactions, "booked_sepa_credit", "transactions"), func(ctx context.Context, c *openibank.Client) error {
+			_, err := c.Transactions.List(ctx, "acc_1", &openibank.TransactionListParams{
+				UpdatedSince:  &start,
+				DateFrom:      &start,
+				DateTo:        &start,
+				AmountMin:     openibank.Float64(10),
+				AmountMax:     openibank.Float64(100),
+				BookingStatus: openibank.String("booked"),
+				Limit:         openibank.Int(50),
+				Offset:        openibank.Int(100),
+			})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, checker := newClient(t, &stub{status: tt.status, body: tt.body})
+			if err := tt.call(context.Background(), client); err != nil {
+				t.Fatal(err)
+			}
+			for _, v := range checker.Violations() {
+				t.Error(v)
+			}
+		})
+	}
+}
+
+func TestCheckerReportsDrift(t *testing.T) {
+	body := []byte(`{"status":"SETTLED","amount":150,"currency":"EUR","creditor_name":"Max Mustermann"}`)
+	client, checker := newClient(t, &stub{status: http.StatusOK, body: body})
+	client.Payments.Get(context.Background(), "pay_1")
+
+	want := map[string]bool{"id": true, "status": true, "amount": true}
+	for _, v := range checker.Violations() {
+		if v.Location != "response" || !want[v.Field] {
+			t.Errorf("unexpected violation: %v", v)
+		}
+		delete(want, v.Field)
+	}
+	for field := range want {
+		t.Errorf("no violation reported for %s", field)
+	}
+}
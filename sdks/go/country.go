@@ -0,0 +1,95 @@
+package openibank
+
+import "strings"
+
+// Country is an ISO 3166-1 alpha-2 country code.
+type Country string
+
+// Countries where OpeniBank connects to institutions.
+const (
+	CountryAustria       Country = "AT"
+	CountryBelgium       Country = "BE"
+	CountryBulgaria      Country = "BG"
+	CountryCroatia       Country = "HR"
+	CountryCyprus        Country = "CY"
+	CountryCzechRepublic Country = "CZ"
+	CountryDenmark       Country = "DK"
+	CountryEstonia       Country = "EE"
+	CountryFinland       Country = "FI"
+	CountryFrance        Country = "FR"
+	CountryGermany       Country = "DE"
+	CountryGreece        Country = "GR"
+	CountryHungary       Country = "HU"
+	CountryIceland       Country = "IS"
+	CountryIreland       Country = "IE"
+	CountryItaly         Country = "IT"
+	CountryLatvia        Country = "LV"
+	CountryLiechtenstein Country = "LI"
+	CountryLithuania     Country = "LT"
+	CountryLuxembourg    Country = "LU"
+	CountryMalta         Country = "MT"
+	CountryNetherlands   Country = "NL"
+	CountryNorway        Country = "NO"
+	CountryPoland        Country = "PL"
+	CountryPortugal      Country = "PT"
+	CountryRomania       Country = "RO"
+	CountrySlovakia      Country = "SK"
+	CountrySlovenia      Country = "SI"
+	CountrySpain         Country = "ES"
+	CountrySweden        Country = "SE"
+	CountrySwitzerland   Country = "CH"
+	CountryUnitedKingdom Country = "GB"
+)
+
+// Valid reports whether c is an assigned ISO 3166-1 alpha-2 code.
+func (c Country) Valid() bool {
+	_, ok := iso3166[c]
+	return ok
+}
+
+// ParseCountry parses a country code case-insensitively and reports whether
+// it is an assigned ISO 3166-1 alpha-2 code.
+func ParseCountry(code string) (Country, bool) {
+	c := Country(strings.ToUpper(strings.TrimSpace(code)))
+	return c, c.Valid()
+}
+
+// iso3166 lists the assigned ISO 3166-1 alpha-2 codes.
+var iso3166 = map[Country]struct{}{
+	"AD": {}, "AE": {}, "AF": {}, "AG": {}, "AI": {}, "AL": {}, "AM": {},
+	"AO": {}, "AQ": {}, "AR": {}, "AS": {}, "AT": {}, "AU": {}, "AW": {},
+	"AX": {}, "AZ": {}, "BA": {}, "BB": {}, "BD": {}, "BE": {}, "BF": {},
+	"BG": {}, "BH": {}, "BI": {}, "BJ": {}, "BL": {}, "BM": {}, "BN": {},
+	"BO": {}, "BQ": {}, "BR": {}, "BS": {}, "BT": {}, "BV": {}, "BW": {},
+	"BY": {}, "BZ": {}, "CA": {}, "CC": {}, "CD": {}, "CF": {}, "CG": {},
+	"CH": {}, "CI": {}, "CK": {}, "CL": {}, "CM": {}, "CN": {}, "CO": {},
+	"CR": {}, "CU": {}, "CV": {}, "CW": {}, "CX": {}, "CY": {}, "CZ": {},
+	"DE": {}, "DJ": {}, "DK": {}, "DM": {}, "DO": {}, "DZ": {}, "EC": {},
+	"EE": {}, "EG": {}, "EH": {}, "ER": {}, "ES": {}, "ET": {}, "FI": {},
+	"FJ": {}, "FK": {}, "FM": {}, "FO": {}, "FR": {}, "GA": {}, "GB": {},
+	"GD": {}, "GE": {}, "GF": {}, "GG": {}, "GH": {}, "GI": {}, "GL": {},
+	"GM": {}, "GN": {}, "GP": {}, "GQ": {}, "GR": {}, "GS": {}, "GT": {},
+	"GU": {}, "GW": {}, "GY": {}, "HK": {}, "HM": {}, "HN": {}, "HR": {},
+	"HT": {}, "HU": {}, "ID": {}, "IE": {}, "IL": {}, "IM": {}, "IN": {},
+	"IO": {}, "IQ": {}, "IR": {}, "IS": {}, "IT": {}, "JE": {}, "JM": {},
+	"JO": {}, "JP": {}, "KE": {}, "KG": {}, "KH": {}, "KI": {}, "KM": {},
+	"KN": {}, "KP": {}, "KR": {}, "KW": {}, "KY": {}, "KZ": {}, "LA": {},
+	"LB": {}, "LC": {}, "LI": {}, "LK": {}, "LR": {}, "LS": {}, "LT": {},
+	"LU": {}, "LV": {}, "LY": {}, "MA": {}, "MC": {}, "MD": {}, "ME": {},
+	"MF": {}, "MG": {}, "MH": {}, "MK": {}, "ML": {}, "MM": {}, "MN": {},
+	"MO": {}, "MP": {}, "MQ": {}, "MR": {}, "MS": {}, "MT": {}, "MU": {},
+	"MV": {}, "MW": {}, "MX": {}, "MY": {}, "MZ": {}, "NA": {}, "NC": {},
+	"NE": {}, "NF": {}, "NG": {}, "NI": {}, "NL": {}, "NO": {}, "NP": {},
+	"NR": {}, "NU": {}, "NZ": {}, "OM": {}, "PA": {}, "PE": {}, "PF": {},
+	"PG": {}, "PH": {}, "PK": {}, "PL": {}, "PM": {}, "PN": {}, "PR": {},
+	"PS": {}, "PT": {}, "PW": {}, "PY": {}, "QA": {}, "RE": {}, "RO": {},
+	"RS": {}, "RU": {}, "RW": {}, "SA": {}, "SB": {}, "SC": {}, "SD": {},
+	"SE": {}, "SG": {}, "SH": {}, "SI": {}, "SJ": {}, "SK": {}, "SL": {},
+	"SM": {}, "SN": {}, "SO": {}, "SR": {}, "SS": {}, "ST": {}, "SV": {},
+	"SX": {}, "SY": {}, "SZ": {}, "TC": {}, "TD": {}, "TF": {}, "TG": {},
+	"TH": {}, "TJ": {}, "TK": {}, "TL": {}, "TM": {}, "TN": {}, "TO": {},
+	"TR": {}, "TT": {}, "TV": {}, "TW": {}, "TZ": {}, "UA": {}, "UG": {},
+	"UM": {}, "US": {}, "UY": {}, "UZ": {}, "VA": {}, "VC": {}, "VE": {},
+	"VG": {}, "VI": {}, "VN": {}, "VU": {}, "WF": {}, "WS": {}, "YE": {},
+	"YT": {}, "ZA": {}, "ZM": {}, "ZW": {},
+}
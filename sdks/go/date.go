@@ -0,0 +1,101 @@
+package openibank
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// dateLayout is the layout of dates in the API.
+const dateLayout = "2006-01-02"
+
+// Date is a calendar date without a time of day or time zone, such as a
+// booking or execution date. Unlike a time.Time at midnight, it does not
+// shift to the previous or next day when converted between time zones. In
+// JSON a Date is a "2006-01-02" string.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the date of t in t's location.
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// Today returns the current date in loc, by the system clock. Use DateOf
+// with the time of a Clock to follow it instead.
+func Today(loc *time.Location) Date {
+	return DateOf(time.Now().In(loc))
+}
+
+// ParseDate parses a date in the form "2006-01-02".
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q: %w", s, err)
+	}
+	return DateOf(t), nil
+}
+
+// String returns the date in the form "2006-01-02".
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// IsZero reports whether d is the zero Date.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// In returns the time of midnight at the start of the date in loc.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// AddDays returns the date n days after d, or before it if n is negative.
+func (d Date) AddDays(n int) Date {
+	return DateOf(d.In(time.UTC).AddDate(0, 0, n))
+}
+
+// Before reports whether d is before other.
+func (d Date) Before(other Date) bool {
+	return d.In(time.UTC).Before(other.In(time.UTC))
+}
+
+// After reports whether d is after other.
+func (d Date) After(other Date) bool {
+	return other.Before(d)
+}
+
+// MarshalText encodes the date as "2006-01-02".
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes a "2006-01-02" date. An RFC 3339 timestamp is also
+// accepted, and its date is taken as written, without converting time zones.
+func (d *Date) UnmarshalText(data []byte) error {
+	if len(data) > len(dateLayout) {
+		data = data[:len(dateLayout)]
+	}
+	parsed, err := ParseDate(string(data))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// UnmarshalJSON decodes a date string, leaving d unchanged for null.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("invalid date %s", data)
+	}
+	return d.UnmarshalText(data[1 : len(data)-1])
+}
@@ -0,0 +1,195 @@
+package openibank
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// WithStrictDecoding makes responses with fields the SDK does not model fail
+// with an *UnknownFieldsError, so that API changes are noticed during
+// development. Leave it off in production, where new fields should not
+// break the client.
+func WithStrictDecoding(strict bool) Option {
+	return func(c *Config) {
+		c.StrictDecoding = strict
+	}
+}
+
+// UnknownFieldsError is returned in strict decoding mode for a response
+// with fields the SDK does not model. Fields are JSON paths such as
+// "accounts[0].nickname".
+type UnknownFieldsError struct {
+	Fields []string
+}
+
+func (e *UnknownFieldsError) Error() string {
+	return "response has unknown fields: " + strings.Join(e.Fields, ", ")
+}
+
+// checkUnknownFields returns an *UnknownFieldsError if data has fields that
+// decoding into v would ignore.
+func checkUnknownFields(data []byte, v interface{}) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+	var fields []string
+	collectUnknownFields(value, reflect.TypeOf(v), "", &fields)
+	if len(fields) == 0 {
+		return nil
+	}
+	sort.Strings(fields)
+	return &UnknownFieldsError{Fields: fields}
+}
+
+var (
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	rawModelType    = reflect.TypeOf((*interface{ Raw() json.RawMessage })(nil)).Elem()
+)
+
+// collectUnknownFields walks a decoded JSON value alongside the type it is
+// decoded into. Types with their own decoding are not walked, except models
+// that only retain their raw JSON.
+func collectUnknownFields(value interface{}, t reflect.Type, path string, fields *[]string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	ptr := reflect.PointerTo(t)
+	if ptr.Implements(unmarshalerType) && !ptr.Implements(rawModelType) {
+		return
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			known := structFields(t)
+			for key, item := range v {
+				fieldPath := key
+				if path != "" {
+					fieldPath = path + "." + key
+				}
+				field, ok := known[strings.ToLower(key)]
+				if !ok {
+					*fields = append(*fields, fieldPath)
+					continue
+				}
+				collectUnknownFields(item, field, fieldPath, fields)
+			}
+		case reflect.Map:
+			for key, item := range v {
+				collectUnknownFields(item, t.Elem(), path+"."+key, fields)
+			}
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, item := range v {
+				collectUnknownFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), fields)
+			}
+		}
+	}
+}
+
+// structFields returns the types of the fields of a struct by lower-cased
+// JSON name, as encoding/json matches names case-insensitively.
+func structFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			for name, ft := range structFields(f.Type) {
+				fields[name] = ft
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = f.Type
+	}
+	return fields
+}
+
+// rawJSON is embedded in models to retain the JSON they were decoded from.
+type rawJSON struct {
+	raw json.RawMessage
+}
+
+// Raw returns the JSON the model was decoded from, including fields the SDK
+// does not model yet, or nil if it was not decoded from JSON.
+func (r rawJSON) Raw() json.RawMessage {
+	return r.raw
+}
+
+func (r *rawJSON) setRaw(data []byte) {
+	r.raw = append(json.RawMessage(nil), data...)
+}
+
+// UnmarshalJSON decodes the account and retains its JSON.
+func (a *Account) UnmarshalJSON(data []byte) error {
+	type account Account
+	if err := json.Unmarshal(data, (*account)(a)); err != nil {
+		return err
+	}
+	a.setRaw(data)
+	return nil
+}
+
+// UnmarshalJSON decodes the balance and retains its JSON.
+func (b *Balance) UnmarshalJSON(data []byte) error {
+	type balance Balance
+	if err := json.Unmarshal(data, (*balance)(b)); err != nil {
+		return err
+	}
+	b.setRaw(data)
+	return nil
+}
+
+// UnmarshalJSON decodes the transaction and retains its JSON.
+func (t *Transaction) UnmarshalJSON(data []byte) error {
+	type transaction Transaction
+	if err := json.Unmarshal(data, (*transaction)(t)); err != nil {
+		return err
+	}
+	t.setRaw(data)
+	return nil
+}
+
+// UnmarshalJSON decodes the payment and retains its JSON.
+func (p *Payment) UnmarshalJSON(data []byte) error {
+	type payment Payment
+	if err := json.Unmarshal(data, (*payment)(p)); err != nil {
+		return err
+	}
+	p.setRaw(data)
+	return nil
+}
+
+// UnmarshalJSON decodes the consent and retains its JSON.
+func (c *Consent) UnmarshalJSON(data []byte) error {
+	type consent Consent
+	if err := json.Unmarshal(data, (*consent)(c)); err != nil {
+		return err
+	}
+	c.setRaw(data)
+	return nil
+}
+
+// UnmarshalJSON decodes the institution and retains its JSON.
+func (i *Institution) UnmarshalJSON(data []byte) error {
+	type institution Institution
+	if err := json.Unmarshal(data, (*institution)(i)); err != nil {
+		return err
+	}
+	i.setRaw(data)
+	return nil
+}
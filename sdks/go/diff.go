@@ -0,0 +1,95 @@
+package openibank
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Change is a field whose value differs between two models. Field is a
+// JSON path such as "status" or "remittance_information.unstructured", and
+// Old and New are the values, or nil where a pointer field is unset.
+type Change struct {
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Field, c.Old, c.New)
+}
+
+// Diff returns the fields whose values differ between a and b, ordered as
+// the fields are declared. Nested structs are compared field by field;
+// slices and maps are compared as a whole, with nil equal to empty. The raw
+// JSON retained by models is ignored, so fields the SDK does not model are
+// not compared.
+func Diff[T any](a, b T) []Change {
+	var changes []Change
+	diffValues(reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem(), "", &changes)
+	return changes
+}
+
+// Equal reports whether t and other have the same values in every field
+// the SDK models.
+func (t Transaction) Equal(other Transaction) bool {
+	return len(Diff(t, other)) == 0
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func diffValues(a, b reflect.Value, path string, changes *[]Change) {
+	switch {
+	case a.Type() == timeType:
+		if !a.Interface().(time.Time).Equal(b.Interface().(time.Time)) {
+			*changes = append(*changes, Change{Field: path, Old: a.Interface(), New: b.Interface()})
+		}
+	case a.Kind() == reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				*changes = append(*changes, Change{Field: path, Old: ptrValue(a), New: ptrValue(b)})
+			}
+			return
+		}
+		diffValues(a.Elem(), b.Elem(), path, changes)
+	case a.Kind() == reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			diffValues(a.Field(i), b.Field(i), name, changes)
+		}
+	case a.Kind() == reflect.Slice || a.Kind() == reflect.Map:
+		if a.Len() == 0 && b.Len() == 0 {
+			return
+		}
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*changes = append(*changes, Change{Field: path, Old: a.Interface(), New: b.Interface()})
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*changes = append(*changes, Change{Field: path, Old: a.Interface(), New: b.Interface()})
+		}
+	}
+}
+
+// ptrValue returns the value v points to, or nil if v is nil.
+func ptrValue(v reflect.Value) interface{} {
+	if v.IsNil() {
+		return nil
+	}
+	return v.Elem().Interface()
+}
@@ -0,0 +1,24 @@
+package openibank
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNewEndToEndID(t *testing.T) {
+	format := regexp.MustCompile(`^[0-9a-f]{32}$`)
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		id := NewEndToEndID()
+		if !format.MatchString(id) {
+			t.Fatalf("NewEndToEndID() = %q, want 32 lowercase hex characters", id)
+		}
+		if len(id) > 35 {
+			t.Fatalf("NewEndToEndID() = %q, longer than the 35 characters SEPA allows", id)
+		}
+		if seen[id] {
+			t.Fatalf("NewEndToEndID() returned %q twice", id)
+		}
+		seen[id] = true
+	}
+}
@@ -0,0 +1,43 @@
+package openibank_test
+
+import (
+	"testing"
+
+	openibank "github.com/openibank/sdk-go"
+)
+
+// enum is implemented by the API's enum types.
+type enum interface {
+	Valid() bool
+	IsKnown() bool
+}
+
+func TestEnumIsKnown(t *testing.T) {
+	tests := []struct {
+		known, unknown enum
+	}{
+		{openibank.AccountStatusActive, openibank.AccountStatus("dormant")},
+		{openibank.TransactionStatusBooked, openibank.TransactionStatus("held")},
+		{openibank.TransactionTypeCredit, openibank.TransactionType("transfer")},
+		{openibank.SchemeSEPA, openibank.Scheme("ACH")},
+		{openibank.PaymentStatusReceived, openibank.PaymentStatus("XXXX")},
+		{openibank.ConsentTypeAccountInformation, openibank.ConsentType("funds_confirmation")},
+		{openibank.ConsentStatusValid, openibank.ConsentStatus("paused")},
+		{openibank.ConsentHistoryAccessed, openibank.ConsentHistoryEventType("renamed")},
+		{openibank.CapabilityAccounts, openibank.Capability("loans")},
+		{openibank.SCARedirect, openibank.SCAApproach("oob")},
+		{openibank.WebhookDeliveryStatusSucceeded, openibank.WebhookDeliveryStatus("dropped")},
+		{openibank.BalanceTypeExpected, openibank.BalanceType("reserved")},
+		{openibank.FrequencyMonthly, openibank.Frequency("fortnightly")},
+		{openibank.EventBalanceUpdated, openibank.EventType("balance.deleted")},
+		{openibank.RevokedByPSU, openibank.RevokedBy("regulator")},
+	}
+	for _, tt := range tests {
+		if !tt.known.IsKnown() || !tt.known.Valid() {
+			t.Errorf("%T(%v): IsKnown = %v, Valid = %v, want true", tt.known, tt.known, tt.known.IsKnown(), tt.known.Valid())
+		}
+		if tt.unknown.IsKnown() || tt.unknown.Valid() {
+			t.Errorf("%T(%v): IsKnown = %v, Valid = %v, want false", tt.unknown, tt.unknown, tt.unknown.IsKnown(), tt.unknown.Valid())
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package openibank
+
+// Error codes set in the Code field of API errors, and of the errors the
+// SDK returns for requests it rejects before sending them.
+const (
+	// Request errors.
+	ErrCodeInvalidRequest       = "invalid_request"
+	ErrCodeInvalidIBAN          = "invalid_iban"
+	ErrCodeInvalidBIC           = "invalid_bic"
+	ErrCodeInvalidAccount       = "invalid_account"
+	ErrCodeInvalidAmount        = "invalid_amount"
+	ErrCodeInvalidCountry       = "invalid_country"
+	ErrCodeInvalidAddress       = "invalid_address"
+	ErrCodeInvalidPayment       = "invalid_payment"
+	ErrCodeInvalidPaymentFile   = "invalid_payment_file"
+	ErrCodeInvalidSchedule      = "invalid_schedule"
+	ErrCodeInvalidConsent       = "invalid_consent"
+	ErrCodeInvalidCallback      = "invalid_callback"
+	ErrCodeInvalidFilter        = "invalid_filter"
+	ErrCodeUnsupportedCurrency  = "unsupported_currency"
+	ErrCodeUnsupportedScheme    = "unsupported_scheme"
+	ErrCodeIdempotencyKeyReused = "idempotency_key_reused"
+
+	// Authentication and authorization errors.
+	ErrCodeInvalidToken      = "invalid_token"
+	ErrCodeTokenExpired      = "token_expired"
+	ErrCodeInsufficientScope = "insufficient_scope"
+
+	// Consent errors.
+	ErrCodeConsentExpired       = "consent_expired"
+	ErrCodeConsentRevoked       = "consent_revoked"
+	ErrCodeConsentNotAuthorized = "consent_not_authorized"
+	ErrCodeSCARequired          = "sca_required"
+	ErrCodeSCAFailed            = "sca_failed"
+
+	// Account and payment errors.
+	ErrCodeInsufficientFunds     = "insufficient_funds"
+	ErrCodeLimitExceeded         = "limit_exceeded"
+	ErrCodeAccountBlocked        = "account_blocked"
+	ErrCodeAccountClosed         = "account_closed"
+	ErrCodeDuplicatePayment      = "duplicate_payment"
+	ErrCodePaymentNotCancellable = "payment_not_cancellable"
+
+	// Other errors.
+	ErrCodeResourceNotFound       = "resource_not_found"
+	ErrCodeRateLimited            = "rate_limited"
+	ErrCodeInstitutionUnavailable = "institution_unavailable"
+	ErrCodeSandboxOnly            = "sandbox_only"
+	ErrCodeInternalError          = "internal_error"
+)
+
+// errorCodeDescriptions describes the documented error codes.
+var errorCodeDescriptions = map[string]string{
+	ErrCodeInvalidRequest:       "The request is malformed or has invalid parameters.",
+	ErrCodeInvalidIBAN:          "An IBAN is malformed or its checksum does not match.",
+	ErrCodeInvalidBIC:           "A BIC is malformed.",
+	ErrCodeInvalidAccount:       "An account number or sort code is invalid.",
+	ErrCodeInvalidAmount:        "An amount is not a valid decimal amount for its currency.",
+	ErrCodeInvalidCountry:       "A country is not an ISO 3166-1 alpha-2 code.",
+	ErrCodeInvalidAddress:       "A postal address is missing fields required in its country.",
+	ErrCodeInvalidPayment:       "A payment has invalid or missing fields.",
+	ErrCodeInvalidPaymentFile:   "A bulk payment file has invalid or missing fields.",
+	ErrCodeInvalidSchedule:      "A payment schedule is invalid.",
+	ErrCodeInvalidConsent:       "A consent request has invalid or missing fields.",
+	ErrCodeInvalidCallback:      "A consent authorization callback is invalid.",
+	ErrCodeInvalidFilter:        "A real-time subscription filter is invalid.",
+	ErrCodeUnsupportedCurrency:  "The currency is not supported for this account or scheme.",
+	ErrCodeUnsupportedScheme:    "The payment scheme is not supported by the institution.",
+	ErrCodeIdempotencyKeyReused: "The idempotency key was used for a request with different parameters.",
+
+	ErrCodeInvalidToken:      "The access token is invalid.",
+	ErrCodeTokenExpired:      "The access token has expired.",
+	ErrCodeInsufficientScope: "The access token lacks the scopes the endpoint requires.",
+
+	ErrCodeConsentExpired:       "The consent has expired; the PSU must give a new one.",
+	ErrCodeConsentRevoked:       "The consent was revoked by the PSU, the TPP or the bank.",
+	ErrCodeConsentNotAuthorized: "The consent has not been authorized by the PSU yet.",
+	ErrCodeSCARequired:          "Strong customer authentication is required.",
+	ErrCodeSCAFailed:            "Strong customer authentication failed.",
+
+	ErrCodeInsufficientFunds:     "The debtor account has insufficient funds.",
+	ErrCodeLimitExceeded:         "The amount exceeds a payment limit of the account.",
+	ErrCodeAccountBlocked:        "The account is blocked.",
+	ErrCodeAccountClosed:         "The account is closed.",
+	ErrCodeDuplicatePayment:      "The payment duplicates one already submitted.",
+	ErrCodePaymentNotCancellable: "The payment can no longer be cancelled.",
+
+	ErrCodeResourceNotFound:       "The requested resource does not exist.",
+	ErrCodeRateLimited:            "Too many requests were made; retry later.",
+	ErrCodeInstitutionUnavailable: "The institution is temporarily unavailable.",
+	ErrCodeSandboxOnly:            "The operation is only available in the sandbox environment.",
+	ErrCodeInternalError:          "An unexpected error occurred on the platform.",
+}
+
+// ErrorCodeDescription returns the description of a documented error code,
+// and whether the code is documented.
+func ErrorCodeDescription(code string) (string, bool) {
+	description, ok := errorCodeDescriptions[code]
+	return description, ok
+}
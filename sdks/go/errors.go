@@ -0,0 +1,200 @@
+package openibank
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// Sentinel errors matched by the typed API errors with errors.Is, so that
+// callers can check the kind of failure without a type switch:
+//
+//	if errors.Is(err, openibank.ErrNotFound) {
+//	    // ...
+//	}
+var (
+	// ErrInvalidRequest is matched by *ValidationError.
+	ErrInvalidRequest = errors.New("openibank: invalid request")
+	// ErrUnauthorized is matched by *AuthenticationError.
+	ErrUnauthorized = errors.New("openibank: unauthorized")
+	// ErrForbidden is matched by *AuthorizationError.
+	ErrForbidden = errors.New("openibank: forbidden")
+	// ErrNotFound is matched by *NotFoundError.
+	ErrNotFound = errors.New("openibank: not found")
+	// ErrConflict is matched by *ConflictError.
+	ErrConflict = errors.New("openibank: conflict")
+	// ErrRateLimited is matched by *RateLimitError.
+	ErrRateLimited = errors.New("openibank: rate limited")
+	// ErrServer is matched by *ServerError.
+	ErrServer = errors.New("openibank: server error")
+	// ErrNetwork is matched by *NetworkError.
+	ErrNetwork = errors.New("openibank: network error")
+)
+
+// APIError is implemented by the errors returned for API error responses.
+// Use errors.As to get the details of any of them:
+//
+//	var apiErr openibank.APIError
+//	if errors.As(err, &apiErr) {
+//	    log.Printf("request %s failed with %d", apiErr.APIRequestID(), apiErr.APIStatusCode())
+//	}
+//
+// The methods are prefixed with API because the error types have fields
+// named StatusCode, Code and RequestID.
+type APIError interface {
+	error
+	// APIStatusCode returns the HTTP status code of the response.
+	APIStatusCode() int
+	// APICode returns the machine-readable error code, if any.
+	APICode() string
+	// APIRequestID returns the ID of the request, for support requests.
+	APIRequestID() string
+}
+
+var (
+	_ APIError = (*Error)(nil)
+	_ APIError = (*AuthenticationError)(nil)
+	_ APIError = (*AuthorizationError)(nil)
+	_ APIError = (*ValidationError)(nil)
+	_ APIError = (*NotFoundError)(nil)
+	_ APIError = (*RateLimitError)(nil)
+	_ APIError = (*ConflictError)(nil)
+	_ APIError = (*ServerError)(nil)
+)
+
+// statusSentinels maps HTTP status codes to sentinel errors for *Error,
+// which is returned for statuses without a dedicated type.
+var statusSentinels = map[int]error{
+	400: ErrInvalidRequest,
+	401: ErrUnauthorized,
+	403: ErrForbidden,
+	404: ErrNotFound,
+	409: ErrConflict,
+	429: ErrRateLimited,
+}
+
+// Is reports whether target is the sentinel for the error's status code.
+func (e *Error) Is(target error) bool {
+	if e.StatusCode >= 500 {
+		return target == ErrServer
+	}
+	sentinel, ok := statusSentinels[e.StatusCode]
+	return ok && target == sentinel
+}
+
+func (e *Error) APIStatusCode() int   { return e.StatusCode }
+func (e *Error) APICode() string      { return e.Code }
+func (e *Error) APIRequestID() string { return e.RequestID }
+
+// Is reports whether target is ErrUnauthorized.
+func (e *AuthenticationError) Is(target error) bool { return target == ErrUnauthorized }
+
+func (e *AuthenticationError) APIStatusCode() int   { return e.StatusCode }
+func (e *AuthenticationError) APICode() string      { return e.Code }
+func (e *AuthenticationError) APIRequestID() string { return e.RequestID }
+
+// Is reports whether target is ErrForbidden.
+func (e *AuthorizationError) Is(target error) bool { return target == ErrForbidden }
+
+func (e *AuthorizationError) APIStatusCode() int   { return e.StatusCode }
+func (e *AuthorizationError) APICode() string      { return e.Code }
+func (e *AuthorizationError) APIRequestID() string { return e.RequestID }
+
+// Is reports whether target is ErrInvalidRequest.
+func (e *ValidationError) Is(target error) bool { return target == ErrInvalidRequest }
+
+func (e *ValidationError) APIStatusCode() int   { return e.StatusCode }
+func (e *ValidationError) APICode() string      { return e.Code }
+func (e *ValidationError) APIRequestID() string { return e.RequestID }
+
+// Is reports whether target is ErrNotFound.
+func (e *NotFoundError) Is(target error) bool { return target == ErrNotFound }
+
+func (e *NotFoundError) APIStatusCode() int   { return e.StatusCode }
+func (e *NotFoundError) APICode() string      { return e.Code }
+func (e *NotFoundError) APIRequestID() string { return e.RequestID }
+
+// Is reports whether target is ErrRateLimited.
+func (e *RateLimitError) Is(target error) bool { return target == ErrRateLimited }
+
+func (e *RateLimitError) APIStatusCode() int   { return e.StatusCode }
+func (e *RateLimitError) APICode() string      { return e.Code }
+func (e *RateLimitError) APIRequestID() string { return e.RequestID }
+
+// Is reports whether target is ErrConflict.
+func (e *ConflictError) Is(target error) bool { return target == ErrConflict }
+
+func (e *ConflictError) APIStatusCode() int   { return e.StatusCode }
+func (e *ConflictError) APICode() string      { return e.Code }
+func (e *ConflictError) APIRequestID() string { return e.RequestID }
+
+// Is reports whether target is ErrServer.
+func (e *ServerError) Is(target error) bool { return target == ErrServer }
+
+func (e *ServerError) APIStatusCode() int   { return e.StatusCode }
+func (e *ServerError) APICode() string      { return e.Code }
+func (e *ServerError) APIRequestID() string { return e.RequestID }
+
+// Is reports whether target is ErrNetwork.
+func (e *NetworkError) Is(target error) bool { return target == ErrNetwork }
+
+// IsRetryable reports whether err, or an error it wraps, is one the client
+// itself retries: network errors, rate limiting and server errors. Callers
+// layering their own retries on top of the client's should retry only
+// these. Cancelled and timed-out contexts are not retryable.
+func IsRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var r interface{ Retryable() bool }
+	return errors.As(err, &r) && r.Retryable()
+}
+
+// retryableStatus reports whether the client retries a response with the
+// given status code.
+func retryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// Retryable reports whether the request may succeed if retried, which
+// depends on its status code.
+func (e *Error) Retryable() bool { return retryableStatus(e.StatusCode) }
+
+// Retryable reports false: the credentials must be fixed first.
+func (e *AuthenticationError) Retryable() bool { return false }
+
+// Retryable reports false: the missing scopes must be granted first.
+func (e *AuthorizationError) Retryable() bool { return false }
+
+// Retryable reports false: the request must be corrected first.
+func (e *ValidationError) Retryable() bool { return false }
+
+// Retryable reports false.
+func (e *NotFoundError) Retryable() bool { return false }
+
+// Retryable reports false: the conflict must be resolved first.
+func (e *ConflictError) Retryable() bool { return false }
+
+// Retryable reports true; retry after RetryAfter.
+func (e *RateLimitError) Retryable() bool { return true }
+
+// Retryable reports true.
+func (e *ServerError) Retryable() bool { return true }
+
+// Retryable reports true.
+func (e *NetworkError) Retryable() bool { return true }
+
+// maxErrorBody is the number of bytes of an error response body kept in the
+// Body field of API errors.
+const maxErrorBody = 4 << 10
+
+// readErrorBody reads the body of an error response. It returns the body
+// for decoding, and the body truncated to maxErrorBody to keep on the error.
+func readErrorBody(r io.Reader) ([]byte, string) {
+	data, _ := io.ReadAll(io.LimitReader(r, 1<<20))
+	if len(data) <= maxErrorBody {
+		return data, string(data)
+	}
+	return data, string(data[:maxErrorBody]) + "...(truncated)"
+}
@@ -0,0 +1,100 @@
+package openibank
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Call is a REST request to the API, as an Executor receives it.
+type Call struct {
+	// Method is the HTTP method, such as "GET".
+	Method string
+	// Path is the path of the endpoint, without the base URL and API
+	// version, such as "/accounts/acc_123".
+	Path string
+	// Query holds the query parameters, if any.
+	Query url.Values
+	// Header holds the request's headers, such as Content-Type, Accept,
+	// Idempotency-Key and Consent-ID. Authentication is left to the
+	// executor.
+	Header http.Header
+	// Body is the encoded request body, or nil.
+	Body []byte
+}
+
+// Response is the API's response to a Call.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Executor sends the client's REST requests and returns the API's
+// responses. The client encodes requests, retries, decodes responses and
+// maps error statuses to error types around it, so an executor only moves
+// calls and responses, such as over HTTP, to a mock or from a recording.
+// An error from Execute is a transport failure, returned as a
+// *NetworkError and retried like one, unless it is an error with a
+// Retryable method that reports false, such as an *AuthenticationError,
+// which is returned as is.
+type Executor interface {
+	Execute(ctx context.Context, call *Call) (*Response, error)
+}
+
+// WithExecutor sets the executor the client sends its REST requests with.
+// The default sends them over HTTP with the client's HTTP client,
+// authenticating them with its credentials. Token requests and real-time
+// connections do not go through the executor.
+func WithExecutor(executor Executor) Option {
+	return func(c *Config) {
+		c.Executor = executor
+	}
+}
+
+// httpExecutor is the default Executor. It sends calls to the client's base
+// URL with its HTTP client and access token.
+type httpExecutor struct {
+	client *Client
+}
+
+func (e *httpExecutor) Execute(ctx context.Context, call *Call) (*Response, error) {
+	c := e.client
+	token, err := c.ensureToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reqURL := fmt.Sprintf("%s/%s%s", c.BaseURL(), c.config.APIVersion, call.Path)
+	if len(call.Query) > 0 {
+		reqURL += "?" + call.Query.Encode()
+	}
+	var body io.Reader
+	if call.Body != nil {
+		body = bytes.NewReader(call.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, call.Method, reqURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range call.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-API-Version", c.config.APIVersion)
+	req.Header.Set("User-Agent", "OpeniBank-Go/"+Version)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: data}, nil
+}
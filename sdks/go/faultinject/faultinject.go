@@ -0,0 +1,237 @@
+// Package faultinject provides an HTTP transport that injects faults into
+// the client's requests: latency, server errors, connection resets and
+// truncated response bodies, at configurable rates. Use it to check that
+// retry and circuit-breaker settings behave as intended when the API or the
+// network fails.
+//
+// Example usage:
+//
+//	transport := &faultinject.Transport{
+//	    Latency:      200 * time.Millisecond,
+//	    Jitter:       100 * time.Millisecond,
+//	    ErrorRate:    0.2,
+//	    ResetRate:    0.05,
+//	    TruncateRate: 0.05,
+//	    Seed:         1,
+//	}
+//	client := openibank.NewClient(
+//	    openibank.WithHTTPClient(&http.Client{Transport: transport}),
+//	    openibank.WithMaxRetries(3),
+//	)
+//	// ... exercise the client ...
+//	log.Printf("%+v", transport.Stats())
+package faultinject
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Transport is an http.RoundTripper that injects faults into the requests
+// it sends with Base. Each request is delayed by Latency and a random part
+// of Jitter, then fails with at most one fault, chosen at random with the
+// configured rates. Rates are fractions of requests, between 0 and 1.
+//
+// The zero value injects no faults. Set the fields before the first request
+// and do not change them after. A Transport is safe for concurrent use.
+type Transport struct {
+	// Base sends the requests. The default is http.DefaultTransport.
+	Base http.RoundTripper
+
+	// Latency is added before each request is sent.
+	Latency time.Duration
+	// Jitter is the upper bound of a random delay added to Latency.
+	Jitter time.Duration
+
+	// ErrorRate is the fraction of requests answered with ErrorStatus
+	// without being sent.
+	ErrorRate float64
+	// ErrorStatus is the status of injected errors. The default is 503
+	// Service Unavailable.
+	ErrorStatus int
+
+	// ResetRate is the fraction of requests that fail with a connection
+	// reset after being sent, as when a connection drops before the
+	// response arrives. The API may have acted on these requests, which
+	// makes them a test of idempotency.
+	ResetRate float64
+
+	// TruncateRate is the fraction of responses whose body ends early,
+	// with io.ErrUnexpectedEOF, at a random point.
+	TruncateRate float64
+
+	// Seed seeds the random choice of faults, so that a run can be
+	// repeated. Zero seeds it from the current time.
+	Seed int64
+
+	mu    sync.Mutex
+	rand  *rand.Rand
+	stats Stats
+}
+
+// Stats counts the requests a Transport handled and the faults it
+// injected.
+type Stats struct {
+	Requests  int
+	Errors    int
+	Resets    int
+	Truncated int
+}
+
+// Stats returns the counts of requests and faults so far.
+func (t *Transport) Stats() Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
+
+// fault is a kind of fault injected into a request.
+type fault int
+
+const (
+	faultNone fault = iota
+	faultError
+	faultReset
+	faultTruncate
+)
+
+// next counts a request and chooses its fault and delay.
+func (t *Transport) next() (fault, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.rand == nil {
+		seed := t.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		t.rand = rand.New(rand.NewSource(seed))
+	}
+	t.stats.Requests++
+
+	delay := t.Latency
+	if t.Jitter > 0 {
+		delay += time.Duration(t.rand.Int63n(int64(t.Jitter)))
+	}
+	f := faultNone
+	switch p := t.rand.Float64(); {
+	case p < t.ErrorRate:
+		f = faultError
+		t.stats.Errors++
+	case p < t.ErrorRate+t.ResetRate:
+		f = faultReset
+		t.stats.Resets++
+	case p < t.ErrorRate+t.ResetRate+t.TruncateRate:
+		f = faultTruncate
+		t.stats.Truncated++
+	}
+	return f, delay
+}
+
+// cut returns a random length shorter than n.
+func (t *Transport) cut(n int) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n <= 0 {
+		return 0
+	}
+	return t.rand.Intn(n)
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f, delay := t.next()
+	if err := wait(req.Context(), delay); err != nil {
+		return nil, err
+	}
+
+	if f == faultError {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return errorResponse(req, t.ErrorStatus), nil
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil || f == faultNone {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if f == faultReset {
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	}
+	resp.Body = &truncatedBody{r: bytes.NewReader(body[:t.cut(len(body))])}
+	return resp, nil
+}
+
+// wait waits for d, and returns ctx's error if ctx is done first.
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// errorResponse returns an error response of the API with status, or 503
+// if status is zero.
+func errorResponse(req *http.Request, status int) *http.Response {
+	if status == 0 {
+		status = http.StatusServiceUnavailable
+	}
+	body := fmt.Sprintf(`{"message":"fault injected: %s","code":"internal_error"}`, strings.ToLower(http.StatusText(status)))
+	return &http.Response{
+		Status:     strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode: status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: http.Header{
+			"Content-Type": {"application/json"},
+		},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// truncatedBody is a response body that fails with io.ErrUnexpectedEOF
+// after its content.
+type truncatedBody struct {
+	r *bytes.Reader
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (b *truncatedBody) Close() error { return nil }
@@ -0,0 +1,253 @@
+package fixtures
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	openibank "github.com/openibank/sdk-go"
+)
+
+// Version is the API version the fixtures are rendered in.
+const Version = "v2"
+
+// Difference is a breaking difference between a fixture and its snapshot
+// in another API version, found by CheckCompatibility. Field is the JSON
+// path of the value, such as "creditor.account.iban", or empty.
+type Difference struct {
+	Version string
+	Kind    Kind
+	Name    string
+	Field   string
+	Message string
+}
+
+func (d Difference) Error() string {
+	where := fmt.Sprintf("%s %s/%s", d.Version, d.Kind, d.Name)
+	if d.Field != "" {
+		where += " " + d.Field
+	}
+	return where + ": " + d.Message
+}
+
+// CheckCompatibility decodes payload snapshots rendered in another API
+// version, such as "v3-preview", into the same models as the fixtures, and
+// returns an error for each breaking difference. snapshots holds the JSON of
+// one model per file, laid out as the fixtures are, as <kind>/<name>.json;
+// capture them from the sandbox with the version pinned, for the resources
+// the fixtures show. A snapshot is compatible if it decodes, if the model
+// keeps all of its fields and knows its enum values, and if every field set
+// by the fixture of the same kind and name is also set by the snapshot.
+// Snapshots of unknown kinds are ignored.
+//
+//	err := fixtures.CheckCompatibility("v3-preview", os.DirFS("testdata/v3-preview"))
+func CheckCompatibility(version string, snapshots fs.FS) error {
+	var errs []error
+	for _, kind := range Kinds() {
+		entries, err := fs.ReadDir(snapshots, string(kind))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("fixtures: cannot read %s snapshots: %w", kind, err)
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutSuffix(entry.Name(), ".json")
+			if !ok || entry.IsDir() {
+				continue
+			}
+			raw, err := fs.ReadFile(snapshots, path.Join(string(kind), entry.Name()))
+			if err != nil {
+				return fmt.Errorf("fixtures: cannot read %s snapshot %s: %w", kind, name, err)
+			}
+			for _, d := range compare(version, kind, name, raw) {
+				errs = append(errs, d)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// compare returns the breaking differences of a snapshot.
+func compare(version string, kind Kind, name string, raw []byte) []Difference {
+	var diffs []Difference
+	report := func(field, message string) {
+		diffs = append(diffs, Difference{Version: version, Kind: kind, Name: name, Field: field, Message: message})
+	}
+
+	model := models[kind]()
+	if err := openibank.DecodeJSON(raw, model); err != nil {
+		report("", "does not decode: "+err.Error())
+		return diffs
+	}
+	got, err := encodedFields(model)
+	if err != nil {
+		report("", err.Error())
+		return diffs
+	}
+
+	var snapshot interface{}
+	if err := decodeValue(raw, &snapshot); err != nil {
+		report("", err.Error())
+		return diffs
+	}
+	for _, field := range sortedFields(fields(snapshot, "")) {
+		if !got[field] {
+			report(field, "is not in the model")
+		}
+	}
+	checkEnums(reflect.ValueOf(model), "", report)
+
+	if !hasFixture(kind, name) {
+		return diffs
+	}
+	fixture := models[kind]()
+	if err := openibank.DecodeJSON(Raw(kind, name), fixture); err != nil {
+		report("", "fixture does not decode: "+err.Error())
+		return diffs
+	}
+	want, err := encodedFields(fixture)
+	if err != nil {
+		report("", err.Error())
+		return diffs
+	}
+	for _, field := range sortedFields(want) {
+		if !got[field] && !inArray(field) {
+			report(field, "is set in "+Version+" but not in "+version)
+		}
+	}
+	return diffs
+}
+
+// hasFixture reports whether a fixture of kind and name exists.
+func hasFixture(kind Kind, name string) bool {
+	_, err := data.ReadFile(path.Join("data", string(kind), name+".json"))
+	return err == nil
+}
+
+// inArray reports whether field is an element of an array, or inside one,
+// whose length may differ between a fixture and a snapshot.
+func inArray(field string) bool {
+	return strings.Contains(field, "[")
+}
+
+// encodedFields encodes v and returns the JSON paths of its fields.
+func encodedFields(v interface{}) (map[string]bool, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+	var value interface{}
+	if err := decodeValue(encoded, &value); err != nil {
+		return nil, err
+	}
+	return fields(value, ""), nil
+}
+
+// fields returns the JSON paths of the non-null values in value, objects
+// and arrays included.
+func fields(value interface{}, prefix string) map[string]bool {
+	paths := map[string]bool{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if item == nil {
+				continue
+			}
+			field := key
+			if prefix != "" {
+				field = prefix + "." + key
+			}
+			paths[field] = true
+			for p := range fields(item, field) {
+				paths[p] = true
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if item == nil {
+				continue
+			}
+			field := prefix + "[" + strconv.Itoa(i) + "]"
+			paths[field] = true
+			for p := range fields(item, field) {
+				paths[p] = true
+			}
+		}
+	}
+	return paths
+}
+
+func sortedFields(paths map[string]bool) []string {
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// validator is implemented by the SDK's enum types.
+type validator interface {
+	Valid() bool
+}
+
+var validatorType = reflect.TypeOf((*validator)(nil)).Elem()
+
+// checkEnums reports the enum values in v that the SDK does not know, such
+// as a status added in a new API version.
+func checkEnums(v reflect.Value, field string, report func(field, message string)) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			checkEnums(v.Elem(), field, report)
+		}
+		return
+	}
+	if v.Kind() == reflect.String && v.Type().Implements(validatorType) {
+		if v.String() != "" && !v.Interface().(validator).Valid() {
+			report(field, fmt.Sprintf("value %q is not a known %s", v.String(), v.Type().Name()))
+		}
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			if f.Anonymous && f.Tag.Get("json") == "" {
+				checkEnums(v.Field(i), field, report)
+				continue
+			}
+			if field != "" {
+				name = field + "." + name
+			}
+			checkEnums(v.Field(i), name, report)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			checkEnums(v.Index(i), field+"["+strconv.Itoa(i)+"]", report)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			checkEnums(iter.Value(), field+"."+fmt.Sprint(iter.Key().Interface()), report)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package fixtures
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	for _, version := range []string{"v2", "v3-preview"} {
+		t.Run(version, func(t *testing.T) {
+			snapshots := os.DirFS("testdata/" + version)
+			if matches, _ := fs.Glob(snapshots, "*/*.json"); len(matches) == 0 {
+				t.Fatalf("no snapshots in testdata/%s", version)
+			}
+			if err := CheckCompatibility(version, snapshots); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCheckCompatibilityReportsBreakingChanges(t *testing.T) {
+	snapshots := fstest.MapFS{
+		"payments/received_sepa.json": {Data: []byte(`{
+			"id": "pay_7h6g5f4e",
+			"status": "SETTLED",
+			"amount": "150.00",
+			"currency": "EUR",
+			"creditor_name": "Max Mustermann",
+			"creditor_account": {"iban": "DE89370400440532013000"},
+			"scheme": "sepa",
+			"created_at": "2024-06-03T10:30:00Z"
+		}`)},
+		"accounts/broken.json":  {Data: []byte(`{"id": 42}`)},
+		"unknown_kind/any.json": {Data: []byte(`{}`)},
+	}
+	err := CheckCompatibility("v3-preview", snapshots)
+	if err == nil {
+		t.Fatal("CheckCompatibility = nil, want differences")
+	}
+
+	want := map[string]bool{
+		"accounts/broken ":                             true,
+		"payments/received_sepa status":                true,
+		"payments/received_sepa creditor_account":      true,
+		"payments/received_sepa creditor_account.iban": true,
+		"payments/received_sepa creditor_iban":         true,
+		"payments/received_sepa reference":             true,
+		"payments/received_sepa end_to_end_id":         true,
+		"payments/received_sepa requested_scheme":      true,
+		"payments/received_sepa execution_date":        true,
+	}
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var d Difference
+		if !errors.As(e, &d) || d.Version != "v3-preview" {
+			t.Errorf("unexpected error: %v", e)
+			continue
+		}
+		key := string(d.Kind) + "/" + d.Name + " " + d.Field
+		if !want[key] {
+			t.Errorf("unexpected difference: %v", d)
+		}
+		delete(want, key)
+	}
+	for key := range want {
+		t.Errorf("no difference reported for %s", key)
+	}
+}
@@ -0,0 +1,54 @@
+package fixtures
+
+import (
+	"testing"
+
+	openibank "github.com/openibank/sdk-go"
+)
+
+func TestRoundTrip(t *testing.T) {
+	for _, kind := range Kinds() {
+		names := Names(kind)
+		if len(names) == 0 {
+			t.Errorf("%s: no fixtures", kind)
+		}
+		for _, name := range names {
+			t.Run(string(kind)+"/"+name, func(t *testing.T) {
+				if err := roundTrip(kind, name); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+func TestCheckRoundTrip(t *testing.T) {
+	if err := CheckRoundTrip(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	tx := Transaction("pending_card")
+	if tx.ID != "tx_card_001" || tx.AccountID != "acc_9f8e7d6c" {
+		t.Errorf("ID, AccountID = %q, %q", tx.ID, tx.AccountID)
+	}
+	if tx.Amount != "-4.20" || tx.Currency != "EUR" {
+		t.Errorf("Amount, Currency = %q, %q", tx.Amount, tx.Currency)
+	}
+	if tx.Status != openibank.TransactionStatusPending {
+		t.Errorf("Status = %q, want %q", tx.Status, openibank.TransactionStatusPending)
+	}
+	if mcc, ok := tx.MetaString("mcc"); !ok || mcc != "5814" {
+		t.Errorf(`MetaString("mcc") = %q, %v`, mcc, ok)
+	}
+}
+
+func TestUnknownFixturePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Raw did not panic for an unknown fixture")
+		}
+	}()
+	Raw(Transactions, "no_such_fixture")
+}
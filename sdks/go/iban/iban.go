@@ -0,0 +1,169 @@
+// Package iban parses and validates International Bank Account Numbers.
+//
+// Example usage:
+//
+//	number, err := iban.Parse("DE89 3704 0044 0532 0130 00")
+//	if err != nil {
+//	    log.Fatal(err) // for example iban.ErrInvalidChecksum
+//	}
+//	fmt.Println(number.Country(), number.BBAN()) // DE 370400440532013000
+//	fmt.Println(number.Format())                 // DE89 3704 0044 0532 0130 00
+package iban
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidFormat is returned for an IBAN that does not start with a
+	// 2-letter country code and 2 check digits.
+	ErrInvalidFormat = errors.New("iban: must start with a 2-letter country code and 2 check digits")
+	// ErrInvalidCharacters is returned for an IBAN that contains characters
+	// other than letters and digits, after spaces are removed.
+	ErrInvalidCharacters = errors.New("iban: must contain only letters and digits")
+	// ErrUnknownCountry is returned for an IBAN whose country does not use
+	// IBANs.
+	ErrUnknownCountry = errors.New("iban: unknown country code")
+	// ErrInvalidLength is returned for an IBAN whose length does not match
+	// its country.
+	ErrInvalidLength = errors.New("iban: invalid length for country")
+	// ErrInvalidChecksum is returned for an IBAN whose check digits do not
+	// match.
+	ErrInvalidChecksum = errors.New("iban: invalid checksum")
+)
+
+// IBAN is a validated IBAN in electronic format: upper case, without spaces.
+type IBAN string
+
+// Normalize returns s in electronic format, with spaces and dashes removed
+// and letters in upper case. It does not validate s.
+func Normalize(s string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(s))
+}
+
+// Parse normalizes and validates s.
+func Parse(s string) (IBAN, error) {
+	normalized := Normalize(s)
+	if err := validate(normalized); err != nil {
+		return "", err
+	}
+	return IBAN(normalized), nil
+}
+
+// Validate reports whether s, once normalized, is a valid IBAN: made of
+// letters and digits, of the length used by its country, and with matching
+// mod-97 check digits.
+func Validate(s string) error {
+	return validate(Normalize(s))
+}
+
+// Country returns the ISO 3166-1 alpha-2 country code of the IBAN. Like
+// CheckDigits and BBAN, it returns "" for an IBAN too short to hold its
+// part, such as one converted from a string without Parse.
+func (i IBAN) Country() string {
+	if len(i) < 2 {
+		return ""
+	}
+	return string(i[:2])
+}
+
+// CheckDigits returns the two check digits of the IBAN.
+func (i IBAN) CheckDigits() string {
+	if len(i) < 4 {
+		return ""
+	}
+	return string(i[2:4])
+}
+
+// BBAN returns the Basic Bank Account Number, the country-specific part of
+// the IBAN that identifies the bank and account.
+func (i IBAN) BBAN() string {
+	if len(i) < 4 {
+		return ""
+	}
+	return string(i[4:])
+}
+
+// String returns the IBAN in electronic format.
+func (i IBAN) String() string {
+	return string(i)
+}
+
+// Format returns the IBAN in print format, in groups of four characters
+// separated by spaces.
+func (i IBAN) Format() string {
+	var b strings.Builder
+	for n := 0; n < len(i); n += 4 {
+		if n > 0 {
+			b.WriteByte(' ')
+		}
+		end := n + 4
+		if end > len(i) {
+			end = len(i)
+		}
+		b.WriteString(string(i[n:end]))
+	}
+	return b.String()
+}
+
+func validate(iban string) error {
+	if len(iban) < 4 {
+		return ErrInvalidFormat
+	}
+	for n := 0; n < len(iban); n++ {
+		c := iban[n]
+		switch {
+		case n < 2 && (c < 'A' || c > 'Z'):
+			return ErrInvalidFormat
+		case n >= 2 && n < 4 && (c < '0' || c > '9'):
+			return ErrInvalidFormat
+		case (c < 'A' || c > 'Z') && (c < '0' || c > '9'):
+			return ErrInvalidCharacters
+		}
+	}
+	length, ok := lengths[iban[:2]]
+	if !ok {
+		return fmt.Errorf("%w %s", ErrUnknownCountry, iban[:2])
+	}
+	if len(iban) != length {
+		return fmt.Errorf("%w: %s IBANs have %d characters", ErrInvalidLength, iban[:2], length)
+	}
+	if mod97(iban[4:]+iban[:4]) != 1 {
+		return ErrInvalidChecksum
+	}
+	return nil
+}
+
+// mod97 returns the remainder of s, with letters replaced by 10 to 35, when
+// divided by 97.
+func mod97(s string) int {
+	remainder := 0
+	for n := 0; n < len(s); n++ {
+		c := s[n]
+		if c >= 'A' {
+			remainder = (remainder*100 + int(c-'A'+10)) % 97
+		} else {
+			remainder = (remainder*10 + int(c-'0')) % 97
+		}
+	}
+	return remainder
+}
+
+// lengths lists the IBAN length of each country in the SWIFT IBAN registry.
+var lengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16,
+	"BG": 22, "BH": 22, "BI": 27, "BR": 29, "BY": 28, "CH": 21, "CR": 22,
+	"CY": 28, "CZ": 24, "DE": 22, "DJ": 27, "DK": 18, "DO": 28, "EE": 20,
+	"EG": 29, "ES": 24, "FI": 18, "FK": 18, "FO": 18, "FR": 27, "GB": 22,
+	"GE": 22, "GI": 23, "GL": 18, "GR": 27, "GT": 28, "HN": 28, "HR": 21,
+	"HU": 28, "IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27, "JO": 30,
+	"KW": 30, "KZ": 20, "LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20,
+	"LV": 21, "LY": 25, "MC": 27, "MD": 24, "ME": 22, "MK": 19, "MN": 20,
+	"MR": 27, "MT": 31, "MU": 30, "NI": 28, "NL": 18, "NO": 15, "OM": 23,
+	"PK": 24, "PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22,
+	"RU": 33, "SA": 24, "SC": 31, "SD": 18, "SE": 24, "SI": 19, "SK": 24,
+	"SM": 27, "SO": 23, "ST": 25, "SV": 28, "TL": 23, "TN": 24, "TR": 26,
+	"UA": 29, "VA": 22, "VG": 24, "XK": 20, "YE": 30,
+}
@@ -0,0 +1,44 @@
+package iban
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzParseIBAN(f *testing.F) {
+	for _, seed := range []string{
+		"DE89 3704 0044 0532 0130 00",
+		"GB29NWBK60161331926819",
+		"",
+		"D",
+		"DE8",
+		"DE89",
+		"de89-3704-0044-0532-0130-00",
+		"XX00000000",
+		"DE00370400440532013000",
+		"DE8937040044053201300\x00",
+		"ÄÖ89370400440532013000",
+		"DE89 3704",
+		strings.Repeat("9", 64),
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		number, err := Parse(s)
+		if (err == nil) != (Validate(s) == nil) {
+			t.Fatalf("Parse and Validate disagree on %q", s)
+		}
+		if err != nil {
+			// Accessors must not panic on unvalidated values either.
+			raw := IBAN(s)
+			_, _, _, _ = raw.Country(), raw.CheckDigits(), raw.BBAN(), raw.Format()
+			return
+		}
+		if got := number.Country() + number.CheckDigits() + number.BBAN(); got != number.String() {
+			t.Fatalf("parts of %q make %q", number, got)
+		}
+		if again, err := Parse(number.Format()); err != nil || again != number {
+			t.Fatalf("Parse(Format(%q)) = %q, %v", number, again, err)
+		}
+	})
+}
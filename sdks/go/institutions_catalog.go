@@ -0,0 +1,155 @@
+package openibank
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// institutionsSnapshot is the institutions list shipped with the SDK. It is
+// served by CachedList until the first refresh from the API completes.
+//
+//go:embed data/institutions.json
+var institutionsSnapshot []byte
+
+// catalogRetryDelay is the minimum time between failed background refreshes.
+const catalogRetryDelay = time.Minute
+
+// catalogPageSize is the page size of requests made by RefreshCatalog.
+const catalogPageSize = 100
+
+// institutionCatalog caches the full institutions list.
+type institutionCatalog struct {
+	mu           sync.Mutex
+	loaded       bool
+	institutions []Institution
+	etag         string
+	fetchedAt    time.Time
+	lastAttempt  time.Time
+	refreshing   bool
+}
+
+// WithInstitutionCacheTTL sets how long the institutions list served by
+// CachedList is considered fresh before a background refresh is started.
+// The default is 24 hours.
+func WithInstitutionCacheTTL(ttl time.Duration) Option {
+	return func(c *Config) {
+		c.InstitutionCacheTTL = ttl
+	}
+}
+
+// CachedList returns the full institutions list without waiting for the
+// network. The list comes from memory, or from the snapshot embedded in the
+// SDK on first use. When it is older than the cache TTL, a refresh is
+// started in the background using a conditional request, so unchanged lists
+// cost no download. The returned slice may be modified by the caller.
+func (s *InstitutionsService) CachedList(ctx context.Context) ([]Institution, error) {
+	c := &s.catalog
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.loaded {
+		if err := c.loadSnapshot(); err != nil {
+			return nil, fmt.Errorf("failed to load institutions snapshot: %w", err)
+		}
+		c.loaded = true
+	}
+
+	now := s.client.now()
+	stale := now.Sub(c.fetchedAt) > s.client.config.InstitutionCacheTTL
+	if stale && !c.refreshing && now.Sub(c.lastAttempt) > catalogRetryDelay {
+		c.refreshing = true
+		c.lastAttempt = now
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), s.client.config.Timeout)
+			defer cancel()
+			_ = s.RefreshCatalog(ctx)
+		}()
+	}
+
+	institutions := make([]Institution, len(c.institutions))
+	copy(institutions, c.institutions)
+	return institutions, nil
+}
+
+// RefreshCatalog refreshes the list served by CachedList from the API,
+// sending the ETag of the cached list so that an unchanged list is not
+// downloaded again. A changed list is fetched page by page and replaces the
+// cached one only once every page has been fetched.
+func (s *InstitutionsService) RefreshCatalog(ctx context.Context) error {
+	c := &s.catalog
+	c.mu.Lock()
+	etag := c.etag
+	c.mu.Unlock()
+
+	institutions, header, err := s.fetchCatalog(ctx, etag)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshing = false
+	switch {
+	case errors.Is(err, errNotModified):
+		c.fetchedAt = s.client.now()
+		return nil
+	case err != nil:
+		return err
+	}
+	c.institutions = institutions
+	c.etag = header.Get("ETag")
+	c.fetchedAt = s.client.now()
+	c.loaded = true
+	return nil
+}
+
+// fetchCatalog fetches every page of the institutions list. The first
+// request is conditional on etag; its response headers, which carry the
+// ETag of the whole list, are returned.
+func (s *InstitutionsService) fetchCatalog(ctx context.Context, etag string) ([]Institution, http.Header, error) {
+	var institutions []Institution
+	var header http.Header
+	for offset := 0; ; offset += catalogPageSize {
+		values := url.Values{}
+		values.Set("limit", strconv.Itoa(catalogPageSize))
+		values.Set("offset", strconv.Itoa(offset))
+		var result struct {
+			Institutions []Institution `json:"institutions"`
+		}
+		var opts []RequestOption
+		if offset == 0 {
+			opts = append(opts, withResponseHeader(&header))
+			if etag != "" {
+				opts = append(opts, withHeader("If-None-Match", etag))
+			}
+		}
+		if err := s.client.request(ctx, "GET", "/institutions", values, nil, &result, opts...); err != nil {
+			return nil, nil, err
+		}
+		institutions = append(institutions, result.Institutions...)
+		if len(result.Institutions) < catalogPageSize {
+			return institutions, header, nil
+		}
+	}
+}
+
+// loadSnapshot loads the embedded snapshot. It is marked as fetched long ago
+// so that the first CachedList call refreshes it in the background.
+func (c *institutionCatalog) loadSnapshot() error {
+	var snapshot struct {
+		ETag         string        `json:"etag"`
+		Institutions []Institution `json:"institutions"`
+	}
+	if err := json.Unmarshal(institutionsSnapshot, &snapshot); err != nil {
+		return err
+	}
+	c.institutions = snapshot.Institutions
+	c.etag = snapshot.ETag
+	c.fetchedAt = time.Time{}
+	return nil
+}
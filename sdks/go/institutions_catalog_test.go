@@ -0,0 +1,101 @@
+package openibank
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// catalogExecutor serves n institutions in pages under etag, answers a
+// matching If-None-Match with 304 and fails requests at offset failAt.
+type catalogExecutor struct {
+	n      int
+	etag   string
+	failAt string
+	calls  []*Call
+}
+
+func (e *catalogExecutor) Execute(ctx context.Context, call *Call) (*Response, error) {
+	e.calls = append(e.calls, call)
+	if call.Header.Get("If-None-Match") == e.etag {
+		return &Response{StatusCode: http.StatusNotModified}, nil
+	}
+	if call.Query.Get("offset") == e.failAt {
+		return &Response{StatusCode: http.StatusServiceUnavailable, Body: []byte(`{}`)}, nil
+	}
+	var offset, limit int
+	fmt.Sscan(call.Query.Get("offset"), &offset)
+	fmt.Sscan(call.Query.Get("limit"), &limit)
+	var items []string
+	for i := offset; i < offset+limit && i < e.n; i++ {
+		items = append(items, fmt.Sprintf(`{"id":"inst_%d"}`, i))
+	}
+	return &Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Etag": {e.etag}},
+		Body:       []byte(`{"institutions":[` + strings.Join(items, ",") + `]}`),
+	}, nil
+}
+
+func TestRefreshCatalogFetchesEveryPage(t *testing.T) {
+	executor := &catalogExecutor{n: 250, etag: `"v1"`, failAt: "-"}
+	client := NewClient(WithExecutor(executor), WithAPIKey("test_key"), WithMaxRetries(0))
+	ctx := context.Background()
+
+	if err := client.Institutions.RefreshCatalog(ctx); err != nil {
+		t.Fatal(err)
+	}
+	institutions, err := client.Institutions.CachedList(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(institutions) != 250 || institutions[249].ID != "inst_249" {
+		t.Fatalf("cached %d institutions, want 250", len(institutions))
+	}
+	if len(executor.calls) != 3 {
+		t.Fatalf("made %d requests, want 3", len(executor.calls))
+	}
+	for i, call := range executor.calls {
+		if want := fmt.Sprint(i * catalogPageSize); call.Query.Get("offset") != want {
+			t.Errorf("page %d: offset %q, want %s", i+1, call.Query.Get("offset"), want)
+		}
+		if i > 0 && call.Header.Get("If-None-Match") != "" {
+			t.Errorf("page %d: sent If-None-Match", i+1)
+		}
+	}
+
+	// An unchanged list costs a single conditional request.
+	executor.calls = nil
+	if err := client.Institutions.RefreshCatalog(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(executor.calls) != 1 || executor.calls[0].Header.Get("If-None-Match") != `"v1"` {
+		t.Errorf("refreshing an unchanged list made %d requests, want one conditional request", len(executor.calls))
+	}
+}
+
+func TestRefreshCatalogKeepsCacheOnFailedPage(t *testing.T) {
+	executor := &catalogExecutor{n: 150, etag: `"v1"`, failAt: "-"}
+	client := NewClient(WithExecutor(executor), WithAPIKey("test_key"), WithMaxRetries(0))
+	ctx := context.Background()
+	if err := client.Institutions.RefreshCatalog(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	executor.n, executor.etag, executor.failAt = 300, `"v2"`, "200"
+	if err := client.Institutions.RefreshCatalog(ctx); err == nil {
+		t.Fatal("RefreshCatalog succeeded although a page failed")
+	}
+	institutions, err := client.Institutions.CachedList(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(institutions) != 150 {
+		t.Errorf("cached %d institutions after a failed refresh, want the previous 150", len(institutions))
+	}
+	if etag := client.Institutions.catalog.etag; etag != `"v1"` {
+		t.Errorf("etag = %s after a failed refresh, want \"v1\"", etag)
+	}
+}
@@ -0,0 +1,79 @@
+package openibank
+
+import (
+	"context"
+	"net/url"
+	"sync"
+)
+
+// LogoSize represents the size of an institution logo.
+type LogoSize string
+
+const (
+	// LogoSmall is a 64x64 pixel logo.
+	LogoSmall LogoSize = "small"
+	// LogoMedium is a 128x128 pixel logo.
+	LogoMedium LogoSize = "medium"
+	// LogoLarge is a 256x256 pixel logo.
+	LogoLarge LogoSize = "large"
+)
+
+// Logo is a downloaded institution logo.
+type Logo struct {
+	Data        []byte
+	ContentType string
+}
+
+type logoKey struct {
+	institutionID string
+	size          LogoSize
+}
+
+// logoCache keeps downloaded logos in memory for the lifetime of the client.
+type logoCache struct {
+	mu    sync.Mutex
+	logos map[logoKey]Logo
+}
+
+func (c *logoCache) get(key logoKey) (Logo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	logo, ok := c.logos[key]
+	return logo, ok
+}
+
+func (c *logoCache) put(key logoKey, logo Logo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.logos == nil {
+		c.logos = map[logoKey]Logo{}
+	}
+	c.logos[key] = logo
+}
+
+// DownloadLogo downloads an institution's logo through the API, for
+// deployments that cannot reach the CDN behind LogoURL directly. Logos are
+// cached in memory, so repeated calls for the same institution and size do
+// not hit the network.
+func (s *InstitutionsService) DownloadLogo(ctx context.Context, institutionID string, size LogoSize) (*Logo, error) {
+	key := logoKey{institutionID, size}
+	if logo, ok := s.logos.get(key); ok {
+		return copyLogo(logo), nil
+	}
+
+	values := url.Values{}
+	values.Set("size", string(size))
+	var raw rawResponse
+	if err := s.client.request(ctx, "GET", "/institutions/"+institutionID+"/logo", values, nil, &raw, withHeader("Accept", "image/*")); err != nil {
+		return nil, err
+	}
+	logo := Logo{Data: raw.data, ContentType: raw.contentType}
+	s.logos.put(key, logo)
+	return copyLogo(logo), nil
+}
+
+func copyLogo(logo Logo) *Logo {
+	data := make([]byte, len(logo.Data))
+	copy(data, logo.Data)
+	return &Logo{Data: data, ContentType: logo.ContentType}
+}
@@ -0,0 +1,333 @@
+// Package iso20022 builds ISO 20022 payment messages from SDK payment
+// parameters.
+//
+// Example usage:
+//
+//	file, err := iso20022.BuildPain001(payments,
+//	    iso20022.WithInitiatingParty("ACME Corp"),
+//	    iso20022.WithDebtor("acc_123456", iso20022.Debtor{
+//	        Name: "ACME Corp",
+//	        IBAN: "DE89370400440532013000",
+//	        BIC:  "COBADEFFXXX",
+//	    }),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	bulk, err := client.Payments.UploadBulkFile(ctx, bytes.NewReader(file))
+package iso20022
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	openibank "github.com/openibank/sdk-go"
+	"github.com/openibank/sdk-go/money"
+)
+
+// Version is a pain.001 message version.
+type Version string
+
+const (
+	// Pain001V03 is pain.001.001.03, the version required by most SEPA
+	// rulebooks.
+	Pain001V03 Version = "pain.001.001.03"
+	// Pain001V09 is pain.001.001.09, the version used by the 2019 SEPA
+	// rulebooks and CBPR+.
+	Pain001V09 Version = "pain.001.001.09"
+)
+
+// Debtor describes the debtor of the payments made from one account.
+type Debtor struct {
+	Name string
+	IBAN string
+	BIC  string
+}
+
+// maxNameLength is the maximum length of names (Nm) and unstructured
+// remittance information (Ustrd).
+const maxNameLength = 140
+
+// Option configures BuildPain001.
+type Option func(*options)
+
+type options struct {
+	version         Version
+	messageID       string
+	initiatingParty string
+	createdAt       time.Time
+	debtors         map[string]Debtor
+}
+
+// WithVersion sets the pain.001 version. The default is Pain001V03.
+func WithVersion(version Version) Option {
+	return func(o *options) {
+		o.version = version
+	}
+}
+
+// WithMessageID sets the message ID. By default a random ID is generated.
+func WithMessageID(id string) Option {
+	return func(o *options) {
+		o.messageID = id
+	}
+}
+
+// WithInitiatingParty sets the name of the party initiating the payments.
+func WithInitiatingParty(name string) Option {
+	return func(o *options) {
+		o.initiatingParty = name
+	}
+}
+
+// WithCreationTime sets the message creation time. The default is now.
+func WithCreationTime(t time.Time) Option {
+	return func(o *options) {
+		o.createdAt = t
+	}
+}
+
+// WithDebtor sets the debtor details for payments made from the given debtor
+// account ID. Without it, the account ID is sent as a proprietary account
+// identifier for the platform to resolve.
+func WithDebtor(debtorAccountID string, debtor Debtor) Option {
+	return func(o *options) {
+		o.debtors[debtorAccountID] = debtor
+	}
+}
+
+// BuildPain001 builds a pain.001 customer credit transfer initiation message
+// from the given payments. Payments are grouped into payment information
+// blocks by debtor account, execution date, instant scheme and whether they
+// are SEPA payments, that is euro payments to an IBAN. Only SEPA blocks
+// carry the SEPA service level. Invalid payments are reported as a
+// *openibank.ValidationError.
+func BuildPain001(payments []openibank.PaymentCreateParams, opts ...Option) ([]byte, error) {
+	o := &options{
+		version:   Pain001V03,
+		createdAt: time.Now(),
+		debtors:   map[string]Debtor{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.version != Pain001V03 && o.version != Pain001V09 {
+		return nil, fmt.Errorf("iso20022: unsupported version %q", o.version)
+	}
+	if o.messageID == "" {
+		o.messageID = openibank.NewEndToEndID()
+	}
+
+	if err := o.validate(payments); err != nil {
+		return nil, err
+	}
+
+	doc := document{
+		Xmlns: "urn:iso:std:iso:20022:tech:xsd:" + string(o.version),
+		Initiation: initiation{
+			GroupHeader: groupHeader{
+				MessageID:       o.messageID,
+				CreatedAt:       o.createdAt.Format("2006-01-02T15:04:05"),
+				NumberOfTxs:     strconv.Itoa(len(payments)),
+				ControlSum:      controlSum(payments),
+				InitiatingParty: party{Name: o.initiatingParty},
+			},
+		},
+	}
+
+	index := map[string]int{}
+	for _, p := range payments {
+		date := o.createdAt.Format("2006-01-02")
+		if p.ExecutionDate != nil {
+			date = p.ExecutionDate.String()
+		}
+		instant := p.Scheme != nil && *p.Scheme == openibank.SchemeSEPAInstant
+		sepa := p.Amount.Currency == "EUR" && p.Creditor.Account.IBAN != nil
+		key := fmt.Sprintf("%s|%s|%t|%t", p.DebtorAccountID, date, instant, sepa)
+
+		n, ok := index[key]
+		if !ok {
+			n = len(doc.Initiation.PaymentInfos)
+			index[key] = n
+			doc.Initiation.PaymentInfos = append(doc.Initiation.PaymentInfos, o.paymentInfo(n, p.DebtorAccountID, date, instant, sepa))
+		}
+		info := &doc.Initiation.PaymentInfos[n]
+		info.Transactions = append(info.Transactions, creditTransfer(p))
+	}
+	for i := range doc.Initiation.PaymentInfos {
+		info := &doc.Initiation.PaymentInfos[i]
+		info.NumberOfTxs = strconv.Itoa(len(info.Transactions))
+		info.ControlSum = controlSum(info.payments())
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("iso20022: failed to encode pain.001: %w", err)
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+func (o *options) paymentInfo(n int, debtorAccountID, date string, instant, sepa bool) paymentInfo {
+	info := paymentInfo{
+		ID:     fmt.Sprintf("%s-%d", truncate(o.messageID, 30), n+1),
+		Method: "TRF",
+	}
+	if sepa || instant {
+		info.TypeInfo = &paymentTypeInfo{}
+	}
+	if sepa {
+		info.TypeInfo.ServiceLevel = &code{Code: "SEPA"}
+	}
+	if instant {
+		info.TypeInfo.LocalInstrument = &code{Code: "INST"}
+	}
+	if o.version == Pain001V03 {
+		info.ExecutionDate = executionDate{Value: date}
+	} else {
+		info.ExecutionDate = executionDate{Date: date}
+	}
+
+	debtor, ok := o.debtors[debtorAccountID]
+	info.Debtor = party{Name: debtor.Name}
+	if ok && debtor.IBAN != "" {
+		info.DebtorAccount = account{ID: accountID{IBAN: debtor.IBAN}}
+	} else {
+		info.DebtorAccount = account{ID: accountID{Other: &otherID{ID: debtorAccountID}}}
+	}
+	switch {
+	case debtor.BIC != "" && o.version == Pain001V03:
+		info.DebtorAgent = agent{FinancialInstitution: financialInstitution{BIC: debtor.BIC}}
+	case debtor.BIC != "":
+		info.DebtorAgent = agent{FinancialInstitution: financialInstitution{BICFI: debtor.BIC}}
+	default:
+		info.DebtorAgent = agent{FinancialInstitution: financialInstitution{Other: &otherID{ID: "NOTPROVIDED"}}}
+	}
+	return info
+}
+
+func creditTransfer(p openibank.PaymentCreateParams) creditTransferTx {
+	endToEndID := "NOTPROVIDED"
+	if p.EndToEndID != nil {
+		endToEndID = *p.EndToEndID
+	}
+	tx := creditTransferTx{
+		params:       p,
+		PaymentID:    paymentID{EndToEndID: endToEndID},
+		Amount:       amount{Instructed: instructedAmount{Currency: p.Amount.Currency, Value: p.Amount.Amount}},
+		Creditor:     party{Name: p.Creditor.Name},
+		CreditorAcct: creditorAccount(p.Creditor.Account),
+	}
+	if p.Reference != nil {
+		tx.Remittance = &remittance{Unstructured: *p.Reference}
+	}
+	return tx
+}
+
+func creditorAccount(a openibank.CreditorAccount) account {
+	switch {
+	case a.IBAN != nil:
+		return account{ID: accountID{IBAN: strings.ReplaceAll(*a.IBAN, " ", "")}}
+	case a.SortCode != nil && a.AccountNumber != nil:
+		return account{ID: accountID{Other: &otherID{
+			ID:     *a.SortCode + *a.AccountNumber,
+			Scheme: &schemeName{Proprietary: "UK.OBIE.SortCodeAccountNumber"},
+		}}}
+	default:
+		return account{ID: accountID{Other: &otherID{
+			ID:     *a.BBAN,
+			Scheme: &schemeName{Code: "BBAN"},
+		}}}
+	}
+}
+
+func (o *options) validate(payments []openibank.PaymentCreateParams) error {
+	var errs []openibank.FieldError
+	add := func(i int, field, message string) {
+		errs = append(errs, openibank.FieldError{Field: fmt.Sprintf("payments[%d].%s", i, field), Message: message})
+	}
+	tooLong := fmt.Sprintf("must be at most %d characters", maxNameLength)
+	if utf8.RuneCountInString(o.initiatingParty) > maxNameLength {
+		errs = append(errs, openibank.FieldError{Field: "initiating_party", Message: tooLong})
+	}
+	ids := make([]string, 0, len(o.debtors))
+	for id := range o.debtors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if utf8.RuneCountInString(o.debtors[id].Name) > maxNameLength {
+			errs = append(errs, openibank.FieldError{Field: fmt.Sprintf("debtors[%s].name", id), Message: tooLong})
+		}
+	}
+	if len(payments) == 0 {
+		return &openibank.ValidationError{
+			Message: "invalid payment file",
+			Code:    openibank.ErrCodeInvalidPaymentFile,
+			Errors:  []openibank.FieldError{{Field: "payments", Message: "at least one payment is required"}},
+		}
+	}
+	for i, p := range payments {
+		if p.DebtorAccountID == "" {
+			add(i, "debtor_account_id", "is required")
+		}
+		if p.Creditor.Name == "" {
+			add(i, "creditor.name", "is required")
+		} else if utf8.RuneCountInString(p.Creditor.Name) > maxNameLength {
+			add(i, "creditor.name", tooLong)
+		}
+		a := p.Creditor.Account
+		if a.IBAN == nil && a.BBAN == nil && (a.SortCode == nil || a.AccountNumber == nil) {
+			add(i, "creditor.account", "an IBAN, BBAN or sort code and account number is required")
+		}
+		if r, err := money.ParseDecimal(p.Amount.Amount); err != nil || r.Sign() <= 0 {
+			add(i, "amount.amount", "must be a positive decimal amount with at most 18 digits, 5 of them after the decimal point")
+		}
+		if len(p.Amount.Currency) != 3 {
+			add(i, "amount.currency", "must be a 3-letter ISO 4217 code")
+		}
+		if p.EndToEndID != nil && len(*p.EndToEndID) > 35 {
+			add(i, "end_to_end_id", "must be at most 35 characters")
+		}
+		if p.Reference != nil && utf8.RuneCountInString(*p.Reference) > maxNameLength {
+			add(i, "reference", tooLong)
+		}
+		if p.Schedule != nil {
+			add(i, "schedule", "periodic payments cannot be submitted in a payment file")
+		}
+	}
+	if len(errs) > 0 {
+		return &openibank.ValidationError{Message: "invalid payment file", Code: openibank.ErrCodeInvalidPaymentFile, Errors: errs}
+	}
+	return nil
+}
+
+// controlSum returns the sum of the payment amounts, keeping the largest
+// number of fraction digits used by any amount. The amounts must have been
+// validated.
+func controlSum(payments []openibank.PaymentCreateParams) string {
+	sum := new(big.Rat)
+	digits := 0
+	for _, p := range payments {
+		if r, err := money.ParseDecimal(p.Amount.Amount); err == nil {
+			sum.Add(sum, r)
+		}
+		if _, frac, ok := strings.Cut(p.Amount.Amount, "."); ok && len(frac) > digits {
+			digits = len(frac)
+		}
+	}
+	return sum.FloatString(digits)
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
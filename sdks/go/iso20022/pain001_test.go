@@ -0,0 +1,119 @@
+package iso20022_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	openibank "github.com/openibank/sdk-go"
+	"github.com/openibank/sdk-go/iso20022"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// payments are grouped into four blocks: two SEPA blocks for acc_1 on
+// different dates, an instant block for acc_1 and a non-SEPA block for the
+// GBP payment from acc_2.
+func payments() []openibank.PaymentCreateParams {
+	instant := openibank.SchemeSEPAInstant
+	later := openibank.DateOf(time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC))
+	iban := func(name, iban, amount string) openibank.PaymentCreateParams {
+		return openibank.PaymentCreateParams{
+			Creditor:        openibank.Creditor{Name: name, Account: openibank.CreditorAccount{IBAN: openibank.String(iban)}},
+			Amount:          openibank.Amount{Amount: amount, Currency: "EUR"},
+			DebtorAccountID: "acc_1",
+		}
+	}
+
+	first := iban("Max Mustermann", "DE89 3704 0044 0532 0130 00", "150.00")
+	first.EndToEndID = openibank.String("E2E-0001")
+	first.Reference = openibank.String("Invoice 2030-001")
+	second := iban("Erika Mustermann", "DE02120300000000202051", "20.5")
+	third := iban("Jean Dupont", "FR1420041010050500013M02606", "99.999")
+	third.ExecutionDate = &later
+	fourth := iban("Mario Rossi", "IT60X0542811101000000123456", "10.00")
+	fourth.Scheme = &instant
+	uk := openibank.PaymentCreateParams{
+		Creditor: openibank.Creditor{
+			Name:    "John Smith",
+			Account: openibank.CreditorAccount{SortCode: openibank.String("200000"), AccountNumber: openibank.String("55779911")},
+		},
+		Amount:          openibank.Amount{Amount: "20.00", Currency: "GBP"},
+		DebtorAccountID: "acc_2",
+	}
+	return []openibank.PaymentCreateParams{first, second, third, fourth, uk}
+}
+
+func TestBuildPain001(t *testing.T) {
+	for _, tt := range []struct {
+		version iso20022.Version
+		golden  string
+	}{
+		{iso20022.Pain001V03, "testdata/pain001_v03.xml"},
+		{iso20022.Pain001V09, "testdata/pain001_v09.xml"},
+	} {
+		t.Run(string(tt.version), func(t *testing.T) {
+			got, err := iso20022.BuildPain001(payments(),
+				iso20022.WithVersion(tt.version),
+				iso20022.WithMessageID("MSG-20300131-0001"),
+				iso20022.WithCreationTime(time.Date(2030, 1, 31, 9, 30, 0, 0, time.UTC)),
+				iso20022.WithInitiatingParty("ACME Corp"),
+				iso20022.WithDebtor("acc_1", iso20022.Debtor{Name: "ACME Corp", IBAN: "DE89370400440532013000", BIC: "COBADEFFXXX"}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *update {
+				if err := os.WriteFile(tt.golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(tt.golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("BuildPain001 differs from %s:\n%s", tt.golden, got)
+			}
+		})
+	}
+}
+
+func TestBuildPain001Invalid(t *testing.T) {
+	long := strings.Repeat("x", 141)
+	tests := []struct {
+		name   string
+		modify func(p *openibank.PaymentCreateParams)
+		opts   []iso20022.Option
+		field  string
+	}{
+		{"fraction amount", func(p *openibank.PaymentCreateParams) { p.Amount.Amount = "1/2" }, nil, "payments[0].amount.amount"},
+		{"exponent amount", func(p *openibank.PaymentCreateParams) { p.Amount.Amount = "1e3" }, nil, "payments[0].amount.amount"},
+		{"hexadecimal amount", func(p *openibank.PaymentCreateParams) { p.Amount.Amount = "0x10" }, nil, "payments[0].amount.amount"},
+		{"too many fraction digits", func(p *openibank.PaymentCreateParams) { p.Amount.Amount = "1.000001" }, nil, "payments[0].amount.amount"},
+		{"too many digits", func(p *openibank.PaymentCreateParams) { p.Amount.Amount = "1234567890123456789" }, nil, "payments[0].amount.amount"},
+		{"zero amount", func(p *openibank.PaymentCreateParams) { p.Amount.Amount = "0.00" }, nil, "payments[0].amount.amount"},
+		{"long creditor name", func(p *openibank.PaymentCreateParams) { p.Creditor.Name = long }, nil, "payments[0].creditor.name"},
+		{"long reference", func(p *openibank.PaymentCreateParams) { p.Reference = openibank.String(long) }, nil, "payments[0].reference"},
+		{"long initiating party", func(p *openibank.PaymentCreateParams) {}, []iso20022.Option{iso20022.WithInitiatingParty(long)}, "initiating_party"},
+		{"long debtor name", func(p *openibank.PaymentCreateParams) {}, []iso20022.Option{iso20022.WithDebtor("acc_1", iso20022.Debtor{Name: long})}, "debtors[acc_1].name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := payments()[0]
+			tt.modify(&p)
+			_, err := iso20022.BuildPain001([]openibank.PaymentCreateParams{p}, tt.opts...)
+			var verr *openibank.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("err = %v, want a *ValidationError", err)
+			}
+			if len(verr.Errors) != 1 || verr.Errors[0].Field != tt.field {
+				t.Errorf("errors = %v, want one for %s", verr.Errors, tt.field)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package iso20022
+
+import (
+	"encoding/xml"
+
+	openibank "github.com/openibank/sdk-go"
+)
+
+// XML elements of a pain.001 message. Elements whose shape differs between
+// versions carry both variants and leave the unused one empty.
+
+type document struct {
+	XMLName    xml.Name   `xml:"Document"`
+	Xmlns      string     `xml:"xmlns,attr"`
+	Initiation initiation `xml:"CstmrCdtTrfInitn"`
+}
+
+type initiation struct {
+	GroupHeader  groupHeader   `xml:"GrpHdr"`
+	PaymentInfos []paymentInfo `xml:"PmtInf"`
+}
+
+type groupHeader struct {
+	MessageID       string `xml:"MsgId"`
+	CreatedAt       string `xml:"CreDtTm"`
+	NumberOfTxs     string `xml:"NbOfTxs"`
+	ControlSum      string `xml:"CtrlSum"`
+	InitiatingParty party  `xml:"InitgPty"`
+}
+
+type paymentInfo struct {
+	ID            string             `xml:"PmtInfId"`
+	Method        string             `xml:"PmtMtd"`
+	NumberOfTxs   string             `xml:"NbOfTxs"`
+	ControlSum    string             `xml:"CtrlSum"`
+	TypeInfo      *paymentTypeInfo   `xml:"PmtTpInf,omitempty"`
+	ExecutionDate executionDate      `xml:"ReqdExctnDt"`
+	Debtor        party              `xml:"Dbtr"`
+	DebtorAccount account            `xml:"DbtrAcct"`
+	DebtorAgent   agent              `xml:"DbtrAgt"`
+	Transactions  []creditTransferTx `xml:"CdtTrfTxInf"`
+}
+
+// payments returns the parameters of the transactions in the block.
+func (p *paymentInfo) payments() []openibank.PaymentCreateParams {
+	params := make([]openibank.PaymentCreateParams, len(p.Transactions))
+	for i, tx := range p.Transactions {
+		params[i] = tx.params
+	}
+	return params
+}
+
+type paymentTypeInfo struct {
+	ServiceLevel    *code `xml:"SvcLvl,omitempty"`
+	LocalInstrument *code `xml:"LclInstrm,omitempty"`
+}
+
+type code struct {
+	Code string `xml:"Cd"`
+}
+
+// executionDate is a plain date in pain.001.001.03 and a choice wrapping the
+// date in later versions.
+type executionDate struct {
+	Value string `xml:",chardata"`
+	Date  string `xml:"Dt,omitempty"`
+}
+
+type party struct {
+	Name string `xml:"Nm,omitempty"`
+}
+
+type account struct {
+	ID accountID `xml:"Id"`
+}
+
+type accountID struct {
+	IBAN  string   `xml:"IBAN,omitempty"`
+	Other *otherID `xml:"Othr,omitempty"`
+}
+
+type otherID struct {
+	ID     string      `xml:"Id"`
+	Scheme *schemeName `xml:"SchmeNm,omitempty"`
+}
+
+type schemeName struct {
+	Code        string `xml:"Cd,omitempty"`
+	Proprietary string `xml:"Prtry,omitempty"`
+}
+
+type agent struct {
+	FinancialInstitution financialInstitution `xml:"FinInstnId"`
+}
+
+// financialInstitution identifies an agent by BIC in pain.001.001.03 and by
+// BICFI in later versions.
+type financialInstitution struct {
+	BIC   string   `xml:"BIC,omitempty"`
+	BICFI string   `xml:"BICFI,omitempty"`
+	Other *otherID `xml:"Othr,omitempty"`
+}
+
+type creditTransferTx struct {
+	params openibank.PaymentCreateParams
+
+	PaymentID    paymentID   `xml:"PmtId"`
+	Amount       amount      `xml:"Amt"`
+	Creditor     party       `xml:"Cdtr"`
+	CreditorAcct account     `xml:"CdtrAcct"`
+	Remittance   *remittance `xml:"RmtInf,omitempty"`
+}
+
+type paymentID struct {
+	EndToEndID string `xml:"EndToEndId"`
+}
+
+type amount struct {
+	Instructed instructedAmount `xml:"InstdAmt"`
+}
+
+type instructedAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type remittance struct {
+	Unstructured string `xml:"Ustrd"`
+}
@@ -0,0 +1,73 @@
+package openibank
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// MetaString returns the string stored under key in the transaction's
+// metadata, and whether there is one.
+func (t *Transaction) MetaString(key string) (string, bool) {
+	s, ok := t.Metadata[key].(string)
+	return s, ok
+}
+
+// MetaInt returns the integer stored under key in the transaction's
+// metadata, and whether there is one. Whole JSON numbers and strings
+// holding an integer are accepted.
+func (t *Transaction) MetaInt(key string) (int64, bool) {
+	switch v := t.Metadata[key].(type) {
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	case json.Number:
+		i, err := v.Int64()
+		return i, err == nil
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// MetaTime returns the time stored under key in the transaction's metadata,
+// and whether there is one. RFC 3339 timestamps and "2006-01-02" dates, taken
+// as midnight UTC, are accepted.
+func (t *Transaction) MetaTime(key string) (time.Time, bool) {
+	s, ok := t.Metadata[key].(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	if parsed, err := time.Parse(time.RFC3339, s); err == nil {
+		return parsed, true
+	}
+	if parsed, err := time.Parse(dateLayout, s); err == nil {
+		return parsed, true
+	}
+	return time.Time{}, false
+}
+
+// DecodeMetadata decodes metadata into a value of type T, typically a struct
+// with JSON tags for the keys your integration stores:
+//
+//	type orderMeta struct {
+//	    OrderID string `json:"order_id"`
+//	    Channel string `json:"channel"`
+//	}
+//	meta, err := openibank.DecodeMetadata[orderMeta](tx.Metadata)
+func DecodeMetadata[T any](metadata map[string]interface{}) (T, error) {
+	var v T
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return v, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return v, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	return v, nil
+}
@@ -0,0 +1,26 @@
+package openibank
+
+import "time"
+
+// MetricsRecorder receives metrics from the client, for example to export
+// them to Prometheus or StatsD. Methods may be called concurrently and
+// should return quickly.
+//
+// RealtimeEvent is called once per event handled by a subscription, with the
+// time its handler took and the lag: how long after the server's timestamp
+// the event was received. Counting the calls gives the event rate.
+// RealtimeReconnect is called after each reconnection attempt with its
+// error, nil if it succeeded, and RealtimeDropped for each event discarded
+// because its buffer was full.
+type MetricsRecorder interface {
+	RealtimeEvent(eventType EventType, handlerLatency, lag time.Duration)
+	RealtimeReconnect(attempt int, err error)
+	RealtimeDropped(eventType EventType)
+}
+
+// WithMetricsRecorder sets the recorder that receives the client's metrics.
+func WithMetricsRecorder(recorder MetricsRecorder) Option {
+	return func(c *Config) {
+		c.Metrics = recorder
+	}
+}
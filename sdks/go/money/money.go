@@ -0,0 +1,251 @@
+// Package money converts amounts between the API's decimal format, such as
+// "1234.56", and the number formats of locales, such as "1.234,56" in
+// Germany.
+//
+// Example usage:
+//
+//	amount, err := money.Parse("1.234,56", "de-DE")
+//	if err != nil {
+//	    log.Fatal(err) // for example money.ErrInvalidAmount
+//	}
+//	fmt.Println(amount) // 1234.56
+//
+//	s, _ := money.Format("1234.56", "de-DE")
+//	fmt.Println(s) // 1.234,56
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	// ErrUnknownLocale is returned for a locale whose number format is not
+	// known.
+	ErrUnknownLocale = errors.New("money: unknown locale")
+	// ErrInvalidAmount is returned for an amount that is not a number in
+	// the expected format.
+	ErrInvalidAmount = errors.New("money: invalid amount")
+)
+
+// format is the number format of a locale.
+type format struct {
+	decimal string
+	group   string
+	// alternativeGroups are also accepted as group separators when
+	// parsing, such as the spaces people type instead of no-break spaces.
+	alternativeGroups []string
+}
+
+const (
+	noBreakSpace       = "\u00a0"
+	narrowNoBreakSpace = "\u202f"
+)
+
+var spaces = []string{" ", noBreakSpace, narrowNoBreakSpace}
+
+var (
+	pointComma = format{decimal: ".", group: ","}
+	commaPoint = format{decimal: ",", group: "."}
+	commaSpace = format{decimal: ",", group: noBreakSpace, alternativeGroups: spaces}
+)
+
+// formats maps locales to their number formats.
+var formats = map[string]format{
+	"en-US": pointComma,
+	"en-GB": pointComma,
+	"en-IE": pointComma,
+	"en-AU": pointComma,
+	"en-CA": pointComma,
+	"ja-JP": pointComma,
+	"zh-CN": pointComma,
+	"de-DE": commaPoint,
+	"de-AT": {decimal: ",", group: noBreakSpace, alternativeGroups: append([]string{"."}, spaces...)},
+	"de-CH": {decimal: ".", group: "\u2019", alternativeGroups: []string{"'"}},
+	"fr-FR": {decimal: ",", group: narrowNoBreakSpace, alternativeGroups: spaces},
+	"fr-BE": {decimal: ",", group: narrowNoBreakSpace, alternativeGroups: spaces},
+	"fr-CH": {decimal: ",", group: narrowNoBreakSpace, alternativeGroups: spaces},
+	"nl-NL": commaPoint,
+	"nl-BE": commaPoint,
+	"es-ES": commaPoint,
+	"it-IT": commaPoint,
+	"pt-PT": commaSpace,
+	"pt-BR": commaPoint,
+	"da-DK": commaPoint,
+	"el-GR": commaPoint,
+	"tr-TR": commaPoint,
+	"sv-SE": {decimal: ",", group: noBreakSpace, alternativeGroups: spaces},
+	"nb-NO": commaSpace,
+	"fi-FI": commaSpace,
+	"pl-PL": commaSpace,
+	"cs-CZ": commaSpace,
+	"sk-SK": commaSpace,
+	"hu-HU": commaSpace,
+	"ro-RO": commaPoint,
+	"bg-BG": commaSpace,
+	"lt-LT": commaSpace,
+	"lv-LV": commaSpace,
+	"et-EE": commaSpace,
+}
+
+// languages maps languages to the locale used when only a language is
+// given, such as "de" for "de-DE".
+var languages = map[string]string{
+	"en": "en-US",
+	"de": "de-DE",
+	"fr": "fr-FR",
+	"nl": "nl-NL",
+	"es": "es-ES",
+	"it": "it-IT",
+	"pt": "pt-PT",
+	"da": "da-DK",
+	"sv": "sv-SE",
+	"nb": "nb-NO",
+	"no": "nb-NO",
+	"fi": "fi-FI",
+	"pl": "pl-PL",
+}
+
+// lookup returns the number format of a locale such as "de-DE", "de_DE" or
+// "de".
+func lookup(locale string) (format, error) {
+	tag := strings.ReplaceAll(locale, "_", "-")
+	language, region, hasRegion := strings.Cut(tag, "-")
+	language = strings.ToLower(language)
+	if hasRegion {
+		tag = language + "-" + strings.ToUpper(region)
+	} else {
+		tag = languages[language]
+	}
+	f, ok := formats[tag]
+	if !ok {
+		return format{}, fmt.Errorf("%w %q", ErrUnknownLocale, locale)
+	}
+	return f, nil
+}
+
+// Parse parses an amount written in the number format of locale, such as
+// "1.234,56" for "de-DE", and returns it in the API's decimal format, such
+// as "1234.56". Group separators are optional, but where used they must
+// separate groups of three digits. A leading minus sign is allowed.
+func Parse(s, locale string) (string, error) {
+	f, err := lookup(locale)
+	if err != nil {
+		return "", err
+	}
+	invalid := fmt.Errorf("%w %q for %s", ErrInvalidAmount, s, locale)
+
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	integer, fraction, hasFraction := strings.Cut(s, f.decimal)
+	if hasFraction && (fraction == "" || !isDigits(fraction)) {
+		return "", invalid
+	}
+	groups := splitGroups(integer, f)
+	if !isDigits(groups[0]) {
+		return "", invalid
+	}
+	if len(groups) > 1 {
+		if len(groups[0]) > 3 {
+			return "", invalid
+		}
+		for _, g := range groups[1:] {
+			if len(g) != 3 || !isDigits(g) {
+				return "", invalid
+			}
+		}
+	}
+
+	integer = strings.TrimLeft(strings.Join(groups, ""), "0")
+	if integer == "" {
+		integer = "0"
+	}
+	amount := integer
+	if hasFraction {
+		amount += "." + fraction
+	}
+	if negative {
+		amount = "-" + amount
+	}
+	return amount, nil
+}
+
+// Format formats an amount in the API's decimal format, such as "1234.56",
+// in the number format of locale, such as "1.234,56" for "de-DE". The
+// number of decimal places is kept as given.
+func Format(amount, locale string) (string, error) {
+	f, err := lookup(locale)
+	if err != nil {
+		return "", err
+	}
+	negative := strings.HasPrefix(amount, "-")
+	integer, fraction, hasFraction := strings.Cut(strings.TrimPrefix(amount, "-"), ".")
+	if !isDigits(integer) || (hasFraction && !isDigits(fraction)) {
+		return "", fmt.Errorf("%w %q", ErrInvalidAmount, amount)
+	}
+
+	var b strings.Builder
+	if negative {
+		b.WriteByte('-')
+	}
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(f.group)
+		}
+		b.WriteRune(digit)
+	}
+	if hasFraction {
+		b.WriteString(f.decimal)
+		b.WriteString(fraction)
+	}
+	return b.String(), nil
+}
+
+// Limits of ISO 20022 amounts, which ParseDecimal applies to all amounts.
+const (
+	maxDigits         = 18
+	maxFractionDigits = 5
+)
+
+// ParseDecimal parses an amount in the API's decimal format, such as
+// "1234.56" or "-10", with at most 18 digits of which at most 5 follow the
+// decimal point. Unlike big.Rat's SetString it rejects fractions such as
+// "1/2", exponents such as "1e3" and hexadecimal numbers such as "0x10".
+func ParseDecimal(amount string) (*big.Rat, error) {
+	integer, fraction, hasFraction := strings.Cut(strings.TrimPrefix(amount, "-"), ".")
+	if !isDigits(integer) || (hasFraction && !isDigits(fraction)) ||
+		len(integer)+len(fraction) > maxDigits || len(fraction) > maxFractionDigits {
+		return nil, fmt.Errorf("%w %q", ErrInvalidAmount, amount)
+	}
+	r, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrInvalidAmount, amount)
+	}
+	return r, nil
+}
+
+// splitGroups splits the integer part of an amount at the group separators
+// of f.
+func splitGroups(integer string, f format) []string {
+	for _, sep := range f.alternativeGroups {
+		integer = strings.ReplaceAll(integer, sep, f.group)
+	}
+	return strings.Split(integer, f.group)
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
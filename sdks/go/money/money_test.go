@@ -0,0 +1,34 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	valid := map[string]string{
+		"150.00":              "150",
+		"-10":                 "-10",
+		"0.00001":             "1/100000",
+		"1234567890123.45678": "61728394506172839/50000",
+	}
+	for amount, want := range valid {
+		r, err := ParseDecimal(amount)
+		if err != nil {
+			t.Errorf("ParseDecimal(%q): %v", amount, err)
+			continue
+		}
+		if r.RatString() != want {
+			t.Errorf("ParseDecimal(%q) = %s, want %s", amount, r.RatString(), want)
+		}
+	}
+
+	for _, amount := range []string{
+		"", "1/2", "1e3", "0x10", "+1", " 1", "1.", ".5", "1,00", "--1",
+		"1.000001", "1234567890123456789", "12345678901234.56789",
+	} {
+		if _, err := ParseDecimal(amount); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("ParseDecimal(%q) = %v, want ErrInvalidAmount", amount, err)
+		}
+	}
+}
@@ -0,0 +1,692 @@
+// Package openibankmock provides mocks of the openibank service interfaces
+// for tests of code that uses the client. Each mock has a function field per
+// method; set those a test calls. Calling a method whose function is nil
+// panics.
+//
+// Example usage:
+//
+//	accounts := &openibankmock.Accounts{
+//	    GetFunc: func(ctx context.Context, id string, opts ...openibank.RequestOption) (*openibank.Account, error) {
+//	        return &openibank.Account{ID: id, Currency: "EUR"}, nil
+//	    },
+//	}
+//	report := Reporter{Accounts: accounts}
+package openibankmock
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	openibank "github.com/openibank/sdk-go"
+)
+
+var (
+	_ openibank.AccountsAPI     = (*Accounts)(nil)
+	_ openibank.TransactionsAPI = (*Transactions)(nil)
+	_ openibank.PaymentsAPI     = (*Payments)(nil)
+	_ openibank.ConsentsAPI     = (*Consents)(nil)
+	_ openibank.InstitutionsAPI = (*Institutions)(nil)
+	_ openibank.AuthAPI         = (*Auth)(nil)
+	_ openibank.RealtimeAPI     = (*Realtime)(nil)
+	_ openibank.WebhooksAPI     = (*Webhooks)(nil)
+	_ openibank.SandboxAPI      = (*Sandbox)(nil)
+)
+
+// Accounts mocks openibank.AccountsAPI.
+type Accounts struct {
+	ListFunc        func(ctx context.Context, params *openibank.AccountListParams, opts ...openibank.RequestOption) ([]openibank.Account, error)
+	GetFunc         func(ctx context.Context, accountID string, opts ...openibank.RequestOption) (*openibank.Account, error)
+	GetBalancesFunc func(ctx context.Context, accountID string, opts ...openibank.RequestOption) (openibank.Balances, error)
+}
+
+// List calls ListFunc.
+func (m *Accounts) List(ctx context.Context, params *openibank.AccountListParams, opts ...openibank.RequestOption) ([]openibank.Account, error) {
+	if m.ListFunc == nil {
+		panic("openibankmock: Accounts.List called but ListFunc is not set")
+	}
+	return m.ListFunc(ctx, params, opts...)
+}
+
+// Get calls GetFunc.
+func (m *Accounts) Get(ctx context.Context, accountID string, opts ...openibank.RequestOption) (*openibank.Account, error) {
+	if m.GetFunc == nil {
+		panic("openibankmock: Accounts.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(ctx, accountID, opts...)
+}
+
+// GetBalances calls GetBalancesFunc.
+func (m *Accounts) GetBalances(ctx context.Context, accountID string, opts ...openibank.RequestOption) (openibank.Balances, error) {
+	if m.GetBalancesFunc == nil {
+		panic("openibankmock: Accounts.GetBalances called but GetBalancesFunc is not set")
+	}
+	return m.GetBalancesFunc(ctx, accountID, opts...)
+}
+
+// Transactions mocks openibank.TransactionsAPI.
+type Transactions struct {
+	ListFunc func(ctx context.Context, accountID string, params *openibank.TransactionListParams, opts ...openibank.RequestOption) ([]openibank.Transaction, error)
+	GetFunc  func(ctx context.Context, accountID string, transactionID string, opts ...openibank.RequestOption) (*openibank.Transaction, error)
+	IterFunc func(ctx context.Context, accountID string, params *openibank.TransactionListParams, opts ...openibank.RequestOption) *openibank.TransactionIterator
+}
+
+// List calls ListFunc.
+func (m *Transactions) List(ctx context.Context, accountID string, params *openibank.TransactionListParams, opts ...openibank.RequestOption) ([]openibank.Transaction, error) {
+	if m.ListFunc == nil {
+		panic("openibankmock: Transactions.List called but ListFunc is not set")
+	}
+	return m.ListFunc(ctx, accountID, params, opts...)
+}
+
+// Get calls GetFunc.
+func (m *Transactions) Get(ctx context.Context, accountID string, transactionID string, opts ...openibank.RequestOption) (*openibank.Transaction, error) {
+	if m.GetFunc == nil {
+		panic("openibankmock: Transactions.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(ctx, accountID, transactionID, opts...)
+}
+
+// Iter calls IterFunc.
+func (m *Transactions) Iter(ctx context.Context, accountID string, params *openibank.TransactionListParams, opts ...openibank.RequestOption) *openibank.TransactionIterator {
+	if m.IterFunc == nil {
+		panic("openibankmock: Transactions.Iter called but IterFunc is not set")
+	}
+	return m.IterFunc(ctx, accountID, params, opts...)
+}
+
+// Payments mocks openibank.PaymentsAPI.
+type Payments struct {
+	CreateFunc         func(ctx context.Context, params openibank.PaymentCreateParams, opts ...openibank.RequestOption) (*openibank.Payment, error)
+	GetQuoteFunc       func(ctx context.Context, params openibank.QuoteParams) (*openibank.Quote, error)
+	UploadBulkFileFunc func(ctx context.Context, r io.Reader, opts ...openibank.RequestOption) (*openibank.BulkPayment, error)
+	GetBulkFunc        func(ctx context.Context, bulkID string) (*openibank.BulkPayment, error)
+	GetLimitsFunc      func(ctx context.Context, debtorAccountID string) (*openibank.PaymentLimits, error)
+	GetFunc            func(ctx context.Context, paymentID string) (*openibank.Payment, error)
+	ListFunc           func(ctx context.Context, params *openibank.PaymentListParams) ([]openibank.Payment, error)
+	CancelFunc         func(ctx context.Context, paymentID string) (*openibank.Payment, error)
+	RefundFunc         func(ctx context.Context, paymentID string, params openibank.RefundParams, opts ...openibank.RequestOption) (*openibank.Refund, error)
+	ListRefundsFunc    func(ctx context.Context, paymentID string) ([]openibank.Refund, error)
+	WatchFunc          func(ctx context.Context, paymentID string, opts ...openibank.WatchOption) (<-chan openibank.PaymentEvent, error)
+}
+
+// Create calls CreateFunc.
+func (m *Payments) Create(ctx context.Context, params openibank.PaymentCreateParams, opts ...openibank.RequestOption) (*openibank.Payment, error) {
+	if m.CreateFunc == nil {
+		panic("openibankmock: Payments.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(ctx, params, opts...)
+}
+
+// GetQuote calls GetQuoteFunc.
+func (m *Payments) GetQuote(ctx context.Context, params openibank.QuoteParams) (*openibank.Quote, error) {
+	if m.GetQuoteFunc == nil {
+		panic("openibankmock: Payments.GetQuote called but GetQuoteFunc is not set")
+	}
+	return m.GetQuoteFunc(ctx, params)
+}
+
+// UploadBulkFile calls UploadBulkFileFunc.
+func (m *Payments) UploadBulkFile(ctx context.Context, r io.Reader, opts ...openibank.RequestOption) (*openibank.BulkPayment, error) {
+	if m.UploadBulkFileFunc == nil {
+		panic("openibankmock: Payments.UploadBulkFile called but UploadBulkFileFunc is not set")
+	}
+	return m.UploadBulkFileFunc(ctx, r, opts...)
+}
+
+// GetBulk calls GetBulkFunc.
+func (m *Payments) GetBulk(ctx context.Context, bulkID string) (*openibank.BulkPayment, error) {
+	if m.GetBulkFunc == nil {
+		panic("openibankmock: Payments.GetBulk called but GetBulkFunc is not set")
+	}
+	return m.GetBulkFunc(ctx, bulkID)
+}
+
+// GetLimits calls GetLimitsFunc.
+func (m *Payments) GetLimits(ctx context.Context, debtorAccountID string) (*openibank.PaymentLimits, error) {
+	if m.GetLimitsFunc == nil {
+		panic("openibankmock: Payments.GetLimits called but GetLimitsFunc is not set")
+	}
+	return m.GetLimitsFunc(ctx, debtorAccountID)
+}
+
+// Get calls GetFunc.
+func (m *Payments) Get(ctx context.Context, paymentID string) (*openibank.Payment, error) {
+	if m.GetFunc == nil {
+		panic("openibankmock: Payments.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(ctx, paymentID)
+}
+
+// List calls ListFunc.
+func (m *Payments) List(ctx context.Context, params *openibank.PaymentListParams) ([]openibank.Payment, error) {
+	if m.ListFunc == nil {
+		panic("openibankmock: Payments.List called but ListFunc is not set")
+	}
+	return m.ListFunc(ctx, params)
+}
+
+// Cancel calls CancelFunc.
+func (m *Payments) Cancel(ctx context.Context, paymentID string) (*openibank.Payment, error) {
+	if m.CancelFunc == nil {
+		panic("openibankmock: Payments.Cancel called but CancelFunc is not set")
+	}
+	return m.CancelFunc(ctx, paymentID)
+}
+
+// Refund calls RefundFunc.
+func (m *Payments) Refund(ctx context.Context, paymentID string, params openibank.RefundParams, opts ...openibank.RequestOption) (*openibank.Refund, error) {
+	if m.RefundFunc == nil {
+		panic("openibankmock: Payments.Refund called but RefundFunc is not set")
+	}
+	return m.RefundFunc(ctx, paymentID, params, opts...)
+}
+
+// ListRefunds calls ListRefundsFunc.
+func (m *Payments) ListRefunds(ctx context.Context, paymentID string) ([]openibank.Refund, error) {
+	if m.ListRefundsFunc == nil {
+		panic("openibankmock: Payments.ListRefunds called but ListRefundsFunc is not set")
+	}
+	return m.ListRefundsFunc(ctx, paymentID)
+}
+
+// Watch calls WatchFunc.
+func (m *Payments) Watch(ctx context.Context, paymentID string, opts ...openibank.WatchOption) (<-chan openibank.PaymentEvent, error) {
+	if m.WatchFunc == nil {
+		panic("openibankmock: Payments.Watch called but WatchFunc is not set")
+	}
+	return m.WatchFunc(ctx, paymentID, opts...)
+}
+
+// Consents mocks openibank.ConsentsAPI.
+type Consents struct {
+	CreateFunc                   func(ctx context.Context, params openibank.ConsentCreateParams) (*openibank.Consent, error)
+	GetFunc                      func(ctx context.Context, consentID string) (*openibank.Consent, error)
+	RevokeFunc                   func(ctx context.Context, consentID string) error
+	ListFunc                     func(ctx context.Context) ([]openibank.Consent, error)
+	ExtendFunc                   func(ctx context.Context, consentID string, newValidUntil time.Time) (*openibank.Consent, error)
+	RenewWithSameAccessFunc      func(ctx context.Context, consentID string, validUntil time.Time) (*openibank.Consent, error)
+	ReconfirmFunc                func(ctx context.Context, consentID string) (*openibank.Consent, error)
+	ListDueForReconfirmationFunc func(ctx context.Context, within time.Duration) ([]openibank.Consent, error)
+	AuthorizeFunc                func(ctx context.Context, consentID string, redirectURI string, state string) (string, error)
+	HandleCallbackFunc           func(ctx context.Context, r *http.Request) (*openibank.Consent, error)
+	GetHistoryFunc               func(ctx context.Context, consentID string) ([]openibank.ConsentHistoryEntry, error)
+	ListForPSUFunc               func(ctx context.Context, psuID string) ([]openibank.Consent, error)
+	RevokeManyFunc               func(ctx context.Context, ids []string) (openibank.BatchResult[struct{}], error)
+	RevokeAllForPSUFunc          func(ctx context.Context, psuID string) (openibank.BatchResult[struct{}], error)
+	GetUsageFunc                 func(ctx context.Context, consentID string) (*openibank.ConsentUsage, error)
+}
+
+// Create calls CreateFunc.
+func (m *Consents) Create(ctx context.Context, params openibank.ConsentCreateParams) (*openibank.Consent, error) {
+	if m.CreateFunc == nil {
+		panic("openibankmock: Consents.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(ctx, params)
+}
+
+// Get calls GetFunc.
+func (m *Consents) Get(ctx context.Context, consentID string) (*openibank.Consent, error) {
+	if m.GetFunc == nil {
+		panic("openibankmock: Consents.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(ctx, consentID)
+}
+
+// Revoke calls RevokeFunc.
+func (m *Consents) Revoke(ctx context.Context, consentID string) error {
+	if m.RevokeFunc == nil {
+		panic("openibankmock: Consents.Revoke called but RevokeFunc is not set")
+	}
+	return m.RevokeFunc(ctx, consentID)
+}
+
+// List calls ListFunc.
+func (m *Consents) List(ctx context.Context) ([]openibank.Consent, error) {
+	if m.ListFunc == nil {
+		panic("openibankmock: Consents.List called but ListFunc is not set")
+	}
+	return m.ListFunc(ctx)
+}
+
+// Extend calls ExtendFunc.
+func (m *Consents) Extend(ctx context.Context, consentID string, newValidUntil time.Time) (*openibank.Consent, error) {
+	if m.ExtendFunc == nil {
+		panic("openibankmock: Consents.Extend called but ExtendFunc is not set")
+	}
+	return m.ExtendFunc(ctx, consentID, newValidUntil)
+}
+
+// RenewWithSameAccess calls RenewWithSameAccessFunc.
+func (m *Consents) RenewWithSameAccess(ctx context.Context, consentID string, validUntil time.Time) (*openibank.Consent, error) {
+	if m.RenewWithSameAccessFunc == nil {
+		panic("openibankmock: Consents.RenewWithSameAccess called but RenewWithSameAccessFunc is not set")
+	}
+	return m.RenewWithSameAccessFunc(ctx, consentID, validUntil)
+}
+
+// Reconfirm calls ReconfirmFunc.
+func (m *Consents) Reconfirm(ctx context.Context, consentID string) (*openibank.Consent, error) {
+	if m.ReconfirmFunc == nil {
+		panic("openibankmock: Consents.Reconfirm called but ReconfirmFunc is not set")
+	}
+	return m.ReconfirmFunc(ctx, consentID)
+}
+
+// ListDueForReconfirmation calls ListDueForReconfirmationFunc.
+func (m *Consents) ListDueForReconfirmation(ctx context.Context, within time.Duration) ([]openibank.Consent, error) {
+	if m.ListDueForReconfirmationFunc == nil {
+		panic("openibankmock: Consents.ListDueForReconfirmation called but ListDueForReconfirmationFunc is not set")
+	}
+	return m.ListDueForReconfirmationFunc(ctx, within)
+}
+
+// Authorize calls AuthorizeFunc.
+func (m *Consents) Authorize(ctx context.Context, consentID string, redirectURI string, state string) (string, error) {
+	if m.AuthorizeFunc == nil {
+		panic("openibankmock: Consents.Authorize called but AuthorizeFunc is not set")
+	}
+	return m.AuthorizeFunc(ctx, consentID, redirectURI, state)
+}
+
+// HandleCallback calls HandleCallbackFunc.
+func (m *Consents) HandleCallback(ctx context.Context, r *http.Request) (*openibank.Consent, error) {
+	if m.HandleCallbackFunc == nil {
+		panic("openibankmock: Consents.HandleCallback called but HandleCallbackFunc is not set")
+	}
+	return m.HandleCallbackFunc(ctx, r)
+}
+
+// GetHistory calls GetHistoryFunc.
+func (m *Consents) GetHistory(ctx context.Context, consentID string) ([]openibank.ConsentHistoryEntry, error) {
+	if m.GetHistoryFunc == nil {
+		panic("openibankmock: Consents.GetHistory called but GetHistoryFunc is not set")
+	}
+	return m.GetHistoryFunc(ctx, consentID)
+}
+
+// ListForPSU calls ListForPSUFunc.
+func (m *Consents) ListForPSU(ctx context.Context, psuID string) ([]openibank.Consent, error) {
+	if m.ListForPSUFunc == nil {
+		panic("openibankmock: Consents.ListForPSU called but ListForPSUFunc is not set")
+	}
+	return m.ListForPSUFunc(ctx, psuID)
+}
+
+// RevokeMany calls RevokeManyFunc.
+func (m *Consents) RevokeMany(ctx context.Context, ids []string) (openibank.BatchResult[struct{}], error) {
+	if m.RevokeManyFunc == nil {
+		panic("openibankmock: Consents.RevokeMany called but RevokeManyFunc is not set")
+	}
+	return m.RevokeManyFunc(ctx, ids)
+}
+
+// RevokeAllForPSU calls RevokeAllForPSUFunc.
+func (m *Consents) RevokeAllForPSU(ctx context.Context, psuID string) (openibank.BatchResult[struct{}], error) {
+	if m.RevokeAllForPSUFunc == nil {
+		panic("openibankmock: Consents.RevokeAllForPSU called but RevokeAllForPSUFunc is not set")
+	}
+	return m.RevokeAllForPSUFunc(ctx, psuID)
+}
+
+// GetUsage calls GetUsageFunc.
+func (m *Consents) GetUsage(ctx context.Context, consentID string) (*openibank.ConsentUsage, error) {
+	if m.GetUsageFunc == nil {
+		panic("openibankmock: Consents.GetUsage called but GetUsageFunc is not set")
+	}
+	return m.GetUsageFunc(ctx, consentID)
+}
+
+// Institutions mocks openibank.InstitutionsAPI.
+type Institutions struct {
+	ListFunc                  func(ctx context.Context, params *openibank.InstitutionListParams) ([]openibank.Institution, error)
+	GetFunc                   func(ctx context.Context, institutionID string) (*openibank.Institution, error)
+	LookupByBICFunc           func(ctx context.Context, code string) (*openibank.Institution, error)
+	LookupByIBANFunc          func(ctx context.Context, iban string) (*openibank.Institution, error)
+	GetSandboxCredentialsFunc func(ctx context.Context, institutionID string) ([]openibank.SandboxCredential, error)
+	SupportsSchemeFunc        func(ctx context.Context, institutionID string, scheme openibank.Scheme) (bool, error)
+	CachedListFunc            func(ctx context.Context) ([]openibank.Institution, error)
+	RefreshCatalogFunc        func(ctx context.Context) error
+	DownloadLogoFunc          func(ctx context.Context, institutionID string, size openibank.LogoSize) (*openibank.Logo, error)
+}
+
+// List calls ListFunc.
+func (m *Institutions) List(ctx context.Context, params *openibank.InstitutionListParams) ([]openibank.Institution, error) {
+	if m.ListFunc == nil {
+		panic("openibankmock: Institutions.List called but ListFunc is not set")
+	}
+	return m.ListFunc(ctx, params)
+}
+
+// Get calls GetFunc.
+func (m *Institutions) Get(ctx context.Context, institutionID string) (*openibank.Institution, error) {
+	if m.GetFunc == nil {
+		panic("openibankmock: Institutions.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(ctx, institutionID)
+}
+
+// LookupByBIC calls LookupByBICFunc.
+func (m *Institutions) LookupByBIC(ctx context.Context, code string) (*openibank.Institution, error) {
+	if m.LookupByBICFunc == nil {
+		panic("openibankmock: Institutions.LookupByBIC called but LookupByBICFunc is not set")
+	}
+	return m.LookupByBICFunc(ctx, code)
+}
+
+// LookupByIBAN calls LookupByIBANFunc.
+func (m *Institutions) LookupByIBAN(ctx context.Context, iban string) (*openibank.Institution, error) {
+	if m.LookupByIBANFunc == nil {
+		panic("openibankmock: Institutions.LookupByIBAN called but LookupByIBANFunc is not set")
+	}
+	return m.LookupByIBANFunc(ctx, iban)
+}
+
+// GetSandboxCredentials calls GetSandboxCredentialsFunc.
+func (m *Institutions) GetSandboxCredentials(ctx context.Context, institutionID string) ([]openibank.SandboxCredential, error) {
+	if m.GetSandboxCredentialsFunc == nil {
+		panic("openibankmock: Institutions.GetSandboxCredentials called but GetSandboxCredentialsFunc is not set")
+	}
+	return m.GetSandboxCredentialsFunc(ctx, institutionID)
+}
+
+// SupportsScheme calls SupportsSchemeFunc.
+func (m *Institutions) SupportsScheme(ctx context.Context, institutionID string, scheme openibank.Scheme) (bool, error) {
+	if m.SupportsSchemeFunc == nil {
+		panic("openibankmock: Institutions.SupportsScheme called but SupportsSchemeFunc is not set")
+	}
+	return m.SupportsSchemeFunc(ctx, institutionID, scheme)
+}
+
+// CachedList calls CachedListFunc.
+func (m *Institutions) CachedList(ctx context.Context) ([]openibank.Institution, error) {
+	if m.CachedListFunc == nil {
+		panic("openibankmock: Institutions.CachedList called but CachedListFunc is not set")
+	}
+	return m.CachedListFunc(ctx)
+}
+
+// RefreshCatalog calls RefreshCatalogFunc.
+func (m *Institutions) RefreshCatalog(ctx context.Context) error {
+	if m.RefreshCatalogFunc == nil {
+		panic("openibankmock: Institutions.RefreshCatalog called but RefreshCatalogFunc is not set")
+	}
+	return m.RefreshCatalogFunc(ctx)
+}
+
+// DownloadLogo calls DownloadLogoFunc.
+func (m *Institutions) DownloadLogo(ctx context.Context, institutionID string, size openibank.LogoSize) (*openibank.Logo, error) {
+	if m.DownloadLogoFunc == nil {
+		panic("openibankmock: Institutions.DownloadLogo called but DownloadLogoFunc is not set")
+	}
+	return m.DownloadLogoFunc(ctx, institutionID, size)
+}
+
+// Auth mocks openibank.AuthAPI.
+type Auth struct {
+	GetAuthorizationURLFunc func(redirectURI string, scopes []string, state string) string
+	ExchangeCodeFunc        func(ctx context.Context, params openibank.ExchangeCodeParams) (*openibank.TokenResponse, error)
+	RefreshTokenFunc        func(ctx context.Context, refreshToken string) (*openibank.TokenResponse, error)
+}
+
+// GetAuthorizationURL calls GetAuthorizationURLFunc.
+func (m *Auth) GetAuthorizationURL(redirectURI string, scopes []string, state string) string {
+	if m.GetAuthorizationURLFunc == nil {
+		panic("openibankmock: Auth.GetAuthorizationURL called but GetAuthorizationURLFunc is not set")
+	}
+	return m.GetAuthorizationURLFunc(redirectURI, scopes, state)
+}
+
+// ExchangeCode calls ExchangeCodeFunc.
+func (m *Auth) ExchangeCode(ctx context.Context, params openibank.ExchangeCodeParams) (*openibank.TokenResponse, error) {
+	if m.ExchangeCodeFunc == nil {
+		panic("openibankmock: Auth.ExchangeCode called but ExchangeCodeFunc is not set")
+	}
+	return m.ExchangeCodeFunc(ctx, params)
+}
+
+// RefreshToken calls RefreshTokenFunc.
+func (m *Auth) RefreshToken(ctx context.Context, refreshToken string) (*openibank.TokenResponse, error) {
+	if m.RefreshTokenFunc == nil {
+		panic("openibankmock: Auth.RefreshToken called but RefreshTokenFunc is not set")
+	}
+	return m.RefreshTokenFunc(ctx, refreshToken)
+}
+
+// Realtime mocks openibank.RealtimeAPI.
+type Realtime struct {
+	SubscribeFunc     func(ctx context.Context, params openibank.SubscribeParams) (*openibank.Subscription, error)
+	EventsFunc        func(ctx context.Context, params openibank.SubscribeParams) (<-chan openibank.Event, error)
+	ReplayFunc        func(ctx context.Context, params openibank.ReplayParams) error
+	SimulateEventFunc func(ctx context.Context, event openibank.Event) (string, error)
+	SubscribeSSEFunc  func(ctx context.Context, params openibank.SubscribeParams) (*openibank.Subscription, error)
+}
+
+// Subscribe calls SubscribeFunc.
+func (m *Realtime) Subscribe(ctx context.Context, params openibank.SubscribeParams) (*openibank.Subscription, error) {
+	if m.SubscribeFunc == nil {
+		panic("openibankmock: Realtime.Subscribe called but SubscribeFunc is not set")
+	}
+	return m.SubscribeFunc(ctx, params)
+}
+
+// Events calls EventsFunc.
+func (m *Realtime) Events(ctx context.Context, params openibank.SubscribeParams) (<-chan openibank.Event, error) {
+	if m.EventsFunc == nil {
+		panic("openibankmock: Realtime.Events called but EventsFunc is not set")
+	}
+	return m.EventsFunc(ctx, params)
+}
+
+// Replay calls ReplayFunc.
+func (m *Realtime) Replay(ctx context.Context, params openibank.ReplayParams) error {
+	if m.ReplayFunc == nil {
+		panic("openibankmock: Realtime.Replay called but ReplayFunc is not set")
+	}
+	return m.ReplayFunc(ctx, params)
+}
+
+// SimulateEvent calls SimulateEventFunc.
+func (m *Realtime) SimulateEvent(ctx context.Context, event openibank.Event) (string, error) {
+	if m.SimulateEventFunc == nil {
+		panic("openibankmock: Realtime.SimulateEvent called but SimulateEventFunc is not set")
+	}
+	return m.SimulateEventFunc(ctx, event)
+}
+
+// SubscribeSSE calls SubscribeSSEFunc.
+func (m *Realtime) SubscribeSSE(ctx context.Context, params openibank.SubscribeParams) (*openibank.Subscription, error) {
+	if m.SubscribeSSEFunc == nil {
+		panic("openibankmock: Realtime.SubscribeSSE called but SubscribeSSEFunc is not set")
+	}
+	return m.SubscribeSSEFunc(ctx, params)
+}
+
+// Webhooks mocks openibank.WebhooksAPI.
+type Webhooks struct {
+	CreateFunc                     func(ctx context.Context, params openibank.WebhookCreateParams) (*openibank.WebhookEndpoint, error)
+	GetFunc                        func(ctx context.Context, webhookID string) (*openibank.WebhookEndpoint, error)
+	ListFunc                       func(ctx context.Context) ([]openibank.WebhookEndpoint, error)
+	UpdateFunc                     func(ctx context.Context, webhookID string, params openibank.WebhookUpdateParams) (*openibank.WebhookEndpoint, error)
+	DeleteFunc                     func(ctx context.Context, webhookID string) error
+	RotateSecretFunc               func(ctx context.Context, webhookID string) (*openibank.WebhookSecret, error)
+	ListDeliveriesFunc             func(ctx context.Context, webhookID string, params *openibank.WebhookDeliveryListParams) ([]openibank.WebhookDelivery, error)
+	GetDeliveryFunc                func(ctx context.Context, webhookID string, deliveryID string) (*openibank.WebhookDelivery, error)
+	RedeliverFunc                  func(ctx context.Context, webhookID string, eventID string) (*openibank.WebhookDelivery, error)
+	ReplayRangeFunc                func(ctx context.Context, webhookID string, from time.Time, to time.Time) (*openibank.WebhookReplay, error)
+	ListFailedFunc                 func(ctx context.Context, webhookID string) ([]openibank.FailedWebhookEvent, error)
+	AcknowledgeFailedFunc          func(ctx context.Context, webhookID string, eventID string) error
+	CreateRelayFunc                func(ctx context.Context, events []openibank.EventType) (*openibank.WebhookRelay, error)
+	NextRelayedDeliveriesFunc      func(ctx context.Context, relayID string, wait time.Duration) ([]openibank.RelayedDelivery, error)
+	AcknowledgeRelayedDeliveryFunc func(ctx context.Context, relayID string, deliveryID string, statusCode int) error
+	DeleteRelayFunc                func(ctx context.Context, relayID string) error
+}
+
+// Create calls CreateFunc.
+func (m *Webhooks) Create(ctx context.Context, params openibank.WebhookCreateParams) (*openibank.WebhookEndpoint, error) {
+	if m.CreateFunc == nil {
+		panic("openibankmock: Webhooks.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(ctx, params)
+}
+
+// Get calls GetFunc.
+func (m *Webhooks) Get(ctx context.Context, webhookID string) (*openibank.WebhookEndpoint, error) {
+	if m.GetFunc == nil {
+		panic("openibankmock: Webhooks.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(ctx, webhookID)
+}
+
+// List calls ListFunc.
+func (m *Webhooks) List(ctx context.Context) ([]openibank.WebhookEndpoint, error) {
+	if m.ListFunc == nil {
+		panic("openibankmock: Webhooks.List called but ListFunc is not set")
+	}
+	return m.ListFunc(ctx)
+}
+
+// Update calls UpdateFunc.
+func (m *Webhooks) Update(ctx context.Context, webhookID string, params openibank.WebhookUpdateParams) (*openibank.WebhookEndpoint, error) {
+	if m.UpdateFunc == nil {
+		panic("openibankmock: Webhooks.Update called but UpdateFunc is not set")
+	}
+	return m.UpdateFunc(ctx, webhookID, params)
+}
+
+// Delete calls DeleteFunc.
+func (m *Webhooks) Delete(ctx context.Context, webhookID string) error {
+	if m.DeleteFunc == nil {
+		panic("openibankmock: Webhooks.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(ctx, webhookID)
+}
+
+// RotateSecret calls RotateSecretFunc.
+func (m *Webhooks) RotateSecret(ctx context.Context, webhookID string) (*openibank.WebhookSecret, error) {
+	if m.RotateSecretFunc == nil {
+		panic("openibankmock: Webhooks.RotateSecret called but RotateSecretFunc is not set")
+	}
+	return m.RotateSecretFunc(ctx, webhookID)
+}
+
+// ListDeliveries calls ListDeliveriesFunc.
+func (m *Webhooks) ListDeliveries(ctx context.Context, webhookID string, params *openibank.WebhookDeliveryListParams) ([]openibank.WebhookDelivery, error) {
+	if m.ListDeliveriesFunc == nil {
+		panic("openibankmock: Webhooks.ListDeliveries called but ListDeliveriesFunc is not set")
+	}
+	return m.ListDeliveriesFunc(ctx, webhookID, params)
+}
+
+// GetDelivery calls GetDeliveryFunc.
+func (m *Webhooks) GetDelivery(ctx context.Context, webhookID string, deliveryID string) (*openibank.WebhookDelivery, error) {
+	if m.GetDeliveryFunc == nil {
+		panic("openibankmock: Webhooks.GetDelivery called but GetDeliveryFunc is not set")
+	}
+	return m.GetDeliveryFunc(ctx, webhookID, deliveryID)
+}
+
+// Redeliver calls RedeliverFunc.
+func (m *Webhooks) Redeliver(ctx context.Context, webhookID string, eventID string) (*openibank.WebhookDelivery, error) {
+	if m.RedeliverFunc == nil {
+		panic("openibankmock: Webhooks.Redeliver called but RedeliverFunc is not set")
+	}
+	return m.RedeliverFunc(ctx, webhookID, eventID)
+}
+
+// ReplayRange calls ReplayRangeFunc.
+func (m *Webhooks) ReplayRange(ctx context.Context, webhookID string, from time.Time, to time.Time) (*openibank.WebhookReplay, error) {
+	if m.ReplayRangeFunc == nil {
+		panic("openibankmock: Webhooks.ReplayRange called but ReplayRangeFunc is not set")
+	}
+	return m.ReplayRangeFunc(ctx, webhookID, from, to)
+}
+
+// ListFailed calls ListFailedFunc.
+func (m *Webhooks) ListFailed(ctx context.Context, webhookID string) ([]openibank.FailedWebhookEvent, error) {
+	if m.ListFailedFunc == nil {
+		panic("openibankmock: Webhooks.ListFailed called but ListFailedFunc is not set")
+	}
+	return m.ListFailedFunc(ctx, webhookID)
+}
+
+// AcknowledgeFailed calls AcknowledgeFailedFunc.
+func (m *Webhooks) AcknowledgeFailed(ctx context.Context, webhookID string, eventID string) error {
+	if m.AcknowledgeFailedFunc == nil {
+		panic("openibankmock: Webhooks.AcknowledgeFailed called but AcknowledgeFailedFunc is not set")
+	}
+	return m.AcknowledgeFailedFunc(ctx, webhookID, eventID)
+}
+
+// CreateRelay calls CreateRelayFunc.
+func (m *Webhooks) CreateRelay(ctx context.Context, events []openibank.EventType) (*openibank.WebhookRelay, error) {
+	if m.CreateRelayFunc == nil {
+		panic("openibankmock: Webhooks.CreateRelay called but CreateRelayFunc is not set")
+	}
+	return m.CreateRelayFunc(ctx, events)
+}
+
+// NextRelayedDeliveries calls NextRelayedDeliveriesFunc.
+func (m *Webhooks) NextRelayedDeliveries(ctx context.Context, relayID string, wait time.Duration) ([]openibank.RelayedDelivery, error) {
+	if m.NextRelayedDeliveriesFunc == nil {
+		panic("openibankmock: Webhooks.NextRelayedDeliveries called but NextRelayedDeliveriesFunc is not set")
+	}
+	return m.NextRelayedDeliveriesFunc(ctx, relayID, wait)
+}
+
+// AcknowledgeRelayedDelivery calls AcknowledgeRelayedDeliveryFunc.
+func (m *Webhooks) AcknowledgeRelayedDelivery(ctx context.Context, relayID string, deliveryID string, statusCode int) error {
+	if m.AcknowledgeRelayedDeliveryFunc == nil {
+		panic("openibankmock: Webhooks.AcknowledgeRelayedDelivery called but AcknowledgeRelayedDeliveryFunc is not set")
+	}
+	return m.AcknowledgeRelayedDeliveryFunc(ctx, relayID, deliveryID, statusCode)
+}
+
+// DeleteRelay calls DeleteRelayFunc.
+func (m *Webhooks) DeleteRelay(ctx context.Context, relayID string) error {
+	if m.DeleteRelayFunc == nil {
+		panic("openibankmock: Webhooks.DeleteRelay called but DeleteRelayFunc is not set")
+	}
+	return m.DeleteRelayFunc(ctx, relayID)
+}
+
+// Sandbox mocks openibank.SandboxAPI.
+type Sandbox struct {
+	SimulatePaymentStatusFunc func(ctx context.Context, paymentID string, status openibank.PaymentStatus, reason openibank.ReasonCode) (*openibank.Payment, error)
+	ExpireConsentFunc         func(ctx context.Context, consentID string) (*openibank.Consent, error)
+	RevokeConsentAsPSUFunc    func(ctx context.Context, consentID string) (*openibank.Consent, error)
+	ResetFunc                 func(ctx context.Context) error
+}
+
+// SimulatePaymentStatus calls SimulatePaymentStatusFunc.
+func (m *Sandbox) SimulatePaymentStatus(ctx context.Context, paymentID string, status openibank.PaymentStatus, reason openibank.ReasonCode) (*openibank.Payment, error) {
+	if m.SimulatePaymentStatusFunc == nil {
+		panic("openibankmock: Sandbox.SimulatePaymentStatus called but SimulatePaymentStatusFunc is not set")
+	}
+	return m.SimulatePaymentStatusFunc(ctx, paymentID, status, reason)
+}
+
+// ExpireConsent calls ExpireConsentFunc.
+func (m *Sandbox) ExpireConsent(ctx context.Context, consentID string) (*openibank.Consent, error) {
+	if m.ExpireConsentFunc == nil {
+		panic("openibankmock: Sandbox.ExpireConsent called but ExpireConsentFunc is not set")
+	}
+	return m.ExpireConsentFunc(ctx, consentID)
+}
+
+// RevokeConsentAsPSU calls RevokeConsentAsPSUFunc.
+func (m *Sandbox) RevokeConsentAsPSU(ctx context.Context, consentID string) (*openibank.Consent, error) {
+	if m.RevokeConsentAsPSUFunc == nil {
+		panic("openibankmock: Sandbox.RevokeConsentAsPSU called but RevokeConsentAsPSUFunc is not set")
+	}
+	return m.RevokeConsentAsPSUFunc(ctx, consentID)
+}
+
+// Reset calls ResetFunc.
+func (m *Sandbox) Reset(ctx context.Context) error {
+	if m.ResetFunc == nil {
+		panic("openibankmock: Sandbox.Reset called but ResetFunc is not set")
+	}
+	return m.ResetFunc(ctx)
+}
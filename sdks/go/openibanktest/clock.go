@@ -0,0 +1,114 @@
+package openibanktest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	openibank "github.com/openibank/sdk-go"
+)
+
+var _ openibank.Clock = (*Clock)(nil)
+
+// Clock is a fake openibank.Clock whose time only moves when a test
+// advances it, so that token expiry and waits between retries can be tested
+// without waiting:
+//
+//	clock := openibanktest.NewClock(time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC))
+//	client := srv.Client(openibank.WithClock(clock))
+//	go func() {
+//	    clock.BlockUntil(1) // the client waits before retrying
+//	    clock.Advance(time.Second)
+//	}()
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []waiter
+	changed chan struct{}
+}
+
+type waiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewClock returns a Clock set to now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now, changed: make(chan struct{})}
+}
+
+// Now returns the clock's time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives the clock's time once it has been
+// advanced by d.
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, waiter{at: c.now.Add(d), ch: ch})
+	c.notify()
+	return ch
+}
+
+// Advance moves the clock forward by d, and releases the waits that end
+// by the new time, in the order they end.
+func (c *Clock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to t, and releases the waits that end by t, in the
+// order they end. The clock does not move backwards.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.Before(c.now) {
+		return
+	}
+	sort.SliceStable(c.waiters, func(i, j int) bool { return c.waiters[i].at.Before(c.waiters[j].at) })
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(t) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- w.at
+	}
+	c.waiters = pending
+	c.now = t
+	c.notify()
+}
+
+// Waiters returns the number of waits that have not ended.
+func (c *Clock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil blocks until at least n waits have not ended, such as until
+// the client waits before retrying a request.
+func (c *Clock) BlockUntil(n int) {
+	c.mu.Lock()
+	for len(c.waiters) < n {
+		changed := c.changed
+		c.mu.Unlock()
+		<-changed
+		c.mu.Lock()
+	}
+	c.mu.Unlock()
+}
+
+// notify wakes the callers of BlockUntil. The caller must hold c.mu.
+func (c *Clock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
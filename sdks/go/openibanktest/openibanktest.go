@@ -0,0 +1,653 @@
+// Package openibanktest provides an in-process implementation of the REST
+// API for integration tests that run without the sandbox. A Server holds
+// the accounts, transactions, payments and consents a test seeds, issues OAuth tokens
+// for its client credentials, and pages lists in the order items were
+// added, so tests are hermetic and deterministic.
+//
+// Example usage:
+//
+//	srv := openibanktest.NewServer()
+//	defer srv.Close()
+//	srv.AddAccount(openibank.Account{ID: "acc_1", Name: "Main", Currency: "EUR"})
+//	srv.AddTransactions("acc_1", openibank.Transaction{ID: "tx_1", Amount: "-12.50", Currency: "EUR"})
+//
+//	client := srv.Client()
+//	transactions, err := client.Transactions.List(ctx, "acc_1", nil)
+package openibanktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	openibank "github.com/openibank/sdk-go"
+)
+
+// Default client credentials accepted by a Server.
+const (
+	DefaultClientID     = "test_client_id"
+	DefaultClientSecret = "test_client_secret"
+)
+
+// tokenLifetime is the lifetime of the access tokens a Server issues, in
+// seconds.
+const tokenLifetime = 3600
+
+// Server is an in-process implementation of the REST API. Create one with
+// NewServer and close it when the test ends.
+type Server struct {
+	// URL is the base URL of the server, for use WithBaseURL.
+	URL string
+	// ClientID and ClientSecret are the credentials the token endpoint
+	// accepts.
+	ClientID     string
+	ClientSecret string
+
+	server *httptest.Server
+
+	mu           sync.Mutex
+	accounts     []*openibank.Account
+	balances     map[string]openibank.Balances
+	transactions map[string][]*openibank.Transaction
+	payments     []*openibank.Payment
+	consents     []*openibank.Consent
+	idempotency  map[string]*openibank.Payment
+	tokens       map[string]bool
+	ids          map[string]int
+}
+
+// NewServer starts a Server with no data, accepting DefaultClientID and
+// DefaultClientSecret.
+func NewServer() *Server {
+	s := &Server{
+		ClientID:     DefaultClientID,
+		ClientSecret: DefaultClientSecret,
+		tokens:       make(map[string]bool),
+	}
+	s.reset()
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a client configured to use the server with its client
+// credentials. Retries are disabled unless opts enable them.
+func (s *Server) Client(opts ...openibank.Option) *openibank.Client {
+	opts = append([]openibank.Option{
+		openibank.WithBaseURL(s.URL),
+		openibank.WithClientCredentials(s.ClientID, s.ClientSecret),
+		openibank.WithMaxRetries(0),
+	}, opts...)
+	return openibank.NewClient(opts...)
+}
+
+// AddAccount adds an account. An account with the ID of an existing one
+// replaces it. The account's balance, if set, is also returned as its
+// interimAvailable balance.
+func (s *Server) AddAccount(account openibank.Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if account.ID == "" {
+		account.ID = s.newID("acc")
+	}
+	if account.Status == "" {
+		account.Status = openibank.AccountStatusActive
+	}
+	if account.Balance != nil {
+		balance := *account.Balance
+		if balance.Type == "" {
+			balance.Type = openibank.BalanceTypeInterimAvailable
+		}
+		s.balances[account.ID] = openibank.Balances{balance}
+	}
+	for i, a := range s.accounts {
+		if a.ID == account.ID {
+			s.accounts[i] = &account
+			return
+		}
+	}
+	s.accounts = append(s.accounts, &account)
+}
+
+// SetBalances sets the balances returned for an account.
+func (s *Server) SetBalances(accountID string, balances ...openibank.Balance) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balances[accountID] = balances
+}
+
+// AddTransactions adds transactions to an account, after those added
+// before. Transactions without an ID, account ID or status are given one.
+func (s *Server) AddTransactions(accountID string, transactions ...openibank.Transaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range transactions {
+		t := t
+		if t.ID == "" {
+			t.ID = s.newID("tx")
+		}
+		t.AccountID = accountID
+		if t.Status == "" {
+			t.Status = openibank.TransactionStatusBooked
+		}
+		s.transactions[accountID] = append(s.transactions[accountID], &t)
+	}
+}
+
+// AddPayment adds a payment, as if it had been created before the test.
+func (s *Server) AddPayment(payment openibank.Payment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if payment.ID == "" {
+		payment.ID = s.newID("pay")
+	}
+	if payment.Status == "" {
+		payment.Status = openibank.PaymentStatusReceived
+	}
+	s.payments = append(s.payments, &payment)
+}
+
+// Payments returns the payments on the server, including those created
+// through the API, in the order they were added.
+func (s *Server) Payments() []openibank.Payment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	payments := make([]openibank.Payment, len(s.payments))
+	for i, p := range s.payments {
+		payments[i] = *p
+	}
+	return payments
+}
+
+// AddConsent adds a consent, as if it had been authorized before the test.
+// Consents without an ID or status are given one; the status is valid.
+func (s *Server) AddConsent(consent openibank.Consent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if consent.ID == "" {
+		consent.ID = s.newID("con")
+	}
+	if consent.Status == "" {
+		consent.Status = openibank.ConsentStatusValid
+	}
+	s.consents = append(s.consents, &consent)
+}
+
+// Consents returns the consents on the server, in the order they were
+// added.
+func (s *Server) Consents() []openibank.Consent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	consents := make([]openibank.Consent, len(s.consents))
+	for i, c := range s.consents {
+		consents[i] = *c
+	}
+	return consents
+}
+
+// Reset deletes the server's accounts, balances, transactions, payments and
+// consents, and numbers IDs from 1 again, as the sandbox reset endpoint
+// does. Issued access tokens stay valid.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reset()
+}
+
+// reset deletes the server's data. The caller must hold s.mu.
+func (s *Server) reset() {
+	s.accounts = nil
+	s.balances = make(map[string]openibank.Balances)
+	s.transactions = make(map[string][]*openibank.Transaction)
+	s.payments = nil
+	s.consents = nil
+	s.idempotency = make(map[string]*openibank.Payment)
+	s.ids = make(map[string]int)
+}
+
+// newID returns the next ID with prefix, such as "pay_000001". IDs are
+// numbered per prefix. The caller must hold s.mu.
+func (s *Server) newID(prefix string) string {
+	s.ids[prefix]++
+	return fmt.Sprintf("%s_%06d", prefix, s.ids[prefix])
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/oauth/token" {
+		s.handleToken(w, r)
+		return
+	}
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, openibank.ErrCodeInvalidToken, "invalid or missing access token")
+		return
+	}
+
+	// Paths are /{version}/{resource}/...; any version is served.
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 {
+		writeNotFound(w, "", "")
+		return
+	}
+	route := parts[1:]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case route[0] == "accounts":
+		s.routeAccounts(w, r, route[1:])
+	case route[0] == "payments":
+		s.routePayments(w, r, route[1:])
+	case route[0] == "consents":
+		s.routeConsents(w, r, route[1:])
+	case route[0] == "sandbox":
+		s.routeSandbox(w, r, route[1:])
+	default:
+		writeNotFound(w, "", "")
+	}
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, openibank.ErrCodeInvalidRequest, "method not allowed")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, openibank.ErrCodeInvalidRequest, "invalid form")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "client_credentials" {
+		writeError(w, http.StatusBadRequest, openibank.ErrCodeInvalidRequest, "unsupported grant type")
+		return
+	}
+	if r.PostForm.Get("client_id") != s.ClientID || r.PostForm.Get("client_secret") != s.ClientSecret {
+		writeError(w, http.StatusUnauthorized, openibank.ErrCodeInvalidToken, "invalid client credentials")
+		return
+	}
+
+	s.mu.Lock()
+	token := s.newID("tok")
+	s.tokens[token] = true
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, openibank.TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   tokenLifetime,
+	})
+}
+
+// authorized reports whether r carries an access token the server issued.
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[token]
+}
+
+func (s *Server) routeAccounts(w http.ResponseWriter, r *http.Request, route []string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, openibank.ErrCodeInvalidRequest, "method not allowed")
+		return
+	}
+	if len(route) == 0 {
+		s.listAccounts(w, r)
+		return
+	}
+	account := s.account(route[0])
+	if account == nil {
+		writeNotFound(w, "account", route[0])
+		return
+	}
+	switch {
+	case len(route) == 1:
+		writeJSON(w, http.StatusOK, account)
+	case len(route) == 2 && route[1] == "balances":
+		balances := s.balances[account.ID]
+		if balances == nil {
+			balances = openibank.Balances{}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"balances": balances})
+	case len(route) == 2 && route[1] == "transactions":
+		s.listTransactions(w, r, account.ID)
+	case len(route) == 3 && route[1] == "transactions":
+		for _, t := range s.transactions[account.ID] {
+			if t.ID == route[2] {
+				writeJSON(w, http.StatusOK, t)
+				return
+			}
+		}
+		writeNotFound(w, "transaction", route[2])
+	default:
+		writeNotFound(w, "", "")
+	}
+}
+
+func (s *Server) account(id string) *openibank.Account {
+	for _, a := range s.accounts {
+		if a.ID == id {
+			return a
+		}
+	}
+	return nil
+}
+
+func (s *Server) listAccounts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	accounts := []*openibank.Account{}
+	for _, a := range s.accounts {
+		if status := query.Get("status"); status != "" && string(a.Status) != status {
+			continue
+		}
+		if accountType := query.Get("account_type"); accountType != "" && a.AccountType != accountType {
+			continue
+		}
+		accounts = append(accounts, a)
+	}
+	start, end, ok := page(w, r, len(accounts))
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"accounts": accounts[start:end]})
+}
+
+func (s *Server) listTransactions(w http.ResponseWriter, r *http.Request, accountID string) {
+	status := r.URL.Query().Get("booking_status")
+	transactions := []*openibank.Transaction{}
+	for _, t := range s.transactions[accountID] {
+		if status != "" && string(t.Status) != status {
+			continue
+		}
+		transactions = append(transactions, t)
+	}
+	start, end, ok := page(w, r, len(transactions))
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"transactions": transactions[start:end]})
+}
+
+func (s *Server) routePayments(w http.ResponseWriter, r *http.Request, route []string) {
+	switch {
+	case len(route) == 0 && r.Method == http.MethodPost:
+		s.createPayment(w, r)
+	case len(route) == 0 && r.Method == http.MethodGet:
+		s.listPayments(w, r)
+	case len(route) == 1 && r.Method == http.MethodGet:
+		payment := s.payment(route[0])
+		if payment == nil {
+			writeNotFound(w, "payment", route[0])
+			return
+		}
+		writeJSON(w, http.StatusOK, payment)
+	case len(route) == 2 && route[1] == "cancel" && r.Method == http.MethodPost:
+		payment := s.payment(route[0])
+		if payment == nil {
+			writeNotFound(w, "payment", route[0])
+			return
+		}
+		if payment.Status.IsTerminal() {
+			writeError(w, http.StatusConflict, openibank.ErrCodePaymentNotCancellable,
+				fmt.Sprintf("payment in status %s cannot be cancelled", payment.Status))
+			return
+		}
+		payment.Status = openibank.PaymentStatusCancelled
+		writeJSON(w, http.StatusOK, payment)
+	default:
+		writeNotFound(w, "", "")
+	}
+}
+
+func (s *Server) payment(id string) *openibank.Payment {
+	for _, p := range s.payments {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *Server) createPayment(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Idempotency-Key")
+	if payment, ok := s.idempotency[key]; ok && key != "" {
+		writeJSON(w, http.StatusCreated, payment)
+		return
+	}
+
+	var params openibank.PaymentCreateParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeError(w, http.StatusBadRequest, openibank.ErrCodeInvalidRequest, "invalid request body")
+		return
+	}
+	var fieldErrors []openibank.FieldError
+	if params.Creditor.Name == "" {
+		fieldErrors = append(fieldErrors, openibank.FieldError{Field: "creditor.name", Message: "is required"})
+	}
+	if params.Amount.Amount == "" {
+		fieldErrors = append(fieldErrors, openibank.FieldError{Field: "amount.amount", Message: "is required"})
+	}
+	if params.Amount.Currency == "" {
+		fieldErrors = append(fieldErrors, openibank.FieldError{Field: "amount.currency", Message: "is required"})
+	}
+	if params.DebtorAccountID == "" {
+		fieldErrors = append(fieldErrors, openibank.FieldError{Field: "debtor_account_id", Message: "is required"})
+	} else if s.account(params.DebtorAccountID) == nil {
+		fieldErrors = append(fieldErrors, openibank.FieldError{Field: "debtor_account_id", Message: "account not found"})
+	}
+	if len(fieldErrors) > 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"message": "invalid payment",
+			"code":    openibank.ErrCodeInvalidPayment,
+			"errors":  fieldErrors,
+		})
+		return
+	}
+
+	now := time.Now().UTC()
+	payment := &openibank.Payment{
+		ID:            s.newID("pay"),
+		Status:        openibank.PaymentStatusReceived,
+		Amount:        params.Amount.Amount,
+		Currency:      params.Amount.Currency,
+		CreditorName:  params.Creditor.Name,
+		CreditorIBAN:  params.Creditor.Account.IBAN,
+		Reference:     params.Reference,
+		EndToEndID:    params.EndToEndID,
+		ExecutionDate: params.ExecutionDate,
+		CreatedAt:     &now,
+	}
+	if params.Scheme != nil {
+		payment.Scheme = *params.Scheme
+		payment.RequestedScheme = params.Scheme
+	}
+	s.payments = append(s.payments, payment)
+	if key != "" {
+		s.idempotency[key] = payment
+	}
+	writeJSON(w, http.StatusCreated, payment)
+}
+
+func (s *Server) routeSandbox(w http.ResponseWriter, r *http.Request, route []string) {
+	switch {
+	case len(route) == 1 && route[0] == "reset" && r.Method == http.MethodPost:
+		s.reset()
+		w.WriteHeader(http.StatusNoContent)
+	case len(route) == 3 && route[0] == "payments" && route[2] == "status" && r.Method == http.MethodPost:
+		s.simulatePaymentStatus(w, r, route[1])
+	case len(route) == 3 && route[0] == "consents" && route[2] == "expire" && r.Method == http.MethodPost:
+		s.endConsent(w, route[1], openibank.ConsentStatusExpired)
+	case len(route) == 3 && route[0] == "consents" && route[2] == "revoke" && r.Method == http.MethodPost:
+		s.endConsent(w, route[1], openibank.ConsentStatusRevokedByPSU)
+	default:
+		writeNotFound(w, "", "")
+	}
+}
+
+// simulatePaymentStatus moves a payment to the requested status. Terminal
+// payments cannot move, except settled payments, which can be rejected to
+// simulate a return.
+func (s *Server) simulatePaymentStatus(w http.ResponseWriter, r *http.Request, paymentID string) {
+	payment := s.payment(paymentID)
+	if payment == nil {
+		writeNotFound(w, "payment", paymentID)
+		return
+	}
+	var body struct {
+		Status openibank.PaymentStatus `json:"status"`
+		Reason openibank.ReasonCode    `json:"reason_code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || !body.Status.Valid() {
+		writeError(w, http.StatusBadRequest, openibank.ErrCodeInvalidRequest, "invalid payment status")
+		return
+	}
+	returned := payment.Status.IsSuccessful() && body.Status == openibank.PaymentStatusRejected
+	if payment.Status.IsTerminal() && !returned {
+		writeError(w, http.StatusConflict, openibank.ErrCodeInvalidRequest,
+			fmt.Sprintf("payment in status %s cannot move to %s", payment.Status, body.Status))
+		return
+	}
+
+	payment.Status = body.Status
+	payment.StatusReason = nil
+	if body.Reason != "" {
+		payment.StatusReason = &openibank.StatusReason{Code: body.Reason}
+	}
+	if body.Status.IsSuccessful() {
+		now := time.Now().UTC()
+		payment.ExecutedAt = &now
+	}
+	writeJSON(w, http.StatusOK, payment)
+}
+
+func (s *Server) routeConsents(w http.ResponseWriter, r *http.Request, route []string) {
+	switch {
+	case len(route) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"consents": append([]*openibank.Consent{}, s.consents...)})
+	case len(route) == 1 && r.Method == http.MethodGet:
+		if consent := s.consent(route[0]); consent != nil {
+			writeJSON(w, http.StatusOK, consent)
+			return
+		}
+		writeNotFound(w, "consent", route[0])
+	case len(route) == 1 && r.Method == http.MethodDelete:
+		if s.consent(route[0]) == nil {
+			writeNotFound(w, "consent", route[0])
+			return
+		}
+		s.endConsent(w, route[0], openibank.ConsentStatusTerminatedByTPP)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, openibank.ErrCodeInvalidRequest, "method not allowed")
+	}
+}
+
+func (s *Server) consent(id string) *openibank.Consent {
+	for _, c := range s.consents {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// endConsent moves a consent to a terminal status. Consents already in one
+// cannot move.
+func (s *Server) endConsent(w http.ResponseWriter, consentID string, status openibank.ConsentStatus) {
+	consent := s.consent(consentID)
+	if consent == nil {
+		writeNotFound(w, "consent", consentID)
+		return
+	}
+	if consent.Status.IsTerminal() {
+		writeError(w, http.StatusConflict, openibank.ErrCodeInvalidRequest,
+			fmt.Sprintf("consent in status %s cannot move to %s", consent.Status, status))
+		return
+	}
+	consent.Status = status
+	if status == openibank.ConsentStatusExpired {
+		now := time.Now().UTC()
+		consent.ValidUntil = &now
+	}
+	if status == openibank.ConsentStatusTerminatedByTPP {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, consent)
+}
+
+func (s *Server) listPayments(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	payments := []*openibank.Payment{}
+	for _, p := range s.payments {
+		if status != "" && string(p.Status) != status {
+			continue
+		}
+		payments = append(payments, p)
+	}
+	start, end, ok := page(w, r, len(payments))
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"payments": payments[start:end]})
+}
+
+// defaultLimit is the page size when a list request has no limit.
+const defaultLimit = 100
+
+// page returns the bounds of the page of n items selected by the limit and
+// offset parameters of r. It writes an error and returns false if they are
+// invalid.
+func page(w http.ResponseWriter, r *http.Request, n int) (start, end int, ok bool) {
+	query := r.URL.Query()
+	limit, offset := defaultLimit, 0
+	var err error
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 {
+			writeError(w, http.StatusBadRequest, openibank.ErrCodeInvalidFilter, "limit must be a positive integer")
+			return 0, 0, false
+		}
+	}
+	if v := query.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			writeError(w, http.StatusBadRequest, openibank.ErrCodeInvalidFilter, "offset must be a non-negative integer")
+			return 0, 0, false
+		}
+	}
+	start = min(offset, n)
+	end = min(start+limit, n)
+	return start, end, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]interface{}{"message": message, "code": code})
+}
+
+func writeNotFound(w http.ResponseWriter, resourceType, resourceID string) {
+	body := map[string]interface{}{
+		"message": "resource not found",
+		"code":    openibank.ErrCodeResourceNotFound,
+	}
+	if resourceType != "" {
+		body["message"] = resourceType + " not found"
+		body["resource_type"] = resourceType
+		body["resource_id"] = resourceID
+	}
+	writeJSON(w, http.StatusNotFound, body)
+}
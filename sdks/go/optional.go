@@ -0,0 +1,93 @@
+package openibank
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Optional is a value that may be unset, as an alternative to the pointer
+// fields used by params and models. The zero Optional is unset. In JSON an
+// unset Optional is null, and a struct field tagged omitempty is omitted
+// when it is unset, as a nil pointer would be.
+//
+// Optional is a slice so that omitempty applies to it; use its methods
+// rather than indexing it.
+type Optional[T any] []T
+
+// Some returns an Optional set to v.
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{v}
+}
+
+// OptionalFromPtr returns an Optional set to *p, or an unset one if p is
+// nil.
+func OptionalFromPtr[T any](p *T) Optional[T] {
+	if p == nil {
+		return nil
+	}
+	return Some(*p)
+}
+
+// Set sets the value.
+func (o *Optional[T]) Set(v T) {
+	*o = Optional[T]{v}
+}
+
+// Unset clears the value.
+func (o *Optional[T]) Unset() {
+	*o = nil
+}
+
+// IsSet reports whether the value is set.
+func (o Optional[T]) IsSet() bool {
+	return len(o) > 0
+}
+
+// Get returns the value and whether it is set.
+func (o Optional[T]) Get() (T, bool) {
+	if len(o) == 0 {
+		var zero T
+		return zero, false
+	}
+	return o[0], true
+}
+
+// OrElse returns the value, or fallback if it is not set.
+func (o Optional[T]) OrElse(fallback T) T {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return fallback
+}
+
+// Ptr returns a pointer to a copy of the value, or nil if it is not set, for
+// use with pointer fields.
+func (o Optional[T]) Ptr() *T {
+	v, ok := o.Get()
+	if !ok {
+		return nil
+	}
+	return &v
+}
+
+// MarshalJSON encodes the value, or null if it is not set.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if len(o) == 0 {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o[0])
+}
+
+// UnmarshalJSON decodes a value, leaving the Optional unset for null.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*o = nil
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*o = Optional[T]{v}
+	return nil
+}
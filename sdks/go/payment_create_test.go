@@ -0,0 +1,50 @@
+package openibank_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	openibank "github.com/openibank/sdk-go"
+)
+
+// failExecutor fails the test if the client sends a request.
+type failExecutor struct {
+	t *testing.T
+}
+
+func (e failExecutor) Execute(ctx context.Context, call *openibank.Call) (*openibank.Response, error) {
+	e.t.Errorf("unexpected request %s %s", call.Method, call.Path)
+	return nil, errors.New("unexpected request")
+}
+
+func TestCreatePaymentRejectsInvalidIBAN(t *testing.T) {
+	client := openibank.NewClient(openibank.WithExecutor(failExecutor{t}))
+	tests := []struct {
+		iban, message string
+	}{
+		{"DE89370400440532013001", "has an invalid checksum"},
+		{"DE8937040044053201300", "has the wrong length for its country"},
+		{"XX89370400440532013000", "has a country code that does not use IBANs"},
+		{"DE89 3704 0044 0532 0130 0!", "must contain only letters and digits"},
+	}
+	for _, tt := range tests {
+		_, err := client.Payments.Create(context.Background(), openibank.PaymentCreateParams{
+			Creditor: openibank.Creditor{
+				Name:    "Jane Doe",
+				Account: openibank.CreditorAccount{IBAN: openibank.String(tt.iban)},
+			},
+			Amount:          openibank.Amount{Amount: "10.00", Currency: "EUR"},
+			DebtorAccountID: "acc_123",
+		})
+		var verr *openibank.ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("Create(%q) error = %v, want *ValidationError", tt.iban, err)
+			continue
+		}
+		if verr.Code != openibank.ErrCodeInvalidIBAN || len(verr.Errors) != 1 ||
+			verr.Errors[0].Field != "creditor.account.iban" || verr.Errors[0].Message != tt.message {
+			t.Errorf("Create(%q) error = %+v, want %q on creditor.account.iban", tt.iban, verr, tt.message)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package openibank
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPaymentLimitsCheck(t *testing.T) {
+	limits := &PaymentLimits{Currency: "EUR", PerTransaction: String("1000.00"), DailyRemaining: String("250.00")}
+	tests := []struct {
+		amount string
+		code   string
+	}{
+		{"100.00", ""},
+		{"250", ""},
+		{"250.01", ErrCodeLimitExceeded},
+		{"1000.01", ErrCodeLimitExceeded},
+		{"1/2", ErrCodeInvalidAmount},
+		{"1e3", ErrCodeInvalidAmount},
+		{"0x10", ErrCodeInvalidAmount},
+		{"1.000001", ErrCodeInvalidAmount},
+		{"0", ErrCodeInvalidAmount},
+		{"-5.00", ErrCodeInvalidAmount},
+		{"", ErrCodeInvalidAmount},
+	}
+	for _, tt := range tests {
+		err := limits.Check(tt.amount)
+		if tt.code == "" {
+			if err != nil {
+				t.Errorf("Check(%q) = %v, want nil", tt.amount, err)
+			}
+			continue
+		}
+		var verr *ValidationError
+		if !errors.As(err, &verr) || verr.Code != tt.code {
+			t.Errorf("Check(%q) = %v, want a ValidationError with code %s", tt.amount, err, tt.code)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+// Package payments provides builders for payment payloads that validate
+// fields locally before a request is sent.
+//
+// Example usage:
+//
+//	params, err := payments.NewSEPA().
+//	    Creditor("John Doe", "DE89 3704 0044 0532 0130 00").
+//	    Amount("150.00", "EUR").
+//	    DebtorAccount("acc_123456").
+//	    Reference("Invoice #12345").
+//	    Build()
+//	if err != nil {
+//	    log.Fatal(err) // *openibank.ValidationError
+//	}
+//
+//	payment, err := client.Payments.Create(ctx, params)
+package payments
+
+import (
+	"time"
+
+	openibank "github.com/openibank/sdk-go"
+	"github.com/openibank/sdk-go/bic"
+	"github.com/openibank/sdk-go/iban"
+	"github.com/openibank/sdk-go/ukmodulus"
+)
+
+// Limits applied by the builders.
+const (
+	// MaxCreditorNameLength is the maximum length of a creditor name.
+	MaxCreditorNameLength = 70
+	// MaxEndToEndIDLength is the maximum length of an end-to-end ID.
+	MaxEndToEndIDLength = 35
+	// MaxSEPAReferenceLength is the maximum length of unstructured SEPA
+	// remittance information.
+	MaxSEPAReferenceLength = 140
+	// MaxDomesticReferenceLength is the maximum length of a UK Faster
+	// Payments reference.
+	MaxDomesticReferenceLength = 18
+)
+
+// SEPABuilder builds a SEPA credit transfer.
+type SEPABuilder struct {
+	params openibank.PaymentCreateParams
+}
+
+// NewSEPA returns a builder for a SEPA credit transfer.
+func NewSEPA() *SEPABuilder {
+	return &SEPABuilder{}
+}
+
+// Creditor sets the creditor name and IBAN. Spaces and dashes in the IBAN
+// are removed.
+func (b *SEPABuilder) Creditor(name, number string) *SEPABuilder {
+	b.params.Creditor = openibank.Creditor{
+		Name:    name,
+		Account: openibank.CreditorAccount{IBAN: openibank.String(iban.Normalize(number))},
+	}
+	return b
+}
+
+// Amount sets the amount and currency.
+func (b *SEPABuilder) Amount(amount, currency string) *SEPABuilder {
+	b.params.Amount = openibank.Amount{Amount: amount, Currency: currency}
+	return b
+}
+
+// CreditorBIC sets the BIC of the creditor's bank. Spaces are removed.
+func (b *SEPABuilder) CreditorBIC(code string) *SEPABuilder {
+	b.params.Creditor.Account.BIC = openibank.String(bic.Normalize(code))
+	return b
+}
+
+// CreditorAddress sets the creditor's postal address.
+func (b *SEPABuilder) CreditorAddress(addr openibank.Address) *SEPABuilder {
+	b.params.Creditor.Address = &addr
+	return b
+}
+
+// DebtorAccount sets the account the payment is made from.
+func (b *SEPABuilder) DebtorAccount(accountID string) *SEPABuilder {
+	b.params.DebtorAccountID = accountID
+	return b
+}
+
+// Reference sets the unstructured remittance information.
+func (b *SEPABuilder) Reference(reference string) *SEPABuilder {
+	b.params.Reference = openibank.String(reference)
+	return b
+}
+
+// EndToEndID sets the end-to-end ID.
+func (b *SEPABuilder) EndToEndID(id string) *SEPABuilder {
+	b.params.EndToEndID = openibank.String(id)
+	return b
+}
+
+// ExecutionDate sets the requested execution date to the date of t in t's
+// location.
+func (b *SEPABuilder) ExecutionDate(t time.Time) *SEPABuilder {
+	date := openibank.DateOf(t)
+	b.params.ExecutionDate = &date
+	return b
+}
+
+// Instant requests SEPA Instant. When allowFallback is true the platform may
+// execute the payment as a regular SEPA transfer instead.
+func (b *SEPABuilder) Instant(allowFallback bool) *SEPABuilder {
+	scheme := openibank.SchemeSEPAInstant
+	b.params.Scheme = &scheme
+	b.params.AllowSchemeFallback = openibank.Bool(allowFallback)
+	return b
+}
+
+// Build validates the payment and returns its parameters. Validation
+// failures are reported as a *openibank.ValidationError listing every
+// invalid field.
+func (b *SEPABuilder) Build() (openibank.PaymentCreateParams, error) {
+	var v validator
+	v.creditorName(b.params.Creditor.Name)
+	v.address(b.params.Creditor.Address)
+	if b.params.Creditor.Account.IBAN == nil || *b.params.Creditor.Account.IBAN == "" {
+		v.add("creditor.account.iban", "is required")
+	} else if err := iban.Validate(*b.params.Creditor.Account.IBAN); err != nil {
+		v.add("creditor.account.iban", ibanMessage(err))
+	}
+	if code := b.params.Creditor.Account.BIC; code != nil {
+		if err := bic.Validate(*code); err != nil {
+			v.add("creditor.account.bic", bicMessage(err))
+		}
+	}
+	v.amount(b.params.Amount)
+	if b.params.Amount.Currency != "" && b.params.Amount.Currency != "EUR" {
+		v.add("amount.currency", "SEPA payments must be in EUR")
+	}
+	v.debtorAccount(b.params.DebtorAccountID)
+	v.reference(b.params.Reference, MaxSEPAReferenceLength)
+	v.endToEndID(b.params.EndToEndID)
+	return b.params, v.err()
+}
+
+// DomesticBuilder builds a UK domestic payment identified by sort code and
+// account number.
+type DomesticBuilder struct {
+	params  openibank.PaymentCreateParams
+	modulus *ukmodulus.Table
+}
+
+// NewDomestic returns a builder for a UK domestic payment. The payment is
+// sent over Faster Payments unless another scheme is selected.
+func NewDomestic() *DomesticBuilder {
+	scheme := openibank.SchemeFPS
+	return &DomesticBuilder{
+		params: openibank.PaymentCreateParams{Scheme: &scheme},
+	}
+}
+
+// Creditor sets the creditor name, sort code and account number. Dashes and
+// spaces in the sort code are removed.
+func (b *DomesticBuilder) Creditor(name, sortCode, accountNumber string) *DomesticBuilder {
+	b.params.Creditor = openibank.Creditor{
+		Name: name,
+		Account: openibank.CreditorAccount{
+			SortCode:      openibank.String(normalizeSortCode(sortCode)),
+			AccountNumber: openibank.String(accountNumber),
+		},
+	}
+	return b
+}
+
+// Amount sets the amount and currency.
+func (b *DomesticBuilder) Amount(amount, currency string) *DomesticBuilder {
+	b.params.Amount = openibank.Amount{Amount: amount, Currency: currency}
+	return b
+}
+
+// CreditorAddress sets the creditor's postal address.
+func (b *DomesticBuilder) CreditorAddress(addr openibank.Address) *DomesticBuilder {
+	b.params.Creditor.Address = &addr
+	return b
+}
+
+// DebtorAccount sets the account the payment is made from.
+func (b *DomesticBuilder) DebtorAccount(accountID string) *DomesticBuilder {
+	b.params.DebtorAccountID = accountID
+	return b
+}
+
+// Reference sets the payment reference shown to the creditor.
+func (b *DomesticBuilder) Reference(reference string) *DomesticBuilder {
+	b.params.Reference = openibank.String(reference)
+	return b
+}
+
+// EndToEndID sets the end-to-end ID.
+func (b *DomesticBuilder) EndToEndID(id string) *DomesticBuilder {
+	b.params.EndToEndID = openibank.String(id)
+	return b
+}
+
+// ExecutionDate sets the requested execution date to the date of t in t's
+// location.
+func (b *DomesticBuilder) ExecutionDate(t time.Time) *DomesticBuilder {
+	date := openibank.DateOf(t)
+	b.params.ExecutionDate = &date
+	return b
+}
+
+// ModulusCheck checks the creditor's sort code and account number against
+// Vocalink's modulus rules in table when the payment is built.
+func (b *DomesticBuilder) ModulusCheck(table *ukmodulus.Table) *DomesticBuilder {
+	b.modulus = table
+	return b
+}
+
+// Scheme selects the UK scheme (Faster Payments, Bacs or CHAPS).
+func (b *DomesticBuilder) Scheme(scheme openibank.Scheme) *DomesticBuilder {
+	b.params.Scheme = &scheme
+	return b
+}
+
+// Build validates the payment and returns its parameters. Validation
+// failures are reported as a *openibank.ValidationError listing every
+// invalid field.
+func (b *DomesticBuilder) Build() (openibank.PaymentCreateParams, error) {
+	var v validator
+	v.creditorName(b.params.Creditor.Name)
+	v.address(b.params.Creditor.Address)
+	account := b.params.Creditor.Account
+	if account.SortCode == nil || *account.SortCode == "" {
+		v.add("creditor.account.sort_code", "is required")
+	} else if !isDigits(*account.SortCode, 6) {
+		v.add("creditor.account.sort_code", "must be 6 digits")
+	}
+	if account.AccountNumber == nil || *account.AccountNumber == "" {
+		v.add("creditor.account.account_number", "is required")
+	} else if !isDigits(*account.AccountNumber, 8) {
+		v.add("creditor.account.account_number", "must be 8 digits")
+	} else if b.modulus != nil && account.SortCode != nil && isDigits(*account.SortCode, 6) {
+		if err := b.modulus.Check(*account.SortCode, *account.AccountNumber); err != nil {
+			v.add("creditor.account.account_number", "does not match the sort code")
+		}
+	}
+	v.amount(b.params.Amount)
+	if b.params.Amount.Currency != "" && b.params.Amount.Currency != "GBP" {
+		v.add("amount.currency", "UK domestic payments must be in GBP")
+	}
+	if s := b.params.Scheme; s != nil && *s != openibank.SchemeFPS && *s != openibank.SchemeBACS && *s != openibank.SchemeCHAPS {
+		v.add("scheme", "must be fps, bacs or chaps")
+	}
+	v.debtorAccount(b.params.DebtorAccountID)
+	v.reference(b.params.Reference, MaxDomesticReferenceLength)
+	v.endToEndID(b.params.EndToEndID)
+	return b.params, v.err()
+}
@@ -0,0 +1,154 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	openibank "github.com/openibank/sdk-go"
+	"github.com/openibank/sdk-go/bic"
+	"github.com/openibank/sdk-go/iban"
+)
+
+// validator collects field errors for a payment payload.
+type validator struct {
+	errs []openibank.FieldError
+}
+
+func (v *validator) add(field, message string) {
+	v.errs = append(v.errs, openibank.FieldError{Field: field, Message: message})
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &openibank.ValidationError{
+		Message: "invalid payment",
+		Code:    openibank.ErrCodeInvalidPayment,
+		Errors:  v.errs,
+	}
+}
+
+func (v *validator) creditorName(name string) {
+	switch {
+	case strings.TrimSpace(name) == "":
+		v.add("creditor.name", "is required")
+	case utf8.RuneCountInString(name) > MaxCreditorNameLength:
+		v.add("creditor.name", fmt.Sprintf("must be at most %d characters", MaxCreditorNameLength))
+	}
+}
+
+// address adds the errors of an address, if one is set.
+func (v *validator) address(addr *openibank.Address) {
+	if addr == nil {
+		return
+	}
+	var verr *openibank.ValidationError
+	if errors.As(addr.Validate(), &verr) {
+		v.errs = append(v.errs, verr.Errors...)
+	}
+}
+
+func (v *validator) amount(amount openibank.Amount) {
+	// Amounts in an unknown currency are checked against the common exponent
+	// of 2, so that amount errors are reported alongside the currency error.
+	currency := openibank.Currency(amount.Currency)
+	exponent := 2
+	if currency.Valid() {
+		exponent = currency.Exponent()
+	}
+	switch {
+	case amount.Amount == "":
+		v.add("amount.amount", "is required")
+	case !isAmount(amount.Amount, exponent) && exponent == 0:
+		v.add("amount.amount", fmt.Sprintf("must be a positive whole number in %s", amount.Currency))
+	case !isAmount(amount.Amount, exponent):
+		v.add("amount.amount", fmt.Sprintf("must be a positive decimal with at most %d fraction digits", exponent))
+	}
+	switch {
+	case amount.Currency == "":
+		v.add("amount.currency", "is required")
+	case !currency.Valid():
+		v.add("amount.currency", "must be an ISO 4217 code")
+	}
+}
+
+func (v *validator) debtorAccount(id string) {
+	if id == "" {
+		v.add("debtor_account_id", "is required")
+	}
+}
+
+func (v *validator) reference(reference *string, max int) {
+	if reference != nil && utf8.RuneCountInString(*reference) > max {
+		v.add("reference", fmt.Sprintf("must be at most %d characters", max))
+	}
+}
+
+func (v *validator) endToEndID(id *string) {
+	if id == nil {
+		return
+	}
+	switch {
+	case *id == "":
+		v.add("end_to_end_id", "must not be empty")
+	case len(*id) > MaxEndToEndIDLength:
+		v.add("end_to_end_id", fmt.Sprintf("must be at most %d characters", MaxEndToEndIDLength))
+	}
+}
+
+// isAmount reports whether s is a positive decimal amount such as "150" or
+// "150.00" with at most maxFrac fraction digits.
+func isAmount(s string, maxFrac int) bool {
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if whole == "" || !isDigits(whole, len(whole)) {
+		return false
+	}
+	if hasFrac && (len(frac) == 0 || len(frac) > maxFrac || !isDigits(frac, len(frac))) {
+		return false
+	}
+	return strings.Trim(whole+frac, "0") != ""
+}
+
+// isDigits reports whether s consists of exactly n ASCII digits.
+func isDigits(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeSortCode(sortCode string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(sortCode)
+}
+
+// ibanMessage describes why an IBAN failed validation.
+func ibanMessage(err error) string {
+	switch {
+	case errors.Is(err, iban.ErrInvalidFormat):
+		return "must start with a 2-letter country code and 2 check digits"
+	case errors.Is(err, iban.ErrInvalidCharacters):
+		return "must contain only letters and digits"
+	case errors.Is(err, iban.ErrUnknownCountry):
+		return "has a country code that does not use IBANs"
+	case errors.Is(err, iban.ErrInvalidLength):
+		return "has the wrong length for its country"
+	default:
+		return "has an invalid checksum"
+	}
+}
+
+// bicMessage describes why a BIC failed validation.
+func bicMessage(err error) string {
+	if errors.Is(err, bic.ErrInvalidLength) {
+		return "must be 8 or 11 characters"
+	}
+	return "must be a 4-character bank code, 2-letter country code, 2-character location and optional 3-character branch"
+}
@@ -0,0 +1,104 @@
+package openibank
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// encodeQuery encodes the fields of a params struct tagged `url:"name"` as
+// query parameters. Nil pointers and empty slices are omitted, as are zero
+// values of fields tagged omitempty; slices add one parameter per element.
+// Times are encoded as RFC 3339, or as dates with the date option. Floats
+// are encoded with two decimal places with the amount option. Values that
+// implement encoding.TextMarshaler, such as Date, encode as their text, and
+// string enums as their value. params may be a nil pointer.
+func encodeQuery(params interface{}) url.Values {
+	values := url.Values{}
+	v := reflect.ValueOf(params)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return values
+		}
+		v = v.Elem()
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("url")
+		if tag == "" || tag == "-" || !f.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		field := v.Field(i)
+		if hasOption(options, "omitempty") && field.IsZero() {
+			continue
+		}
+		switch field.Kind() {
+		case reflect.Ptr:
+			if !field.IsNil() {
+				values.Add(name, formatQueryValue(field.Elem(), options))
+			}
+		case reflect.Slice, reflect.Array:
+			for j := 0; j < field.Len(); j++ {
+				values.Add(name, formatQueryValue(field.Index(j), options))
+			}
+		default:
+			values.Add(name, formatQueryValue(field, options))
+		}
+	}
+	return values
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// formatQueryValue formats a single query parameter value.
+func formatQueryValue(v reflect.Value, options string) string {
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if hasOption(options, "date") {
+			return t.Format(dateLayout)
+		}
+		return t.Format(time.RFC3339)
+	}
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			panic(fmt.Sprintf("openibank: cannot encode %s as a query parameter: %v", v.Type(), err))
+		}
+		return string(text)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		if hasOption(options, "amount") {
+			return strconv.FormatFloat(v.Float(), 'f', 2, 64)
+		}
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	}
+	panic(fmt.Sprintf("openibank: cannot encode %s as a query parameter", v.Type()))
+}
+
+// hasOption reports whether a comma-separated list of tag options contains
+// option.
+func hasOption(options, option string) bool {
+	for options != "" {
+		var name string
+		name, options, _ = strings.Cut(options, ",")
+		if name == option {
+			return true
+		}
+	}
+	return false
+}
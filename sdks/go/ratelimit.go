@@ -0,0 +1,78 @@
+package openibank
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitStatus is the client's rate limit as reported by the
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers of
+// the latest response that had them. Reset is when Remaining goes back to
+// Limit.
+type RateLimitStatus struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// IsZero reports whether no rate limit has been reported.
+func (s RateLimitStatus) IsZero() bool {
+	return s == RateLimitStatus{}
+}
+
+// RateLimitStatus returns the rate limit reported by the latest response,
+// or the zero RateLimitStatus if no response has reported one. Batch jobs
+// can use it to slow down before requests are rejected with a
+// *RateLimitError.
+func (c *Client) RateLimitStatus() RateLimitStatus {
+	return c.rateLimit.get()
+}
+
+// rateLimitTracker remembers the latest reported rate limit.
+type rateLimitTracker struct {
+	mu     sync.Mutex
+	status RateLimitStatus
+}
+
+func (t *rateLimitTracker) get() RateLimitStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
+
+// record updates the rate limit from the headers of a response, and
+// returns it. Responses without the headers leave it unchanged. now is the
+// time the response was received.
+func (t *rateLimitTracker) record(header http.Header, now time.Time) RateLimitStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	status, ok := parseRateLimit(header, now)
+	if ok {
+		t.status = status
+	}
+	return t.status
+}
+
+// parseRateLimit parses the rate limit headers. X-RateLimit-Reset is either
+// a Unix time or a number of seconds from now.
+func parseRateLimit(header http.Header, now time.Time) (RateLimitStatus, bool) {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return RateLimitStatus{}, false
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimitStatus{}, false
+	}
+	status := RateLimitStatus{Limit: limit, Remaining: remaining}
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		if reset > 1e9 {
+			status.Reset = time.Unix(reset, 0)
+		} else {
+			status.Reset = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+	return status, true
+}
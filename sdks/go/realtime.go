@@ -0,0 +1,1012 @@
+package openibank
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	mathrand "math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// RealtimeService provides WebSocket functionality.
+type RealtimeService struct {
+	client *Client
+	dialer *websocket.Dialer
+}
+
+// EventType represents a real-time event type. Event types added to the API
+// after this version of the SDK are kept as is, and their events delivered
+// as RawEvent; check them with Valid.
+type EventType string
+
+const (
+	// EventTransactionCreated is fired when a new transaction is created.
+	EventTransactionCreated EventType = "transaction.created"
+	// EventTransactionUpdated is fired when a transaction is updated.
+	EventTransactionUpdated EventType = "transaction.updated"
+	// EventBalanceUpdated is fired when an account balance changes.
+	EventBalanceUpdated EventType = "balance.updated"
+	// EventPaymentStatusChanged is fired when a payment status changes.
+	EventPaymentStatusChanged EventType = "payment.status_changed"
+	// EventConsentRevoked is fired when a consent is revoked.
+	EventConsentRevoked EventType = "consent.revoked"
+)
+
+// Valid reports whether t is one of the event types defined by this package.
+func (t EventType) Valid() bool {
+	switch t {
+	case EventTransactionCreated, EventTransactionUpdated, EventBalanceUpdated,
+		EventPaymentStatusChanged, EventConsentRevoked:
+		return true
+	}
+	return false
+}
+
+// IsKnown reports whether t is one of the event types defined by this package.
+// It is the same as Valid.
+func (t EventType) IsKnown() bool {
+	return t.Valid()
+}
+
+// TransactionEvent represents a transaction event.
+type TransactionEvent struct {
+	ID        string      `json:"id"`
+	Type      EventType   `json:"type"`
+	Data      Transaction `json:"data"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// BalanceEvent represents a balance event.
+type BalanceEvent struct {
+	ID        string    `json:"id"`
+	Type      EventType `json:"type"`
+	Data      Balance   `json:"data"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// PaymentEvent represents a payment event.
+type PaymentEvent struct {
+	ID        string    `json:"id"`
+	Type      EventType `json:"type"`
+	Data      Payment   `json:"data"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// RevokedBy identifies who revoked a consent. Values added to the API after
+// this version of the SDK are kept as is; check them with Valid.
+type RevokedBy string
+
+const (
+	// RevokedByPSU means the PSU revoked the consent at their bank.
+	RevokedByPSU RevokedBy = "psu"
+	// RevokedByTPP means the consent was revoked through the API.
+	RevokedByTPP RevokedBy = "tpp"
+	// RevokedByASPSP means the bank revoked the consent, for example after
+	// suspecting fraud.
+	RevokedByASPSP RevokedBy = "aspsp"
+)
+
+// Valid reports whether r is one of the values defined by this package.
+func (r RevokedBy) Valid() bool {
+	switch r {
+	case RevokedByPSU, RevokedByTPP, RevokedByASPSP:
+		return true
+	}
+	return false
+}
+
+// IsKnown reports whether r is one of the values defined by this package.
+// It is the same as Valid.
+func (r RevokedBy) IsKnown() bool {
+	return r.Valid()
+}
+
+// ConsentEventData is the payload of a consent event. Reason is the
+// free-text explanation given for the revocation, if any.
+type ConsentEventData struct {
+	ConsentID string    `json:"consent_id"`
+	Reason    *string   `json:"reason,omitempty"`
+	RevokedBy RevokedBy `json:"revoked_by,omitempty"`
+}
+
+// ConsentEvent represents a consent event.
+type ConsentEvent struct {
+	ID        string           `json:"id"`
+	Type      EventType        `json:"type"`
+	Data      ConsentEventData `json:"data"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+// RawEvent is an event of a type this version of the SDK does not know,
+// with its payload left undecoded.
+type RawEvent struct {
+	ID        string          `json:"id"`
+	Type      EventType       `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// Event is a real-time event delivered by Realtime.Events. It is one of
+// TransactionEvent, BalanceEvent, PaymentEvent or ConsentEvent, or RawEvent
+// for event types added to the platform after this version of the SDK; use
+// a type switch to handle each kind.
+type Event interface {
+	// EventID returns the ID of the event.
+	EventID() string
+	// EventType returns the type of the event.
+	EventType() EventType
+
+	isEvent()
+}
+
+// EventID returns the ID of the event.
+func (e TransactionEvent) EventID() string { return e.ID }
+
+// EventType returns the type of the event.
+func (e TransactionEvent) EventType() EventType { return e.Type }
+
+func (TransactionEvent) isEvent() {}
+
+// EventID returns the ID of the event.
+func (e BalanceEvent) EventID() string { return e.ID }
+
+// EventType returns the type of the event.
+func (e BalanceEvent) EventType() EventType { return e.Type }
+
+func (BalanceEvent) isEvent() {}
+
+// EventID returns the ID of the event.
+func (e PaymentEvent) EventID() string { return e.ID }
+
+// EventType returns the type of the event.
+func (e PaymentEvent) EventType() EventType { return e.Type }
+
+func (PaymentEvent) isEvent() {}
+
+// EventID returns the ID of the event.
+func (e ConsentEvent) EventID() string { return e.ID }
+
+// EventType returns the type of the event.
+func (e ConsentEvent) EventType() EventType { return e.Type }
+
+func (ConsentEvent) isEvent() {}
+
+// EventID returns the ID of the event.
+func (e RawEvent) EventID() string { return e.ID }
+
+// EventType returns the type of the event.
+func (e RawEvent) EventType() EventType { return e.Type }
+
+func (RawEvent) isEvent() {}
+
+// EventHandlers contains handlers for real-time events. Handlers are called
+// one at a time, in the order events arrive, from the subscription's own
+// goroutine, unless SubscribeParams.Dispatch says otherwise. OnUnknownEvent
+// receives events of types this version of the SDK does not know, with the
+// raw message, so that they are not silently dropped.
+//
+// OnConnect is called whenever a connection is established, including the
+// first. OnDisconnect is called whenever it ends, with the error that ended
+// it, or nil if it was closed on purpose. OnReconnecting is called before each
+// reconnection attempt with its number, starting at 1, and OnReconnect once
+// an attempt succeeds.
+type EventHandlers struct {
+	OnTransactionCreated   func(TransactionEvent)
+	OnTransactionUpdated   func(TransactionEvent)
+	OnBalanceUpdated       func(BalanceEvent)
+	OnPaymentStatusChanged func(PaymentEvent)
+	OnConsentRevoked       func(ConsentEvent)
+	OnUnknownEvent         func(eventType string, raw json.RawMessage)
+	OnError                func(error)
+	OnReconnect            func(attempt int)
+	OnConnect              func()
+	OnDisconnect           func(err error)
+	OnReconnecting         func(attempt int)
+}
+
+// ConnectionState is the state of a subscription's connection.
+type ConnectionState string
+
+const (
+	// ConnectionStateConnecting means a connection attempt is in progress.
+	ConnectionStateConnecting ConnectionState = "connecting"
+	// ConnectionStateConnected means events are being received.
+	ConnectionStateConnected ConnectionState = "connected"
+	// ConnectionStateReconnecting means the connection was lost and the
+	// subscription is waiting to try again.
+	ConnectionStateReconnecting ConnectionState = "reconnecting"
+	// ConnectionStateClosed means the subscription has ended.
+	ConnectionStateClosed ConnectionState = "closed"
+)
+
+// SubscribeParams contains parameters for subscribing to events.
+//
+// Events for all of AccountID and AccountIDs are received over a single
+// connection; accounts can be added and removed later with
+// Subscription.AddAccount and Subscription.RemoveAccount.
+//
+// Filter, if set, is applied by the server so that only matching events are
+// sent.
+//
+// ResumeFrom is the ID of the last event processed by an earlier
+// subscription, typically persisted from Subscription.LastEventID before a
+// restart. The server then replays the events that followed it before
+// delivering new ones.
+//
+// Dispatch, if set, buffers events and hands them to handlers on separate
+// goroutines; see DispatchOptions.
+//
+// Events delivered again, for example after a reconnect, are recognized by
+// their ID and dropped. Dedupe remembers the IDs; by default the last 1024
+// are kept in memory.
+type SubscribeParams struct {
+	AccountID  string
+	AccountIDs []string
+	Events     []EventType
+	Filter     *EventFilter
+	Handlers   EventHandlers
+	ResumeFrom string
+	Dispatch   *DispatchOptions
+	Dedupe     DedupeStore
+}
+
+// EventFilter narrows the events sent by the server. Each non-empty field
+// must match for an event to be sent; fields that do not apply to an event
+// type are ignored for it. MinAmount is a decimal string compared with the
+// absolute amount of transaction and payment events.
+type EventFilter struct {
+	MinAmount        *string  `json:"min_amount,omitempty"`
+	TransactionTypes []string `json:"transaction_types,omitempty"`
+	Currencies       []string `json:"currencies,omitempty"`
+	PaymentIDs       []string `json:"payment_ids,omitempty"`
+}
+
+// validate checks the filter before it is sent.
+func (f *EventFilter) validate() error {
+	if f == nil || f.MinAmount == nil {
+		return nil
+	}
+	if _, ok := new(big.Rat).SetString(*f.MinAmount); !ok {
+		return &ValidationError{
+			Message: "invalid event filter",
+			Code:    ErrCodeInvalidFilter,
+			Errors:  []FieldError{{Field: "filter.min_amount", Message: fmt.Sprintf("%q is not a decimal amount", *f.MinAmount)}},
+		}
+	}
+	return nil
+}
+
+// Control messages exchanged with the real-time endpoint.
+const (
+	realtimeSubscribe      = "subscribe"
+	realtimeSubscribed     = "subscribed"
+	realtimeAddAccounts    = "add_accounts"
+	realtimeRemoveAccounts = "remove_accounts"
+	realtimeAuthenticate   = "authenticate"
+	realtimeError          = "error"
+)
+
+// realtimeRequest is a message sent to the real-time endpoint.
+type realtimeRequest struct {
+	Type       string       `json:"type"`
+	AccountIDs []string     `json:"account_ids,omitempty"`
+	Events     []EventType  `json:"events,omitempty"`
+	Filter     *EventFilter `json:"filter,omitempty"`
+	ResumeFrom string       `json:"resume_from,omitempty"`
+	Token      string       `json:"token,omitempty"`
+}
+
+// realtimeEnvelope holds the fields common to all messages received from
+// the real-time endpoint.
+type realtimeEnvelope struct {
+	ID             string    `json:"id,omitempty"`
+	Type           string    `json:"type"`
+	Code           string    `json:"code,omitempty"`
+	Message        string    `json:"message,omitempty"`
+	SubscriptionID string    `json:"subscription_id,omitempty"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// subscribeResult interprets the server's answer to a subscription request.
+func (e realtimeEnvelope) subscribeResult() error {
+	switch e.Type {
+	case realtimeSubscribed:
+		return nil
+	case realtimeError:
+		return &Error{Message: e.Message, Code: e.Code}
+	default:
+		return &NetworkError{Message: fmt.Sprintf("unexpected %q message while subscribing", e.Type)}
+	}
+}
+
+// subscribeRequest returns the subscription request for params.
+func subscribeRequest(params SubscribeParams) realtimeRequest {
+	return realtimeRequest{
+		Type:       realtimeSubscribe,
+		AccountIDs: params.AccountIDs,
+		Events:     params.Events,
+		Filter:     params.Filter,
+		ResumeFrom: params.ResumeFrom,
+	}
+}
+
+// realtimeConn is a subscribed connection over one of the real-time
+// transports.
+type realtimeConn interface {
+	// next blocks until the next message arrives.
+	next() ([]byte, error)
+	// send sends a change to the subscription.
+	send(ctx context.Context, msg realtimeRequest) error
+	// close closes the connection. It may be called more than once.
+	close()
+}
+
+// realtimeConnector opens a subscribed connection.
+type realtimeConnector func(ctx context.Context, params SubscribeParams) (realtimeConn, error)
+
+// errStreamEnded is returned by realtimeConn.next when the server ends the
+// subscription normally.
+var errStreamEnded = errors.New("stream ended")
+
+// Delays between reconnection attempts: the default base delay, and the cap
+// of the backoff.
+const (
+	defaultRealtimeRetryDelay = time.Second
+	maxRealtimeRetryDelay     = 30 * time.Second
+)
+
+// WithRealtimeReconnect configures how subscriptions reconnect after the
+// connection drops. Attempts are spaced by exponential backoff with jitter
+// starting at baseDelay; a baseDelay of zero or less selects the default. A
+// maxRetries of zero disables reconnection and a negative value retries
+// forever. The default is 10 attempts starting at one second.
+func WithRealtimeReconnect(maxRetries int, baseDelay time.Duration) Option {
+	if baseDelay <= 0 {
+		baseDelay = defaultRealtimeRetryDelay
+	}
+	return func(c *Config) {
+		c.RealtimeMaxRetries = maxRetries
+		c.RealtimeRetryDelay = baseDelay
+	}
+}
+
+// WithRealtimeHeartbeat configures the keepalive of real-time connections.
+// A ping is sent every period, and a connection on which nothing, not even a
+// pong, has been received for period plus pongWait is considered dead and is
+// reconnected. A period of zero disables heartbeats. The default is a ping
+// every 10 seconds with a 5 second wait.
+func WithRealtimeHeartbeat(period, pongWait time.Duration) Option {
+	return func(c *Config) {
+		c.RealtimePingPeriod = period
+		c.RealtimePongWait = pongWait
+	}
+}
+
+// WithRealtimeTLSConfig sets the TLS configuration of WebSocket connections.
+// By default they use that of the HTTP client's transport, including any
+// client certificates.
+func WithRealtimeTLSConfig(config *tls.Config) Option {
+	return func(c *Config) {
+		c.RealtimeTLSConfig = config
+	}
+}
+
+// WithRealtimeProxy sets the proxy of WebSocket connections, as
+// http.Transport.Proxy does. By default they use the proxy of the HTTP
+// client's transport.
+func WithRealtimeProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(c *Config) {
+		c.RealtimeProxy = proxy
+	}
+}
+
+// WithRealtimeDialTimeout limits how long establishing the TCP connection to
+// the real-time endpoint may take. The whole handshake is limited by the
+// client timeout.
+func WithRealtimeDialTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.RealtimeDialTimeout = timeout
+	}
+}
+
+// Subscription represents a real-time subscription. If the connection
+// drops, the subscription reconnects, re-authenticates and subscribes again
+// transparently, resuming after the last event received so that events sent
+// while disconnected are replayed.
+type Subscription struct {
+	service   *RealtimeService
+	connect   realtimeConnector
+	ctx       context.Context
+	params    SubscribeParams
+	handlers  EventHandlers
+	writeMu   sync.Mutex
+	mu        sync.Mutex
+	conn      realtimeConn
+	done      chan struct{}
+	closing   chan struct{}
+	closeOnce sync.Once
+	err       error
+	lastID    string
+	cycling   atomic.Bool
+	state     ConnectionState
+	events    chan<- Event
+
+	dispatcher    *dispatcher
+	dedupe        DedupeStore
+	received      atomic.Uint64
+	duplicates    atomic.Uint64
+	reconnects    atomic.Uint64
+	lag           atomic.Int64
+	statsMu       sync.Mutex
+	droppedByType map[EventType]uint64
+}
+
+// Wait waits for the subscription to end and for in-flight handlers to
+// return. It returns nil if the subscription was closed by Close or its
+// context, or ended by the server. Otherwise it returns the error that ended
+// it: a *ReconnectError once reconnection has given up, or the error that
+// made reconnecting pointless, such as an *AuthenticationError.
+func (s *Subscription) Wait() error {
+	<-s.done
+	return s.err
+}
+
+// State returns the state of the subscription's connection.
+func (s *Subscription) State() ConnectionState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// setState moves the subscription to a new state. A closed subscription
+// stays closed.
+func (s *Subscription) setState(state ConnectionState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != ConnectionStateClosed {
+		s.state = state
+	}
+}
+
+// LastEventID returns the ID of the last event received. Persist it and pass
+// it as SubscribeParams.ResumeFrom to resume after a restart without missing
+// events.
+func (s *Subscription) LastEventID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastID
+}
+
+// Accounts returns the IDs of the accounts the subscription receives events
+// for.
+func (s *Subscription) Accounts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.params.AccountIDs...)
+}
+
+// AddAccount starts receiving events for an account on the existing
+// connection. If the connection is down, the account is included when the
+// subscription reconnects.
+func (s *Subscription) AddAccount(ctx context.Context, accountID string) error {
+	return s.updateAccounts(ctx, realtimeAddAccounts, accountID)
+}
+
+// RemoveAccount stops receiving events for an account.
+func (s *Subscription) RemoveAccount(ctx context.Context, accountID string) error {
+	return s.updateAccounts(ctx, realtimeRemoveAccounts, accountID)
+}
+
+// ReconnectError is returned by Subscription.Wait when reconnection has given
+// up after Attempts attempts. Err is the error of the last attempt.
+type ReconnectError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *ReconnectError) Error() string {
+	return fmt.Sprintf("real-time reconnection failed after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *ReconnectError) Unwrap() error {
+	return e.Err
+}
+
+// errSubscriptionClosed is returned when a closed subscription is changed.
+var errSubscriptionClosed = errors.New("subscription closed")
+
+func (s *Subscription) updateAccounts(ctx context.Context, op, accountID string) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	select {
+	case <-s.done:
+		return errSubscriptionClosed
+	default:
+	}
+
+	s.mu.Lock()
+	if s.isClosing() {
+		s.mu.Unlock()
+		return errSubscriptionClosed
+	}
+	if op == realtimeAddAccounts {
+		s.params.AccountIDs = addAccountIDs(s.params.AccountIDs, accountID)
+	} else {
+		s.params.AccountIDs = removeAccountID(s.params.AccountIDs, accountID)
+	}
+	conn := s.conn
+	s.mu.Unlock()
+
+	// A failure means the connection is down; the change is applied when
+	// the subscription reconnects.
+	_ = conn.send(ctx, realtimeRequest{Type: op, AccountIDs: []string{accountID}})
+	return nil
+}
+
+// addAccountIDs appends the IDs not yet in ids.
+func addAccountIDs(ids []string, add ...string) []string {
+	for _, id := range add {
+		found := false
+		for _, existing := range ids {
+			if existing == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// removeAccountID returns ids without id.
+func removeAccountID(ids []string, id string) []string {
+	kept := make([]string, 0, len(ids))
+	for _, existing := range ids {
+		if existing != id {
+			kept = append(kept, existing)
+		}
+	}
+	return kept
+}
+
+// Close closes the subscription and waits for in-flight handlers to return.
+// If ctx is done first, Close returns ctx.Err() and the handlers finish in
+// the background. Close may be called more than once and concurrently. A
+// handler closing its own subscription must not wait for itself: cancel the
+// subscription's context or pass an already cancelled ctx instead.
+func (s *Subscription) Close(ctx context.Context) error {
+	s.stop()
+	select {
+	case <-s.done:
+		return nil
+	default:
+	}
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stop closes the subscription without waiting for it to end.
+func (s *Subscription) stop() {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		close(s.closing)
+		s.state = ConnectionStateClosed
+		conn := s.conn
+		s.mu.Unlock()
+		conn.close()
+	})
+}
+
+// Subscribe connects to the real-time endpoint, subscribes to events and
+// dispatches them to the handlers until the subscription is closed, ctx is
+// done or the connection is lost for good. It returns once the server has
+// accepted the subscription.
+func (s *RealtimeService) Subscribe(ctx context.Context, params SubscribeParams) (*Subscription, error) {
+	return s.subscribe(ctx, params, nil, s.withPollingFallback(s.connectWebSocket))
+}
+
+// Events subscribes to real-time events and returns them on a channel
+// instead of calling the event handlers in params. OnError, OnReconnect and
+// the connection handlers are still called. The channel is closed once
+// the subscription ends; cancel ctx to end it. The subscription waits for
+// each event to be received, so the channel must be drained until it is
+// closed or ctx is done.
+//
+//	events, err := client.Realtime.Events(ctx, params)
+//	for event := range events {
+//		switch e := event.(type) {
+//		case openibank.TransactionEvent:
+//			...
+//		}
+//	}
+func (s *RealtimeService) Events(ctx context.Context, params SubscribeParams) (<-chan Event, error) {
+	events := make(chan Event)
+	sub, err := s.subscribe(ctx, params, events, s.withPollingFallback(s.connectWebSocket))
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		sub.Wait()
+		close(events)
+	}()
+	return events, nil
+}
+
+// subscribe starts a subscription over the transport of connect that sends
+// events on events, or passes them to the handlers in params if events is nil.
+func (s *RealtimeService) subscribe(ctx context.Context, params SubscribeParams, events chan<- Event, connect realtimeConnector) (*Subscription, error) {
+	if err := params.Filter.validate(); err != nil {
+		return nil, err
+	}
+	var accountIDs []string
+	if params.AccountID != "" {
+		accountIDs = append(accountIDs, params.AccountID)
+	}
+	params.AccountID = ""
+	params.AccountIDs = addAccountIDs(accountIDs, params.AccountIDs...)
+
+	conn, err := connect(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		service:  s,
+		connect:  connect,
+		ctx:      ctx,
+		params:   params,
+		handlers: params.Handlers,
+		conn:     conn,
+		lastID:   params.ResumeFrom,
+		state:    ConnectionStateConnected,
+		events:   events,
+		done:     make(chan struct{}),
+		closing:  make(chan struct{}),
+	}
+	sub.dedupe = params.Dedupe
+	if sub.dedupe == nil {
+		sub.dedupe = NewMemoryDedupeStore(defaultDedupeSize)
+	}
+	if params.Dispatch != nil {
+		sub.dispatcher = newDispatcher(sub, *params.Dispatch)
+	}
+	go sub.run()
+	if s.client.config.ClientID != "" {
+		go sub.refreshAuth()
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			sub.stop()
+		case <-sub.done:
+		}
+	}()
+	return sub, nil
+}
+
+// run reads messages, reconnecting whenever the connection drops, until the
+// subscription is closed or reconnection gives up.
+func (s *Subscription) run() {
+	defer close(s.done)
+	if s.dispatcher != nil {
+		defer s.dispatcher.stop()
+	}
+	defer s.setState(ConnectionStateClosed)
+
+	if s.handlers.OnConnect != nil {
+		s.handlers.OnConnect()
+	}
+	for {
+		s.mu.Lock()
+		conn := s.conn
+		s.mu.Unlock()
+
+		err := s.read(conn)
+		conn.close()
+		if s.isClosing() || errors.Is(err, errStreamEnded) {
+			s.disconnected(nil)
+			return
+		}
+		// Polling mode ends by itself to try the real-time endpoint again,
+		// and connections that cannot be re-authenticated are replaced
+		// before their token expires, which are not failures.
+		if errors.Is(err, errRetryTransport) || s.cycling.Swap(false) {
+			s.disconnected(nil)
+		} else {
+			lost := &NetworkError{Message: fmt.Sprintf("real-time connection lost: %v", err), Err: err}
+			s.disconnected(lost)
+			if s.service.client.config.RealtimeMaxRetries == 0 {
+				s.err = lost
+				s.handleError(lost)
+				return
+			}
+			s.handleError(lost)
+		}
+
+		if err := s.reconnect(); err != nil {
+			if !s.isClosing() {
+				s.err = err
+				s.handleError(err)
+			}
+			return
+		}
+	}
+}
+
+func (s *Subscription) disconnected(err error) {
+	if s.handlers.OnDisconnect != nil {
+		s.handlers.OnDisconnect(err)
+	}
+}
+
+// read dispatches messages from conn until reading fails.
+func (s *Subscription) read(conn realtimeConn) error {
+	for {
+		data, err := conn.next()
+		if err != nil {
+			return err
+		}
+		if err := s.dispatch(data); err != nil {
+			s.handleError(err)
+		}
+	}
+}
+
+// reconnect replaces the connection, retrying with exponential backoff and
+// jitter. Authentication failures drop the cached access token so that the
+// next attempt authenticates again.
+func (s *Subscription) reconnect() error {
+	config := s.service.client.config
+	maxRetries := config.RealtimeMaxRetries
+	if maxRetries == 0 {
+		maxRetries = 1
+	}
+	var lastErr error
+	for attempt := 1; maxRetries < 0 || attempt <= maxRetries; attempt++ {
+		s.setState(ConnectionStateReconnecting)
+		if s.handlers.OnReconnecting != nil {
+			s.handlers.OnReconnecting(attempt)
+		}
+		select {
+		case <-config.Clock.After(realtimeBackoff(config.RealtimeRetryDelay, attempt)):
+		case <-s.closing:
+			return nil
+		}
+
+		// Account changes wait for the attempt, so that none is lost
+		// between taking the parameters and publishing the connection.
+		s.writeMu.Lock()
+		s.mu.Lock()
+		params := s.params
+		params.AccountIDs = append([]string(nil), s.params.AccountIDs...)
+		if s.lastID != "" {
+			params.ResumeFrom = s.lastID
+		}
+		s.mu.Unlock()
+
+		s.setState(ConnectionStateConnecting)
+		conn, err := s.connect(s.ctx, params)
+		if metrics := config.Metrics; metrics != nil {
+			metrics.RealtimeReconnect(attempt, err)
+		}
+		if err != nil {
+			s.writeMu.Unlock()
+			var authErr *AuthenticationError
+			switch {
+			case errors.As(err, &authErr) && config.ClientID != "":
+				s.service.client.clearToken()
+			case !isRetryableRealtimeError(err):
+				return err
+			}
+			lastErr = err
+			continue
+		}
+
+		s.mu.Lock()
+		closing := s.isClosing()
+		if !closing {
+			s.conn = conn
+			s.state = ConnectionStateConnected
+		}
+		s.mu.Unlock()
+		s.writeMu.Unlock()
+		if closing {
+			conn.close()
+			return nil
+		}
+		s.reconnects.Add(1)
+
+		if s.handlers.OnConnect != nil {
+			s.handlers.OnConnect()
+		}
+		if s.handlers.OnReconnect != nil {
+			s.handlers.OnReconnect(attempt)
+		}
+		return nil
+	}
+	return &ReconnectError{Attempts: maxRetries, Err: lastErr}
+}
+
+func (s *Subscription) isClosing() bool {
+	select {
+	case <-s.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// realtimeBackoff returns the delay before a reconnection attempt: an
+// exponentially growing delay, capped, of which a random half is waited.
+func realtimeBackoff(base time.Duration, attempt int) time.Duration {
+	delay := maxRealtimeRetryDelay
+	if attempt <= 16 {
+		if d := base << (attempt - 1); d > 0 && d < delay {
+			delay = d
+		}
+	}
+	half := delay / 2
+	return half + time.Duration(mathrand.Int63n(int64(half)+1))
+}
+
+// isRetryableRealtimeError reports whether a failed connection attempt may
+// succeed if retried.
+func isRetryableRealtimeError(err error) bool {
+	var netErr *NetworkError
+	var serverErr *ServerError
+	var rateErr *RateLimitError
+	return errors.As(err, &netErr) || errors.As(err, &serverErr) || errors.As(err, &rateErr)
+}
+
+// dispatch decodes a message, passes it to the matching handler unless it
+// is a duplicate, and records its ID as the resume point.
+func (s *Subscription) dispatch(data []byte) error {
+	var envelope realtimeEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("failed to decode real-time message: %w", err)
+	}
+	var err error
+	if !s.isDuplicate(envelope.ID) {
+		err = s.handle(envelope, data)
+	}
+	if envelope.ID != "" {
+		s.mu.Lock()
+		s.lastID = envelope.ID
+		s.mu.Unlock()
+	}
+	return err
+}
+
+// handle passes a message to the events channel or the matching handler.
+// Events without a handler are dropped.
+func (s *Subscription) handle(envelope realtimeEnvelope, data []byte) error {
+	if envelope.Type == realtimeError {
+		return &Error{Message: envelope.Message, Code: envelope.Code}
+	}
+	event, err := decodeRealtimeEvent(EventType(envelope.Type), data)
+	if err != nil {
+		return err
+	}
+	s.received.Add(1)
+	var lag time.Duration
+	if !envelope.Timestamp.IsZero() {
+		lag = time.Since(envelope.Timestamp)
+		s.lag.Store(int64(lag))
+	}
+
+	// The event is passed on by the dispatcher, if any, or right away.
+	var deliver func()
+	_, unknown := event.(RawEvent)
+	switch {
+	case s.events != nil:
+		deliver = func() {
+			select {
+			case s.events <- event:
+			case <-s.closing:
+			}
+		}
+	case unknown:
+		deliver = func() {
+			if s.handlers.OnUnknownEvent != nil {
+				s.handlers.OnUnknownEvent(envelope.Type, json.RawMessage(data))
+			}
+		}
+	default:
+		deliver = func() { s.handlers.dispatch(event) }
+	}
+	if metrics := s.service.client.config.Metrics; metrics != nil {
+		handle := deliver
+		deliver = func() {
+			start := time.Now()
+			handle()
+			metrics.RealtimeEvent(event.EventType(), time.Since(start), lag)
+		}
+	}
+	if s.dispatcher == nil {
+		deliver()
+		return nil
+	}
+	s.dispatcher.submit(dispatchItem{eventType: event.EventType(), deliver: deliver})
+	return nil
+}
+
+// decodeRealtimeEvent decodes an event of the given type. Events of unknown
+// types are decoded as RawEvent.
+func decodeRealtimeEvent(eventType EventType, data []byte) (Event, error) {
+	var event Event
+	var err error
+	switch eventType {
+	case EventTransactionCreated, EventTransactionUpdated:
+		var e TransactionEvent
+		err = DecodeJSON(data, &e)
+		event = e
+	case EventBalanceUpdated:
+		var e BalanceEvent
+		err = DecodeJSON(data, &e)
+		event = e
+	case EventPaymentStatusChanged:
+		var e PaymentEvent
+		err = DecodeJSON(data, &e)
+		event = e
+	case EventConsentRevoked:
+		var e ConsentEvent
+		err = DecodeJSON(data, &e)
+		event = e
+	default:
+		var e RawEvent
+		err = DecodeJSON(data, &e)
+		event = e
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode real-time event: %w", err)
+	}
+	return event, nil
+}
+
+// dispatch passes an event to the matching handler, if any.
+func (h EventHandlers) dispatch(event Event) {
+	switch e := event.(type) {
+	case TransactionEvent:
+		if e.Type == EventTransactionUpdated {
+			if h.OnTransactionUpdated != nil {
+				h.OnTransactionUpdated(e)
+			}
+		} else if h.OnTransactionCreated != nil {
+			h.OnTransactionCreated(e)
+		}
+	case BalanceEvent:
+		if h.OnBalanceUpdated != nil {
+			h.OnBalanceUpdated(e)
+		}
+	case PaymentEvent:
+		if h.OnPaymentStatusChanged != nil {
+			h.OnPaymentStatusChanged(e)
+		}
+	case ConsentEvent:
+		if h.OnConsentRevoked != nil {
+			h.OnConsentRevoked(e)
+		}
+	}
+}
+
+func (s *Subscription) handleError(err error) {
+	if s.handlers.OnError != nil {
+		s.handlers.OnError(err)
+	}
+}
@@ -0,0 +1,57 @@
+package openibank
+
+// refreshAuth keeps a subscription authenticated for as long as it runs.
+// Shortly before the access token expires, a new one is obtained and sent
+// over the connection. If that fails, the connection is replaced by a new one
+// that resumes after the last event received, so that no events are lost and
+// errors are reported as for any reconnection.
+func (s *Subscription) refreshAuth() {
+	client := s.service.client
+	for {
+		expiry := client.tokenExpiresAt()
+		if expiry.IsZero() {
+			// The token was dropped after an authentication failure; the
+			// reconnection in progress authenticates again.
+			expiry = client.now().Add(client.config.RealtimeRetryDelay)
+		}
+		select {
+		case <-client.config.Clock.After(expiry.Sub(client.now())):
+		case <-s.closing:
+			return
+		case <-s.done:
+			return
+		}
+		if client.tokenExpiresAt().IsZero() {
+			continue
+		}
+
+		// If another request has replaced the token in the meantime, that
+		// one is sent.
+		token, err := client.ensureToken(s.ctx)
+		if err != nil || !s.authenticate(token) {
+			s.replaceConn()
+		}
+	}
+}
+
+// authenticate sends token over the current connection and reports whether
+// it was sent.
+func (s *Subscription) authenticate(token string) bool {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+	return conn.send(s.ctx, realtimeRequest{Type: realtimeAuthenticate, Token: token}) == nil
+}
+
+// replaceConn closes the current connection so that the subscription
+// reconnects, without reporting it as lost.
+func (s *Subscription) replaceConn() {
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+	s.cycling.Store(true)
+	conn.close()
+}
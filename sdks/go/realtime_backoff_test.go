@@ -0,0 +1,16 @@
+package openibank
+
+import "testing"
+
+func TestRealtimeReconnectZeroDelay(t *testing.T) {
+	client := NewClient(WithRealtimeReconnect(3, 0))
+	if got := client.config.RealtimeRetryDelay; got != defaultRealtimeRetryDelay {
+		t.Fatalf("RealtimeRetryDelay = %v, want %v", got, defaultRealtimeRetryDelay)
+	}
+	for attempt := 1; attempt <= 3; attempt++ {
+		max := defaultRealtimeRetryDelay << (attempt - 1)
+		if d := realtimeBackoff(client.config.RealtimeRetryDelay, attempt); d < max/2 || d > max {
+			t.Errorf("attempt %d: backoff = %v, want between %v and %v", attempt, d, max/2, max)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package openibank
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// defaultDedupeSize is the number of event IDs a subscription remembers when
+// no DedupeStore is given.
+const defaultDedupeSize = 1024
+
+// DedupeStore remembers the IDs of received events, so that events delivered
+// again after a reconnect are not passed to handlers twice. Implement it on
+// top of a shared store, such as Redis, to deduplicate across processes.
+type DedupeStore interface {
+	// Seen records id and reports whether it was already recorded.
+	Seen(ctx context.Context, id string) (bool, error)
+}
+
+// NewMemoryDedupeStore returns a DedupeStore that remembers the last size
+// event IDs in memory.
+func NewMemoryDedupeStore(size int) DedupeStore {
+	if size < 1 {
+		size = 1
+	}
+	return &memoryDedupeStore{
+		size:  size,
+		order: list.New(),
+		ids:   make(map[string]*list.Element, size),
+	}
+}
+
+// memoryDedupeStore is a least-recently-used set of event IDs.
+type memoryDedupeStore struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	ids   map[string]*list.Element
+}
+
+func (m *memoryDedupeStore) Seen(ctx context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if elem, ok := m.ids[id]; ok {
+		m.order.MoveToFront(elem)
+		return true, nil
+	}
+	m.ids[id] = m.order.PushFront(id)
+	if m.order.Len() > m.size {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.ids, oldest.Value.(string))
+	}
+	return false, nil
+}
+
+// isDuplicate reports whether an event with the given ID was received
+// before. If the store fails, the error is reported and the event is treated
+// as new, so that it is not lost.
+func (s *Subscription) isDuplicate(id string) bool {
+	if id == "" {
+		return false
+	}
+	seen, err := s.dedupe.Seen(s.ctx, id)
+	if err != nil {
+		s.handleError(err)
+		return false
+	}
+	if seen {
+		s.duplicates.Add(1)
+	}
+	return seen
+}
@@ -0,0 +1,172 @@
+package openibank
+
+import (
+	"sync"
+	"time"
+)
+
+// OverflowPolicy decides what happens to an event that arrives while the
+// dispatch buffer for its type is full.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock makes the subscription wait until there is room in the
+	// buffer. No events are lost, but a slow handler delays all others and,
+	// for long enough, the connection.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropOldest discards the oldest buffered event to make room.
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+)
+
+// DispatchOptions decouples handlers from the connection. Events are
+// buffered, up to BufferSize per event type, and passed to handlers by
+// worker goroutines, so that a slow handler does not stall reading.
+//
+// Workers sets the number of workers for the given event types; each such
+// type gets its own buffer and workers. Events of other types share one
+// buffer and a single worker. With one worker, events are handled in the
+// order they arrive; with more, handlers of that type run concurrently and
+// must be safe for that. Events sent on an Events channel are dispatched the
+// same way.
+type DispatchOptions struct {
+	BufferSize int
+	Overflow   OverflowPolicy
+	Workers    map[EventType]int
+}
+
+// SubscriptionStats counts the events of a subscription. Dropped counts the
+// events discarded by OverflowDropOldest, in total and per event type.
+// Duplicates counts the events ignored because they had been received
+// before; they are not included in Received. Reconnects counts successful
+// reconnections, and Lag is how long after the server's timestamp the latest
+// event was received.
+type SubscriptionStats struct {
+	Received      uint64
+	Duplicates    uint64
+	Reconnects    uint64
+	Lag           time.Duration
+	Dropped       uint64
+	DroppedByType map[EventType]uint64
+}
+
+// Stats returns the event counts of the subscription so far.
+func (s *Subscription) Stats() SubscriptionStats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	stats := SubscriptionStats{
+		Received:      s.received.Load(),
+		Duplicates:    s.duplicates.Load(),
+		Reconnects:    s.reconnects.Load(),
+		Lag:           time.Duration(s.lag.Load()),
+		DroppedByType: make(map[EventType]uint64, len(s.droppedByType)),
+	}
+	for eventType, n := range s.droppedByType {
+		stats.Dropped += n
+		stats.DroppedByType[eventType] = n
+	}
+	return stats
+}
+
+// recordDropped counts an event discarded because its buffer was full.
+func (s *Subscription) recordDropped(eventType EventType) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	if s.droppedByType == nil {
+		s.droppedByType = map[EventType]uint64{}
+	}
+	s.droppedByType[eventType]++
+	if metrics := s.service.client.config.Metrics; metrics != nil {
+		metrics.RealtimeDropped(eventType)
+	}
+}
+
+// dispatchItem is a buffered event and the call that handles it.
+type dispatchItem struct {
+	eventType EventType
+	deliver   func()
+}
+
+// dispatcher runs handlers on worker goroutines fed by per-type buffers.
+type dispatcher struct {
+	sub      *Subscription
+	overflow OverflowPolicy
+	queues   map[EventType]chan dispatchItem
+	shared   chan dispatchItem
+	wg       sync.WaitGroup
+}
+
+func newDispatcher(sub *Subscription, opts DispatchOptions) *dispatcher {
+	size := opts.BufferSize
+	if size < 1 {
+		size = 1
+	}
+	d := &dispatcher{
+		sub:      sub,
+		overflow: opts.Overflow,
+		queues:   map[EventType]chan dispatchItem{},
+		shared:   make(chan dispatchItem, size),
+	}
+	d.start(d.shared, 1)
+	for eventType, workers := range opts.Workers {
+		queue := make(chan dispatchItem, size)
+		d.queues[eventType] = queue
+		d.start(queue, workers)
+	}
+	return d
+}
+
+func (d *dispatcher) start(queue chan dispatchItem, workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for item := range queue {
+				// Buffered events are discarded once the subscription is
+				// closed; only handlers already running are waited for.
+				if !d.sub.isClosing() {
+					item.deliver()
+				}
+			}
+		}()
+	}
+}
+
+// submit buffers an event for its workers, applying the overflow policy when
+// the buffer is full.
+func (d *dispatcher) submit(item dispatchItem) {
+	queue, ok := d.queues[item.eventType]
+	if !ok {
+		queue = d.shared
+	}
+	if d.overflow != OverflowDropOldest {
+		select {
+		case queue <- item:
+		case <-d.sub.closing:
+		}
+		return
+	}
+	for {
+		select {
+		case queue <- item:
+			return
+		default:
+		}
+		select {
+		case dropped := <-queue:
+			d.sub.recordDropped(dropped.eventType)
+		default:
+		}
+	}
+}
+
+// stop lets the workers finish the buffered events and waits for them.
+func (d *dispatcher) stop() {
+	close(d.shared)
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	d.wg.Wait()
+}
@@ -0,0 +1,308 @@
+package openibank
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// pollingOverlap is how far each poll reaches back before the previous one,
+// so that changes are not missed because of clock skew. Changes seen twice
+// are emitted once.
+const pollingOverlap = time.Minute
+
+// pollingPageSize is the page size of list requests made while polling.
+const pollingPageSize = 100
+
+// pollingUpgradeAfter is how long a subscription stays in polling mode before
+// trying the real-time endpoint again.
+const pollingUpgradeAfter = 5 * time.Minute
+
+// errRetryTransport is returned by a polling connection when it is time to
+// try the real-time endpoint again.
+var errRetryTransport = errors.New("retrying real-time endpoint")
+
+// WithRealtimePollingFallback makes subscriptions poll the list endpoints
+// every interval when the WebSocket or SSE endpoint cannot be reached, and
+// emit the same events from the changes found. The real-time endpoint is
+// tried again every few minutes. Polling covers transaction, balance and
+// payment events; consent events are only delivered in real time. Zero, the
+// default, disables the fallback.
+func WithRealtimePollingFallback(interval time.Duration) Option {
+	return func(c *Config) {
+		c.RealtimePollInterval = interval
+	}
+}
+
+// withPollingFallback returns connect, or when polling fallback is enabled,
+// a connector that polls when connect cannot reach the endpoint.
+func (s *RealtimeService) withPollingFallback(connect realtimeConnector) realtimeConnector {
+	if s.client.config.RealtimePollInterval <= 0 {
+		return connect
+	}
+	return func(ctx context.Context, params SubscribeParams) (realtimeConn, error) {
+		conn, err := connect(ctx, params)
+		var netErr *NetworkError
+		var serverErr *ServerError
+		if err == nil || !(errors.As(err, &netErr) || errors.As(err, &serverErr)) {
+			return conn, err
+		}
+		return s.connectPolling(ctx, params)
+	}
+}
+
+// pollingConn produces events by polling the list endpoints. Only next
+// touches the poll state; the accounts are shared with send.
+type pollingConn struct {
+	service  *RealtimeService
+	ctx      context.Context
+	cancel   context.CancelFunc
+	params   SubscribeParams
+	expires  time.Time
+	since    time.Time
+	queue    [][]byte
+	baseline map[string]bool
+	txs      map[string]TransactionStatus
+	balances map[string]string
+	payments map[string]PaymentStatus
+
+	mu        sync.Mutex
+	accounts  []string
+	closeOnce sync.Once
+}
+
+// connectPolling starts polling for the subscription. The current state is
+// fetched first, so that only later changes are emitted.
+func (s *RealtimeService) connectPolling(ctx context.Context, params SubscribeParams) (realtimeConn, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	now := s.client.now()
+	c := &pollingConn{
+		service:  s,
+		ctx:      ctx,
+		cancel:   cancel,
+		params:   params,
+		accounts: append([]string(nil), params.AccountIDs...),
+		expires:  now.Add(pollingUpgradeAfter),
+		since:    now,
+		baseline: map[string]bool{},
+		txs:      map[string]TransactionStatus{},
+		balances: map[string]string{},
+		payments: map[string]PaymentStatus{},
+	}
+	if err := c.poll(false); err != nil {
+		cancel()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *pollingConn) next() ([]byte, error) {
+	for len(c.queue) == 0 {
+		if c.service.client.now().After(c.expires) {
+			return nil, errRetryTransport
+		}
+		select {
+		case <-c.ctx.Done():
+			return nil, c.ctx.Err()
+		case <-c.service.client.config.Clock.After(c.service.client.config.RealtimePollInterval):
+		}
+		if err := c.poll(true); err != nil {
+			return nil, err
+		}
+	}
+	data := c.queue[0]
+	c.queue = c.queue[1:]
+	return data, nil
+}
+
+func (c *pollingConn) send(ctx context.Context, msg realtimeRequest) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range msg.AccountIDs {
+		switch msg.Type {
+		case realtimeAddAccounts:
+			c.accounts = addAccountIDs(c.accounts, id)
+		case realtimeRemoveAccounts:
+			c.accounts = removeAccountID(c.accounts, id)
+		}
+	}
+	return nil
+}
+
+func (c *pollingConn) close() {
+	c.closeOnce.Do(c.cancel)
+}
+
+// poll fetches the changes since the previous poll and queues their events.
+// Changes to accounts polled for the first time only set the baseline.
+func (c *pollingConn) poll(emit bool) error {
+	start := c.service.client.now()
+	since := c.since.Add(-pollingOverlap)
+
+	c.mu.Lock()
+	accounts := append([]string(nil), c.accounts...)
+	c.mu.Unlock()
+
+	for _, accountID := range accounts {
+		emitAccount := emit && c.baseline[accountID]
+		if c.wants(EventTransactionCreated) || c.wants(EventTransactionUpdated) {
+			if err := c.pollTransactions(accountID, since, emitAccount, start); err != nil {
+				return err
+			}
+		}
+		if c.wants(EventBalanceUpdated) {
+			if err := c.pollBalances(accountID, emitAccount, start); err != nil {
+				return err
+			}
+		}
+		c.baseline[accountID] = true
+	}
+	if c.wants(EventPaymentStatusChanged) {
+		if err := c.pollPayments(since, emit, start); err != nil {
+			return err
+		}
+	}
+	c.since = start
+	return nil
+}
+
+func (c *pollingConn) pollTransactions(accountID string, since time.Time, emit bool, now time.Time) error {
+	for offset := 0; ; offset += pollingPageSize {
+		txs, err := c.service.client.Transactions.List(c.ctx, accountID, &TransactionListParams{
+			UpdatedSince: &since,
+			Limit:        Int(pollingPageSize),
+			Offset:       Int(offset),
+		})
+		if err != nil {
+			return err
+		}
+		for _, tx := range txs {
+			status, seen := c.txs[tx.ID]
+			c.txs[tx.ID] = tx.Status
+			switch {
+			case !seen:
+				c.push(emit, TransactionEvent{Type: EventTransactionCreated, Data: tx, Timestamp: now})
+			case status != tx.Status:
+				c.push(emit, TransactionEvent{Type: EventTransactionUpdated, Data: tx, Timestamp: now})
+			}
+		}
+		if len(txs) < pollingPageSize {
+			return nil
+		}
+	}
+}
+
+func (c *pollingConn) pollBalances(accountID string, emit bool, now time.Time) error {
+	balances, err := c.service.client.Accounts.GetBalances(c.ctx, accountID)
+	if err != nil {
+		return err
+	}
+	for _, balance := range balances {
+		key := accountID + "/" + string(balance.Type) + "/" + balance.Currency
+		amount, seen := c.balances[key]
+		c.balances[key] = balance.Amount
+		if seen && amount != balance.Amount {
+			c.push(emit, BalanceEvent{Type: EventBalanceUpdated, Data: balance, Timestamp: now})
+		}
+	}
+	return nil
+}
+
+func (c *pollingConn) pollPayments(since time.Time, emit bool, now time.Time) error {
+	for offset := 0; ; offset += pollingPageSize {
+		payments, err := c.service.client.Payments.List(c.ctx, &PaymentListParams{
+			UpdatedSince: &since,
+			Limit:        Int(pollingPageSize),
+			Offset:       Int(offset),
+		})
+		if err != nil {
+			return err
+		}
+		for _, payment := range payments {
+			status, seen := c.payments[payment.ID]
+			c.payments[payment.ID] = payment.Status
+			if !seen || status != payment.Status {
+				c.push(emit, PaymentEvent{Type: EventPaymentStatusChanged, Data: payment, Timestamp: now})
+			}
+		}
+		if len(payments) < pollingPageSize {
+			return nil
+		}
+	}
+}
+
+// wants reports whether the subscription includes events of the given type.
+func (c *pollingConn) wants(eventType EventType) bool {
+	if len(c.params.Events) == 0 {
+		return true
+	}
+	for _, t := range c.params.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// push queues an event if it is wanted and passes the filter. Polled events
+// have no ID, so they do not move the resume point.
+func (c *pollingConn) push(emit bool, event Event) {
+	if !emit || !c.wants(event.EventType()) || !c.params.Filter.matches(event) {
+		return
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	c.queue = append(c.queue, data)
+}
+
+// matches applies the filter to an event on the client, as the server does
+// for real-time events.
+func (f *EventFilter) matches(event Event) bool {
+	if f == nil {
+		return true
+	}
+	switch e := event.(type) {
+	case TransactionEvent:
+		return f.matchesAmount(e.Data.Amount) &&
+			matchesAny(f.Currencies, e.Data.Currency) &&
+			matchesAny(f.TransactionTypes, string(e.Data.TransactionType))
+	case PaymentEvent:
+		return f.matchesAmount(e.Data.Amount) &&
+			matchesAny(f.Currencies, e.Data.Currency) &&
+			matchesAny(f.PaymentIDs, e.Data.ID)
+	case BalanceEvent:
+		return matchesAny(f.Currencies, e.Data.Currency)
+	}
+	return true
+}
+
+func (f *EventFilter) matchesAmount(amount string) bool {
+	if f.MinAmount == nil {
+		return true
+	}
+	min, ok := new(big.Rat).SetString(*f.MinAmount)
+	if !ok {
+		return true
+	}
+	value, ok := new(big.Rat).SetString(strings.TrimPrefix(amount, "-"))
+	return ok && value.Cmp(min) >= 0
+}
+
+// matchesAny reports whether value is in values, or values is empty.
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
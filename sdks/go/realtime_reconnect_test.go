@@ -0,0 +1,125 @@
+package openibank
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedConn is a real-time connection that returns messages, then err.
+// Without an err, it blocks until it is closed.
+type scriptedConn struct {
+	messages [][]byte
+	err      error
+
+	once   sync.Once
+	closed chan struct{}
+}
+
+func newScriptedConn(err error, messages ...string) *scriptedConn {
+	c := &scriptedConn{err: err, closed: make(chan struct{})}
+	for _, m := range messages {
+		c.messages = append(c.messages, []byte(m))
+	}
+	return c
+}
+
+func (c *scriptedConn) next() ([]byte, error) {
+	if len(c.messages) > 0 {
+		m := c.messages[0]
+		c.messages = c.messages[1:]
+		return m, nil
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	<-c.closed
+	return nil, errors.New("closed")
+}
+
+func (c *scriptedConn) send(ctx context.Context, msg realtimeRequest) error { return nil }
+
+func (c *scriptedConn) close() { c.once.Do(func() { close(c.closed) }) }
+
+// scriptedConnector returns the connections in order, recording the
+// parameters of each connection.
+type scriptedConnector struct {
+	mu     sync.Mutex
+	conns  []realtimeConn
+	params []SubscribeParams
+}
+
+func (s *scriptedConnector) connect(ctx context.Context, params SubscribeParams) (realtimeConn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.params = append(s.params, params)
+	if len(s.conns) == 0 {
+		return nil, &NetworkError{Message: "no more connections"}
+	}
+	conn := s.conns[0]
+	s.conns = s.conns[1:]
+	return conn, nil
+}
+
+func (s *scriptedConnector) calls() []SubscribeParams {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SubscribeParams(nil), s.params...)
+}
+
+func TestReconnectCallbacks(t *testing.T) {
+	connector := &scriptedConnector{conns: []realtimeConn{
+		newScriptedConn(errors.New("connection reset")),
+		newScriptedConn(nil),
+	}}
+	client := NewClient(WithAPIKey("test_key"), WithRealtimeReconnect(3, time.Millisecond))
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(call string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, call)
+	}
+	reconnected := make(chan struct{})
+	sub, err := client.Realtime.subscribe(context.Background(), SubscribeParams{
+		AccountID: "acc_1",
+		Handlers: EventHandlers{
+			OnConnect:    func() { record("connect") },
+			OnDisconnect: func(err error) { record("disconnect") },
+			OnReconnecting: func(attempt int) {
+				if attempt != 1 {
+					t.Errorf("OnReconnecting(%d), want attempt 1", attempt)
+				}
+				record("reconnecting")
+			},
+			OnReconnect: func(attempt int) {
+				record("reconnect")
+				close(reconnected)
+			},
+		},
+	}, nil, connector.connect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close(context.Background())
+
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription did not reconnect")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"connect", "disconnect", "reconnecting", "connect", "reconnect"}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("calls = %v, want %v", calls, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package openibank
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// replayPageSize is the page size of requests made by Replay.
+const replayPageSize = 100
+
+// ReplayParams contains parameters for replaying past events. From and To
+// bound the time of the events; a nil To replays up to now. Events limits the
+// event types replayed; empty means all.
+type ReplayParams struct {
+	AccountID string
+	From      time.Time
+	To        *time.Time
+	Events    []EventType
+	Handlers  EventHandlers
+}
+
+// Replay fetches past events of an account from the events endpoint and
+// passes them, oldest first, to the handlers in params as Subscribe would.
+// Use it to backfill after downtime longer than a subscription can resume
+// from. It returns once all events have been handled, or with the first
+// error.
+func (s *RealtimeService) Replay(ctx context.Context, params ReplayParams) error {
+	values := url.Values{}
+	values.Set("account_id", params.AccountID)
+	values.Set("from", params.From.Format(time.RFC3339))
+	if params.To != nil {
+		values.Set("to", params.To.Format(time.RFC3339))
+	}
+	for _, eventType := range params.Events {
+		values.Add("events", string(eventType))
+	}
+	values.Set("limit", strconv.Itoa(replayPageSize))
+
+	for offset := 0; ; offset += replayPageSize {
+		values.Set("offset", strconv.Itoa(offset))
+		var result struct {
+			Events []json.RawMessage `json:"events"`
+		}
+		if err := s.client.request(ctx, "GET", "/events", values, nil, &result); err != nil {
+			return err
+		}
+		for _, data := range result.Events {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := replayEvent(params.Handlers, data); err != nil {
+				return err
+			}
+		}
+		if len(result.Events) < replayPageSize {
+			return nil
+		}
+	}
+}
+
+// replayEvent decodes a past event and passes it to the matching handler.
+func replayEvent(handlers EventHandlers, data json.RawMessage) error {
+	var envelope realtimeEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("failed to decode event: %w", err)
+	}
+	event, err := decodeRealtimeEvent(EventType(envelope.Type), data)
+	if err != nil {
+		return err
+	}
+	if _, unknown := event.(RawEvent); unknown {
+		if handlers.OnUnknownEvent != nil {
+			handlers.OnUnknownEvent(envelope.Type, data)
+		}
+		return nil
+	}
+	handlers.dispatch(event)
+	return nil
+}
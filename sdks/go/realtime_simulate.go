@@ -0,0 +1,42 @@
+package openibank
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// SimulateEvent asks the sandbox to emit event to the subscriptions of its
+// account, as if it had happened, so that real-time handling can be tested
+// end to end. Set the event's type and data; its ID and timestamp are
+// assigned by the platform, and the ID is returned. It is only available in
+// the sandbox environment.
+//
+//	id, err := client.Realtime.SimulateEvent(ctx, openibank.TransactionEvent{
+//		Type: openibank.EventTransactionCreated,
+//		Data: openibank.Transaction{AccountID: "acc_123", Amount: "-12.50", Currency: "EUR"},
+//	})
+func (s *RealtimeService) SimulateEvent(ctx context.Context, event Event) (string, error) {
+	if s.client.config.Environment == Production {
+		return "", &Error{Message: "simulated events are only available in the sandbox environment", Code: ErrCodeSandboxOnly}
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal event: %w", err)
+	}
+	var body struct {
+		Type EventType       `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return "", fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := s.client.request(ctx, "POST", "/sandbox/events", nil, body, &result); err != nil {
+		return "", err
+	}
+	return result.ID, nil
+}
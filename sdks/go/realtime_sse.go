@@ -0,0 +1,190 @@
+package openibank
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SubscribeSSE is like Subscribe but receives events over Server-Sent
+// Events instead of a WebSocket. Use it where outbound WebSocket connections
+// are blocked, for example by corporate proxies. Events, handlers,
+// reconnection and resumption behave as for Subscribe.
+func (s *RealtimeService) SubscribeSSE(ctx context.Context, params SubscribeParams) (*Subscription, error) {
+	return s.subscribe(ctx, params, nil, s.withPollingFallback(s.connectSSE))
+}
+
+// sseConn is a subscribed Server-Sent Events stream.
+type sseConn struct {
+	service        *RealtimeService
+	subscriptionID string
+	body           io.ReadCloser
+	reader         *bufio.Reader
+	cancel         context.CancelFunc
+	deadline       time.Duration
+	watchdog       *time.Timer
+	timedOut       atomic.Bool
+	closeOnce      sync.Once
+}
+
+// connectSSE opens an authenticated event stream for the subscription. The
+// stream starts with the server's acknowledgement, which carries the ID used
+// to change the subscription's accounts.
+func (s *RealtimeService) connectSSE(ctx context.Context, params SubscribeParams) (realtimeConn, error) {
+	token, err := s.client.ensureToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(subscribeRequest(params))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	endpoint := fmt.Sprintf("%s/%s/stream/sse", s.client.BaseURL(), s.client.config.APIVersion)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	req.Header.Set("X-API-Version", s.client.config.APIVersion)
+	req.Header.Set("User-Agent", "OpeniBank-Go/"+Version)
+	if params.ResumeFrom != "" {
+		req.Header.Set("Last-Event-ID", params.ResumeFrom)
+	}
+
+	// The client timeout would cut the stream, so the handshake is bounded
+	// by a timer instead.
+	httpClient := *s.client.httpClient
+	httpClient.Timeout = 0
+	handshake := time.AfterFunc(s.client.config.Timeout, cancel)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		handshake.Stop()
+		cancel()
+		return nil, &NetworkError{Message: fmt.Sprintf("failed to connect to real-time endpoint: %v", err), Err: err}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		handshake.Stop()
+		cancel()
+		return nil, streamResponseError(resp)
+	}
+
+	c := &sseConn{
+		service: s,
+		body:    resp.Body,
+		reader:  bufio.NewReader(resp.Body),
+		cancel:  cancel,
+	}
+	data, err := c.next()
+	handshake.Stop()
+	if err != nil {
+		c.close()
+		return nil, &NetworkError{Message: fmt.Sprintf("failed to subscribe: %v", err), Err: err}
+	}
+	var ack realtimeEnvelope
+	if err := json.Unmarshal(data, &ack); err != nil {
+		c.close()
+		return nil, &NetworkError{Message: fmt.Sprintf("failed to subscribe: %v", err), Err: err}
+	}
+	if err := ack.subscribeResult(); err != nil {
+		c.close()
+		return nil, err
+	}
+	c.subscriptionID = ack.SubscriptionID
+
+	// The server sends comment lines as heartbeats.
+	config := s.client.config
+	if config.RealtimePingPeriod > 0 {
+		c.deadline = config.RealtimePingPeriod + config.RealtimePongWait
+		c.watchdog = time.AfterFunc(c.deadline, func() {
+			c.timedOut.Store(true)
+			c.body.Close()
+		})
+	}
+	return c, nil
+}
+
+// sseEndEvent is the type of the event the server sends before it ends the
+// subscription on purpose.
+const sseEndEvent = "end"
+
+// next returns the data of the next event in the stream. A stream closed
+// without an end event, as proxies and load balancers do with idle
+// connections, is a lost connection, which the subscription resumes.
+func (c *sseConn) next() ([]byte, error) {
+	var data []byte
+	var event string
+	hasData := false
+	for {
+		line, err := c.reader.ReadBytes('\n')
+		if err != nil {
+			switch {
+			case c.timedOut.Load():
+				return nil, fmt.Errorf("no heartbeat received for %s", c.deadline)
+			case errors.Is(err, io.EOF) && (len(line) > 0 || hasData || event != ""):
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		if c.watchdog != nil {
+			c.watchdog.Reset(c.deadline)
+		}
+
+		line = bytes.TrimRight(line, "\r\n")
+		switch {
+		case len(line) == 0:
+			if event == sseEndEvent {
+				return nil, errStreamEnded
+			}
+			if hasData {
+				return data, nil
+			}
+			event = ""
+		case line[0] == ':':
+			// Comment, used as a heartbeat.
+		default:
+			field, value, _ := bytes.Cut(line, []byte(":"))
+			value = bytes.TrimPrefix(value, []byte(" "))
+			switch string(field) {
+			case "event":
+				event = string(value)
+			case "data":
+				if hasData {
+					data = append(data, '\n')
+				}
+				data = append(data, value...)
+				hasData = true
+			}
+			// Event IDs are also part of the data.
+		}
+	}
+}
+
+// send applies a subscription change through the API, since an event stream
+// only flows from the server.
+func (c *sseConn) send(ctx context.Context, msg realtimeRequest) error {
+	return c.service.client.request(ctx, "POST", "/stream/sse/"+c.subscriptionID, nil, msg, nil)
+}
+
+func (c *sseConn) close() {
+	c.closeOnce.Do(func() {
+		if c.watchdog != nil {
+			c.watchdog.Stop()
+		}
+		c.cancel()
+		c.body.Close()
+	})
+}
@@ -0,0 +1,70 @@
+package openibank
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSSEConn(stream string) *sseConn {
+	r := strings.NewReader(stream)
+	return &sseConn{body: io.NopCloser(r), reader: bufio.NewReader(r), cancel: func() {}}
+}
+
+func TestSSEConnEnd(t *testing.T) {
+	tests := []struct {
+		name, stream string
+		want         error
+	}{
+		{"end event", "data: {\"id\":\"evt_1\"}\n\nevent: end\ndata: {}\n\n", errStreamEnded},
+		{"end event without data", "data: {\"id\":\"evt_1\"}\n\n: ping\nevent: end\n\n", errStreamEnded},
+		{"close between events", "data: {\"id\":\"evt_1\"}\n\n", io.EOF},
+		{"close after heartbeat", "data: {\"id\":\"evt_1\"}\n\n: ping\n", io.EOF},
+		{"close inside event", "data: {\"id\":\"evt_1\"}\n\ndata: {\"id\"", io.ErrUnexpectedEOF},
+		{"close before blank line", "data: {\"id\":\"evt_1\"}\n\ndata: {\"id\":\"evt_2\"}\n", io.ErrUnexpectedEOF},
+		{"close inside end event", "data: {\"id\":\"evt_1\"}\n\nevent: end\n", io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestSSEConn(tt.stream)
+			data, err := c.next()
+			if err != nil || string(data) != `{"id":"evt_1"}` {
+				t.Fatalf("next() = %q, %v, want the first event", data, err)
+			}
+			if _, err := c.next(); !errors.Is(err, tt.want) {
+				t.Errorf("next() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSSECloseResumes(t *testing.T) {
+	connector := &scriptedConnector{conns: []realtimeConn{
+		newTestSSEConn("id: evt_1\nevent: account.renamed\ndata: {\"id\":\"evt_1\",\"type\":\"account.renamed\"}\n\n"),
+		newScriptedConn(nil),
+	}}
+	client := NewClient(WithAPIKey("test_key"), WithRealtimeReconnect(3, time.Millisecond))
+	reconnected := make(chan struct{})
+	sub, err := client.Realtime.subscribe(context.Background(), SubscribeParams{
+		AccountID: "acc_1",
+		Handlers:  EventHandlers{OnReconnect: func(int) { close(reconnected) }},
+	}, nil, connector.connect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close(context.Background())
+
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription did not reconnect after the stream closed")
+	}
+	calls := connector.calls()
+	if len(calls) != 2 || calls[1].ResumeFrom != "evt_1" {
+		t.Errorf("connections = %+v, want a second one resuming from evt_1", calls)
+	}
+}
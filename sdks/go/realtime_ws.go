@@ -0,0 +1,214 @@
+package openibank
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsConn is a subscribed WebSocket connection.
+type wsConn struct {
+	conn      *websocket.Conn
+	timeout   time.Duration
+	deadline  time.Duration
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// connectWebSocket opens an authenticated WebSocket connection, subscribes
+// on it and starts its heartbeats.
+func (s *RealtimeService) connectWebSocket(ctx context.Context, params SubscribeParams) (realtimeConn, error) {
+	token, err := s.client.ensureToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+	header.Set("X-API-Version", s.client.config.APIVersion)
+	header.Set("User-Agent", "OpeniBank-Go/"+Version)
+
+	dialer := s.websocketDialer()
+	endpoint := fmt.Sprintf("%s/%s/stream", s.client.WebSocketURL(), s.client.config.APIVersion)
+	conn, resp, err := dialer.DialContext(ctx, endpoint, header)
+	if err != nil {
+		return nil, handshakeError(resp, err)
+	}
+
+	if err := requestSubscription(ctx, conn, params, s.client.config.Timeout); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	config := s.client.config
+	c := &wsConn{conn: conn, timeout: config.Timeout, stop: make(chan struct{})}
+	if config.RealtimePingPeriod > 0 {
+		c.deadline = config.RealtimePingPeriod + config.RealtimePongWait
+		conn.SetReadDeadline(time.Now().Add(c.deadline))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(c.deadline))
+		})
+		go keepAlive(conn, config.RealtimePingPeriod, config.RealtimePongWait, c.stop)
+	}
+	return c, nil
+}
+
+// websocketDialer returns the dialer for the real-time endpoint. Unless
+// configured otherwise, it uses the TLS settings and proxy of the client's
+// HTTP transport, so that client certificates used for the REST API are also
+// presented to the real-time endpoint.
+func (s *RealtimeService) websocketDialer() *websocket.Dialer {
+	if s.dialer != nil {
+		return s.dialer
+	}
+	config := s.client.config
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: config.Timeout,
+	}
+	transport := s.client.httpClient.Transport
+	if r, ok := transport.(*recorder); ok {
+		transport = r.base
+	}
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if t, ok := transport.(*http.Transport); ok {
+		dialer.Proxy = t.Proxy
+		if t.TLSClientConfig != nil {
+			dialer.TLSClientConfig = t.TLSClientConfig.Clone()
+		}
+	}
+	if config.RealtimeTLSConfig != nil {
+		dialer.TLSClientConfig = config.RealtimeTLSConfig
+	}
+	if config.RealtimeProxy != nil {
+		dialer.Proxy = config.RealtimeProxy
+	}
+	if config.RealtimeDialTimeout > 0 {
+		dialer.NetDialContext = (&net.Dialer{Timeout: config.RealtimeDialTimeout}).DialContext
+	}
+	return dialer
+}
+
+// requestSubscription sends the subscription request and waits for the
+// server to acknowledge it.
+func requestSubscription(ctx context.Context, conn *websocket.Conn, params SubscribeParams, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetWriteDeadline(deadline)
+	conn.SetReadDeadline(deadline)
+	defer conn.SetWriteDeadline(time.Time{})
+	defer conn.SetReadDeadline(time.Time{})
+
+	if err := conn.WriteJSON(subscribeRequest(params)); err != nil {
+		return &NetworkError{Message: fmt.Sprintf("failed to subscribe: %v", err), Err: err}
+	}
+
+	var ack realtimeEnvelope
+	if err := conn.ReadJSON(&ack); err != nil {
+		return &NetworkError{Message: fmt.Sprintf("failed to subscribe: %v", err), Err: err}
+	}
+	return ack.subscribeResult()
+}
+
+func (c *wsConn) next() ([]byte, error) {
+	_, data, err := c.conn.ReadMessage()
+	if err != nil {
+		var netErr net.Error
+		switch {
+		case errors.As(err, &netErr) && netErr.Timeout():
+			return nil, fmt.Errorf("no heartbeat received for %s", c.deadline)
+		case websocket.IsCloseError(err, websocket.CloseNormalClosure):
+			return nil, errStreamEnded
+		}
+		return nil, err
+	}
+	if c.deadline > 0 {
+		c.conn.SetReadDeadline(time.Now().Add(c.deadline))
+	}
+	return data, nil
+}
+
+func (c *wsConn) send(ctx context.Context, msg realtimeRequest) error {
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.conn.SetWriteDeadline(deadline)
+	defer c.conn.SetWriteDeadline(time.Time{})
+	return c.conn.WriteJSON(msg)
+}
+
+func (c *wsConn) close() {
+	c.closeOnce.Do(func() {
+		close(c.stop)
+		msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		_ = c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		c.conn.Close()
+	})
+}
+
+// keepAlive pings the server every period until stop is closed or a ping
+// cannot be sent.
+func keepAlive(conn *websocket.Conn, period, writeWait time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handshakeError converts a failed WebSocket handshake to an SDK error.
+func handshakeError(resp *http.Response, err error) error {
+	if resp == nil || !errors.Is(err, websocket.ErrBadHandshake) {
+		return &NetworkError{Message: fmt.Sprintf("failed to connect to real-time endpoint: %v", err), Err: err}
+	}
+	return streamResponseError(resp)
+}
+
+// streamResponseError converts an unsuccessful response to a streaming
+// request to an SDK error.
+func streamResponseError(resp *http.Response) error {
+	defer resp.Body.Close()
+
+	var errResp struct {
+		Message string `json:"message"`
+		Code    string `json:"code"`
+	}
+	data, body := readErrorBody(resp.Body)
+	if err := json.Unmarshal(data, &errResp); err != nil {
+		errResp.Message = http.StatusText(resp.StatusCode)
+	}
+	requestID := resp.Header.Get("X-Request-ID")
+	contentType := resp.Header.Get("Content-Type")
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return &AuthenticationError{Message: errResp.Message, Code: errResp.Code, StatusCode: resp.StatusCode, RequestID: requestID, Body: body, ContentType: contentType}
+	case resp.StatusCode == http.StatusForbidden:
+		return &AuthorizationError{Message: errResp.Message, Code: errResp.Code, StatusCode: resp.StatusCode, RequestID: requestID, Body: body, ContentType: contentType}
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return &RateLimitError{Message: errResp.Message, Code: errResp.Code, StatusCode: resp.StatusCode, RequestID: requestID, Body: body, ContentType: contentType}
+	case resp.StatusCode >= 500:
+		return &ServerError{Message: errResp.Message, Code: errResp.Code, StatusCode: resp.StatusCode, RequestID: requestID, Body: body, ContentType: contentType}
+	default:
+		return &Error{Message: errResp.Message, Code: errResp.Code, StatusCode: resp.StatusCode, RequestID: requestID, Body: body, ContentType: contentType}
+	}
+}
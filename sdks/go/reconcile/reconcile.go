@@ -0,0 +1,174 @@
+// Package reconcile matches outgoing payments to the transactions they
+// produced on the debtor account.
+//
+// Example usage:
+//
+//	result := reconcile.MatchPayments(payments, transactions)
+//	for _, m := range result.Matches {
+//	    fmt.Printf("%s -> %s (%s)\n", m.Payment.ID, m.Transaction.ID, m.Method)
+//	}
+//	for _, p := range result.UnmatchedPayments {
+//	    fmt.Printf("no booking yet for %s\n", p.ID)
+//	}
+package reconcile
+
+import (
+	"math/big"
+	"strings"
+	"time"
+
+	openibank "github.com/openibank/sdk-go"
+)
+
+// Method describes how a payment was matched to a transaction.
+type Method string
+
+const (
+	// MethodEndToEndID matched on identical end-to-end IDs.
+	MethodEndToEndID Method = "end_to_end_id"
+	// MethodReference matched on reference, amount and currency.
+	MethodReference Method = "reference"
+	// MethodAmountDate matched on amount, currency and a booking date close
+	// to the payment's execution date.
+	MethodAmountDate Method = "amount_date"
+)
+
+// Match is a payment paired with the transaction it produced.
+type Match struct {
+	Payment     openibank.Payment
+	Transaction openibank.Transaction
+	Method      Method
+}
+
+// Result is the outcome of MatchPayments.
+type Result struct {
+	Matches               []Match
+	UnmatchedPayments     []openibank.Payment
+	UnmatchedTransactions []openibank.Transaction
+}
+
+// Option configures MatchPayments.
+type Option func(*options)
+
+type options struct {
+	dateWindow time.Duration
+}
+
+// WithDateWindow sets how far a transaction's booking date may be from the
+// payment's execution date for an amount and date match. The default is 3
+// days.
+func WithDateWindow(window time.Duration) Option {
+	return func(o *options) {
+		o.dateWindow = window
+	}
+}
+
+// MatchPayments pairs payments with transactions. Matching runs in passes of
+// decreasing confidence: end-to-end ID, then reference with amount, then
+// amount with booking date. Each payment and transaction is used at most
+// once; when several transactions qualify in the date pass, the one booked
+// closest to the payment is chosen.
+func MatchPayments(payments []openibank.Payment, txs []openibank.Transaction, opts ...Option) Result {
+	o := &options{dateWindow: 72 * time.Hour}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	paymentUsed := make([]bool, len(payments))
+	txUsed := make([]bool, len(txs))
+	var matches []Match
+
+	pass := func(method Method, score func(p *openibank.Payment, tx *openibank.Transaction) (time.Duration, bool)) {
+		for i := range payments {
+			if paymentUsed[i] {
+				continue
+			}
+			best := -1
+			var bestScore time.Duration
+			for j := range txs {
+				if txUsed[j] {
+					continue
+				}
+				s, ok := score(&payments[i], &txs[j])
+				if ok && (best < 0 || s < bestScore) {
+					best, bestScore = j, s
+				}
+			}
+			if best >= 0 {
+				paymentUsed[i], txUsed[best] = true, true
+				matches = append(matches, Match{Payment: payments[i], Transaction: txs[best], Method: method})
+			}
+		}
+	}
+
+	pass(MethodEndToEndID, func(p *openibank.Payment, tx *openibank.Transaction) (time.Duration, bool) {
+		return 0, p.EndToEndID != nil && tx.EndToEndID != nil &&
+			*p.EndToEndID != "" && *p.EndToEndID == *tx.EndToEndID
+	})
+	pass(MethodReference, func(p *openibank.Payment, tx *openibank.Transaction) (time.Duration, bool) {
+		return 0, p.Reference != nil && tx.Reference != nil &&
+			normalizeReference(*p.Reference) != "" &&
+			normalizeReference(*p.Reference) == normalizeReference(*tx.Reference) &&
+			sameAmount(p, tx)
+	})
+	pass(MethodAmountDate, func(p *openibank.Payment, tx *openibank.Transaction) (time.Duration, bool) {
+		paid := paymentDate(p)
+		booked := transactionDate(tx)
+		if paid == nil || booked == nil || !sameAmount(p, tx) {
+			return 0, false
+		}
+		d := booked.In(paid.Location()).Sub(*paid)
+		if d < 0 {
+			d = -d
+		}
+		return d, d <= o.dateWindow
+	})
+
+	result := Result{Matches: matches}
+	for i, p := range payments {
+		if !paymentUsed[i] {
+			result.UnmatchedPayments = append(result.UnmatchedPayments, p)
+		}
+	}
+	for j, tx := range txs {
+		if !txUsed[j] {
+			result.UnmatchedTransactions = append(result.UnmatchedTransactions, tx)
+		}
+	}
+	return result
+}
+
+// sameAmount compares absolute amounts, since outgoing payments are usually
+// booked as negative amounts.
+func sameAmount(p *openibank.Payment, tx *openibank.Transaction) bool {
+	if !strings.EqualFold(p.Currency, tx.Currency) {
+		return false
+	}
+	a, ok := new(big.Rat).SetString(p.Amount)
+	if !ok {
+		return false
+	}
+	b, ok := new(big.Rat).SetString(tx.Amount)
+	if !ok {
+		return false
+	}
+	return a.Abs(a).Cmp(b.Abs(b)) == 0
+}
+
+func normalizeReference(ref string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(ref), " "))
+}
+
+func paymentDate(p *openibank.Payment) *time.Time {
+	if p.ExecutedAt != nil {
+		return p.ExecutedAt
+	}
+	return p.CreatedAt
+}
+
+func transactionDate(tx *openibank.Transaction) *openibank.Date {
+	if tx.BookingDate != nil {
+		return tx.BookingDate
+	}
+	return tx.ValueDate
+}
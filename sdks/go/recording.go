@@ -0,0 +1,329 @@
+package openibank
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/openibank/sdk-go/redact"
+)
+
+// RecordingMode is what the client does with the cassette set WithRecording.
+type RecordingMode string
+
+const (
+	// RecordingPassthrough sends requests to the API without recording
+	// them. It is the default.
+	RecordingPassthrough RecordingMode = "passthrough"
+	// RecordingRecord sends requests to the API and records them, with
+	// their responses, in the cassette.
+	RecordingRecord RecordingMode = "record"
+	// RecordingReplay answers requests with the responses recorded in the
+	// cassette, without sending them to the API.
+	RecordingReplay RecordingMode = "replay"
+)
+
+// Valid reports whether m is a known recording mode.
+func (m RecordingMode) Valid() bool {
+	switch m {
+	case RecordingPassthrough, RecordingRecord, RecordingReplay:
+		return true
+	}
+	return false
+}
+
+// WithRecording records the client's REST requests and their responses in
+// the cassette file at path, or replays them from it, depending on mode.
+// Credentials, tokens and personal data are masked before they are written,
+// so cassettes can be attached to bug reports and kept as regression test
+// data. Replayed requests are matched by method and URL, each recording
+// being used once, in order; a request without a recording fails. Real-time
+// connections are not recorded. NewClientFromEnv sets it from the
+// OPENIBANK_RECORDING_MODE and OPENIBANK_RECORDING_PATH variables.
+func WithRecording(path string, mode RecordingMode) Option {
+	return func(c *Config) {
+		c.RecordingPath = path
+		c.RecordingMode = mode
+	}
+}
+
+// defaultRecordingPath is the cassette used by NewClientFromEnv when
+// OPENIBANK_RECORDING_PATH is not set.
+const defaultRecordingPath = "openibank-cassette.json"
+
+// cassette is the file format of a recording.
+type cassette struct {
+	Interactions []interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type recordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type recordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// recorder is an http.RoundTripper that records or replays requests.
+type recorder struct {
+	base http.RoundTripper
+	path string
+	mode RecordingMode
+
+	mu       sync.Mutex
+	loaded   bool
+	loadErr  error
+	cassette cassette
+	used     []bool
+}
+
+// recordingTransport returns the transport of an HTTP client that records
+// or replays requests made with base.
+func recordingTransport(base http.RoundTripper, path string, mode RecordingMode) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &recorder{base: base, path: path, mode: mode}
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept") == "text/event-stream" {
+		return r.base.RoundTrip(req)
+	}
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if r.mode == RecordingReplay {
+		return r.replay(req)
+	}
+
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	if err := r.record(interaction{
+		Request: recordedRequest{
+			Method: req.Method,
+			URL:    requestPath(req.URL),
+			Header: sanitizeHeader(req.Header),
+			Body:   sanitizeBody(body, req.Header.Get("Content-Type")),
+		},
+		Response: recordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     sanitizeHeader(resp.Header),
+			Body:       sanitizeBody(respBody, resp.Header.Get("Content-Type")),
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// record appends an interaction to the cassette and writes it, so that the
+// recording survives a test that does not finish.
+func (r *recorder) record(i interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r.cassette); err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+	if err := os.WriteFile(r.path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	return nil
+}
+
+// replay answers req with the first unused recording of the same method and
+// URL.
+func (r *recorder) replay(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.loaded {
+		r.loaded = true
+		data, err := os.ReadFile(r.path)
+		if err == nil {
+			err = json.Unmarshal(data, &r.cassette)
+		}
+		if err != nil {
+			r.loadErr = fmt.Errorf("failed to load recording: %w", err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	if r.loadErr != nil {
+		return nil, r.loadErr
+	}
+
+	path := requestPath(req.URL)
+	for n, i := range r.cassette.Interactions {
+		if r.used[n] || i.Request.Method != req.Method || i.Request.URL != path {
+			continue
+		}
+		r.used[n] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        i.Response.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(i.Response.Body)),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("no recorded response for %s %s in %s", req.Method, path, r.path)
+}
+
+// requestPath returns the path and query of u, so that recordings replay
+// against any environment.
+func requestPath(u *url.URL) string {
+	return u.RequestURI()
+}
+
+// sensitiveHeaders are left out of recordings.
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+	"Consent-Id":    true,
+}
+
+// sanitizeHeader returns header without its sensitive headers, and without
+// Content-Length, as masking changes the length of bodies.
+func sanitizeHeader(header http.Header) http.Header {
+	sanitized := http.Header{}
+	for key, values := range header {
+		key = http.CanonicalHeaderKey(key)
+		if sensitiveHeaders[key] || key == "Content-Length" {
+			continue
+		}
+		sanitized[key] = append([]string(nil), values...)
+	}
+	return sanitized
+}
+
+// sensitiveFields maps the JSON fields masked in recordings to the kind of
+// data they hold.
+var sensitiveFields = map[string]string{
+	"access_token":      redact.KindFull,
+	"refresh_token":     redact.KindFull,
+	"id_token":          redact.KindFull,
+	"secret":            redact.KindFull,
+	"psu_id":            redact.KindFull,
+	"iban":              redact.KindIBAN,
+	"creditor_iban":     redact.KindIBAN,
+	"counterparty_iban": redact.KindIBAN,
+	"bban":              redact.KindAccount,
+	"account_number":    redact.KindAccount,
+	"masked_pan":        redact.KindAccount,
+	"owner_name":        redact.KindName,
+	"creditor_name":     redact.KindName,
+	"counterparty_name": redact.KindName,
+	"street_name":       redact.KindFull,
+	"building_number":   redact.KindFull,
+}
+
+// sensitiveFormFields are masked in form bodies, such as those of token
+// requests.
+var sensitiveFormFields = map[string]bool{
+	"client_secret": true,
+	"code":          true,
+	"code_verifier": true,
+	"refresh_token": true,
+	"password":      true,
+}
+
+// sanitizeBody masks the sensitive fields of a JSON or form body. Other
+// bodies are recorded as they are.
+func sanitizeBody(body []byte, contentType string) string {
+	switch {
+	case len(body) == 0:
+		return ""
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return string(body)
+		}
+		for key, values := range form {
+			if sensitiveFormFields[key] {
+				for n := range values {
+					values[n] = redact.Full(values[n])
+				}
+			}
+		}
+		return form.Encode()
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if decoder.Decode(&value) != nil {
+		return string(body)
+	}
+	sanitizeValue(value)
+	sanitized, err := json.Marshal(value)
+	if err != nil {
+		return string(body)
+	}
+	return string(sanitized)
+}
+
+// sanitizeValue masks the sensitive fields of decoded JSON in place. The
+// name of a creditor or debtor object is masked as a name.
+func sanitizeValue(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if s, ok := item.(string); ok {
+				if kind, ok := sensitiveFields[key]; ok {
+					v[key] = redact.String(s, kind)
+				}
+				continue
+			}
+			if party, ok := item.(map[string]interface{}); ok && (key == "creditor" || key == "debtor") {
+				if name, ok := party["name"].(string); ok {
+					party["name"] = redact.Name(name)
+				}
+			}
+			sanitizeValue(item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			sanitizeValue(item)
+		}
+	}
+}
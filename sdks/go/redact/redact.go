@@ -0,0 +1,140 @@
+// Package redact masks personal data, such as IBANs and names, so that SDK
+// objects can be logged safely.
+//
+// Struct masks the fields of a value tagged with `redact:"<kind>"`, where
+// kind is iban, account, name or full. SDK models carry these tags:
+//
+//	log.Printf("payment: %+v", redact.Struct(payment))
+package redact
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Kinds of data, used as values of the redact struct tag.
+const (
+	KindIBAN    = "iban"
+	KindAccount = "account"
+	KindName    = "name"
+	KindFull    = "full"
+)
+
+// mask is the character that replaces masked characters.
+const mask = "*"
+
+// IBAN masks an IBAN, keeping its country code, check digits and last four
+// characters: "DE89370400440532013000" becomes "DE89**************3000".
+func IBAN(iban string) string {
+	compact := strings.ToUpper(strings.ReplaceAll(iban, " ", ""))
+	if len(compact) <= 8 {
+		return Full(compact)
+	}
+	return compact[:4] + strings.Repeat(mask, len(compact)-8) + compact[len(compact)-4:]
+}
+
+// Account masks an account number or other identifier, keeping its last
+// four characters: "55779911" becomes "****9911".
+func Account(number string) string {
+	if len(number) <= 4 {
+		return Full(number)
+	}
+	return strings.Repeat(mask, len(number)-4) + number[len(number)-4:]
+}
+
+// Name masks a person's or company's name, keeping the first letter of each
+// word: "John Doe" becomes "J*** D***".
+func Name(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		first := []rune(word)[0]
+		words[i] = string(first) + strings.Repeat(mask, 3)
+	}
+	return strings.Join(words, " ")
+}
+
+// Full masks a value entirely, keeping only whether it was empty.
+func Full(s string) string {
+	if s == "" {
+		return ""
+	}
+	return strings.Repeat(mask, 3)
+}
+
+// String masks s as the given kind of data. Unknown kinds are masked fully.
+func String(s, kind string) string {
+	switch kind {
+	case KindIBAN:
+		return IBAN(s)
+	case KindAccount:
+		return Account(s)
+	case KindName:
+		return Name(s)
+	}
+	return Full(s)
+}
+
+// Struct returns a copy of v with its tagged string fields masked, including
+// those of nested structs, pointers and slices. v itself is not modified.
+// Unexported fields of structs with exported fields are left out of the
+// copy, as they may hold unmasked data; structs without exported fields,
+// such as time.Time, are copied as is. Maps are copied by reference and their
+// contents are not masked.
+func Struct[T any](v T) T {
+	value := reflect.ValueOf(&v).Elem()
+	value.Set(redactValue(value, ""))
+	return v
+}
+
+// redactValue returns a copy of value with tagged fields masked; kind is the
+// tag of the field value is stored in, if any.
+func redactValue(value reflect.Value, kind string) reflect.Value {
+	switch value.Kind() {
+	case reflect.String:
+		if kind == "" {
+			return value
+		}
+		masked := reflect.New(value.Type()).Elem()
+		masked.SetString(String(value.String(), kind))
+		return masked
+	case reflect.Ptr:
+		if value.IsNil() {
+			return value
+		}
+		elem := redactValue(value.Elem(), kind)
+		copied := reflect.New(elem.Type())
+		copied.Elem().Set(elem)
+		return copied
+	case reflect.Struct:
+		if !hasExportedFields(value.Type()) {
+			return value
+		}
+		copied := reflect.New(value.Type()).Elem()
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if field.IsExported() {
+				copied.Field(i).Set(redactValue(value.Field(i), field.Tag.Get("redact")))
+			}
+		}
+		return copied
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			copied.Index(i).Set(redactValue(value.Index(i), kind))
+		}
+		return copied
+	}
+	return value
+}
+
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+package openibank
+
+import "github.com/openibank/sdk-go/redact"
+
+// Redacted returns a copy of the account with its IBAN, BBAN and owner name
+// masked, for logging. The copy does not retain the account's JSON.
+func (a Account) Redacted() Account {
+	return redact.Struct(a)
+}
+
+// Redacted returns a copy of the transaction with its counterparty masked,
+// for logging. The copy does not retain the transaction's JSON.
+func (t Transaction) Redacted() Transaction {
+	return redact.Struct(t)
+}
+
+// Redacted returns a copy of the payment with its creditor masked, for
+// logging. The copy does not retain the payment's JSON.
+func (p Payment) Redacted() Payment {
+	return redact.Struct(p)
+}
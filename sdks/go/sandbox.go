@@ -0,0 +1,101 @@
+package openibank
+
+import (
+	"context"
+	"fmt"
+)
+
+// SandboxService provides access to the sandbox's test controls, which let
+// tests drive the platform instead of waiting for it. Its methods are only
+// available in the sandbox environment.
+type SandboxService struct {
+	client *Client
+}
+
+// sandboxOnly returns an error for feature in the production environment.
+func (s *SandboxService) sandboxOnly(feature string) error {
+	if s.client.config.Environment == Production {
+		return &Error{Message: feature + " is only available in the sandbox environment", Code: ErrCodeSandboxOnly}
+	}
+	return nil
+}
+
+// SimulatePaymentStatus moves a sandbox payment to status, as if its bank
+// had reported it, and returns the updated payment. The change is reported
+// by real-time events and webhooks like any other, so that the handling of
+// each outcome can be tested without waiting for the sandbox's timers:
+//
+//	// Executed
+//	client.Sandbox.SimulatePaymentStatus(ctx, id, openibank.PaymentStatusAcceptedSettlementCompleted, "")
+//	// Rejected for insufficient funds
+//	client.Sandbox.SimulatePaymentStatus(ctx, id, openibank.PaymentStatusRejected, openibank.ReasonInsufficientFunds)
+//
+// A payment returned by the creditor's bank is simulated by rejecting a
+// settled payment with the return reason, such as ReasonClosedAccount.
+// reason may be empty; it is reported as the payment's StatusReason.
+// Transitions the payment could not make, such as out of a terminal status
+// other than settled, fail with a *ConflictError.
+func (s *SandboxService) SimulatePaymentStatus(ctx context.Context, paymentID string, status PaymentStatus, reason ReasonCode) (*Payment, error) {
+	if err := s.sandboxOnly("simulating payment statuses"); err != nil {
+		return nil, err
+	}
+	if !status.Valid() {
+		return nil, &ValidationError{
+			Message: fmt.Sprintf("unknown payment status %q", status),
+			Code:    ErrCodeInvalidRequest,
+			Errors:  []FieldError{{Field: "status", Message: "is not a known payment status"}},
+		}
+	}
+
+	body := struct {
+		Status PaymentStatus `json:"status"`
+		Reason *ReasonCode   `json:"reason_code,omitempty"`
+	}{Status: status}
+	if reason != "" {
+		body.Reason = &reason
+	}
+	var payment Payment
+	if err := s.client.request(ctx, "POST", "/sandbox/payments/"+paymentID+"/status", nil, body, &payment); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// ExpireConsent expires a sandbox consent now, as if its validity date had
+// passed, and returns the updated consent. Requests under the consent then
+// fail as they would in production, so that re-consent handling can be
+// tested without waiting for the consent to expire.
+func (s *SandboxService) ExpireConsent(ctx context.Context, consentID string) (*Consent, error) {
+	return s.simulateConsent(ctx, consentID, "expire", "expiring consents")
+}
+
+// RevokeConsentAsPSU revokes a sandbox consent as its PSU would at their
+// bank, and returns the updated consent, in status ConsentStatusRevokedByPSU.
+// The revocation is reported by a consent.revoked real-time event and
+// webhook, so that their handling can be tested.
+func (s *SandboxService) RevokeConsentAsPSU(ctx context.Context, consentID string) (*Consent, error) {
+	return s.simulateConsent(ctx, consentID, "revoke", "revoking consents as the PSU")
+}
+
+// simulateConsent applies the sandbox action to a consent. Consents already
+// in a terminal status fail with a *ConflictError.
+func (s *SandboxService) simulateConsent(ctx context.Context, consentID, action, feature string) (*Consent, error) {
+	if err := s.sandboxOnly(feature); err != nil {
+		return nil, err
+	}
+	var consent Consent
+	if err := s.client.request(ctx, "POST", "/sandbox/consents/"+consentID+"/"+action, nil, nil, &consent); err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// Reset deletes the application's sandbox data, including its accounts,
+// consents and payments, so that test suites can start each run from a
+// clean slate. The application's credentials stay valid.
+func (s *SandboxService) Reset(ctx context.Context) error {
+	if err := s.sandboxOnly("resetting the sandbox"); err != nil {
+		return err
+	}
+	return s.client.request(ctx, "POST", "/sandbox/reset", nil, nil, nil)
+}
@@ -0,0 +1,229 @@
+package openibank
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Frequency represents how often a periodic payment is executed.
+type Frequency string
+
+const (
+	// FrequencyDaily executes every day.
+	FrequencyDaily Frequency = "daily"
+	// FrequencyWeekly executes every week on the weekday of the start date.
+	FrequencyWeekly Frequency = "weekly"
+	// FrequencyMonthly executes every month.
+	FrequencyMonthly Frequency = "monthly"
+	// FrequencyQuarterly executes every three months.
+	FrequencyQuarterly Frequency = "quarterly"
+	// FrequencyYearly executes every year.
+	FrequencyYearly Frequency = "yearly"
+)
+
+// Valid reports whether f is a known frequency.
+func (f Frequency) Valid() bool {
+	switch f {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyQuarterly, FrequencyYearly:
+		return true
+	}
+	return false
+}
+
+// IsKnown reports whether f is a known frequency. It is the same as Valid.
+func (f Frequency) IsKnown() bool {
+	return f.Valid()
+}
+
+// months returns the number of months in one period, or 0 for day-based
+// frequencies.
+func (f Frequency) months() int {
+	switch f {
+	case FrequencyMonthly:
+		return 1
+	case FrequencyQuarterly:
+		return 3
+	case FrequencyYearly:
+		return 12
+	}
+	return 0
+}
+
+// Schedule describes when a periodic payment is executed. It is used by
+// periodic payments, standing orders and variable recurring payments.
+//
+// Interval is the number of periods between executions and defaults to 1.
+// DayOfMonth applies to monthly, quarterly and yearly schedules: values 1 to
+// 31 select that day, clamped to the last day of shorter months, and negative
+// values count back from the end of the month (-1 is the last day). When nil
+// the day of StartDate is used. The schedule ends after EndDate or after
+// Occurrences executions, whichever comes first; with neither set it runs
+// until cancelled.
+//
+// Dates are calendar dates: only the year, month and day are used.
+type Schedule struct {
+	Frequency   Frequency
+	Interval    int
+	StartDate   time.Time
+	DayOfMonth  *int
+	EndDate     *time.Time
+	Occurrences *int
+}
+
+type scheduleJSON struct {
+	Frequency   Frequency `json:"frequency"`
+	Interval    int       `json:"interval,omitempty"`
+	StartDate   string    `json:"start_date"`
+	DayOfMonth  *int      `json:"day_of_month,omitempty"`
+	EndDate     *string   `json:"end_date,omitempty"`
+	Occurrences *int      `json:"occurrences,omitempty"`
+}
+
+// MarshalJSON encodes the schedule with dates in YYYY-MM-DD format.
+func (s Schedule) MarshalJSON() ([]byte, error) {
+	v := scheduleJSON{
+		Frequency:   s.Frequency,
+		Interval:    s.Interval,
+		StartDate:   s.StartDate.Format("2006-01-02"),
+		DayOfMonth:  s.DayOfMonth,
+		Occurrences: s.Occurrences,
+	}
+	if s.EndDate != nil {
+		v.EndDate = String(s.EndDate.Format("2006-01-02"))
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes a schedule with dates in YYYY-MM-DD format.
+func (s *Schedule) UnmarshalJSON(data []byte) error {
+	var v scheduleJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	start, err := time.Parse("2006-01-02", v.StartDate)
+	if err != nil {
+		return fmt.Errorf("invalid schedule start_date: %w", err)
+	}
+	*s = Schedule{
+		Frequency:   v.Frequency,
+		Interval:    v.Interval,
+		StartDate:   start,
+		DayOfMonth:  v.DayOfMonth,
+		Occurrences: v.Occurrences,
+	}
+	if v.EndDate != nil {
+		end, err := time.Parse("2006-01-02", *v.EndDate)
+		if err != nil {
+			return fmt.Errorf("invalid schedule end_date: %w", err)
+		}
+		s.EndDate = &end
+	}
+	return nil
+}
+
+// Validate checks the schedule for consistency. It returns a *ValidationError
+// listing every invalid field, or nil.
+func (s Schedule) Validate() error {
+	var errs []FieldError
+	if !s.Frequency.Valid() {
+		errs = append(errs, FieldError{Field: "schedule.frequency", Message: fmt.Sprintf("unknown frequency %q", s.Frequency)})
+	}
+	if s.Interval < 0 {
+		errs = append(errs, FieldError{Field: "schedule.interval", Message: "must not be negative"})
+	}
+	if s.StartDate.IsZero() {
+		errs = append(errs, FieldError{Field: "schedule.start_date", Message: "is required"})
+	}
+	if s.DayOfMonth != nil {
+		if s.Frequency.months() == 0 {
+			errs = append(errs, FieldError{Field: "schedule.day_of_month", Message: "only applies to monthly, quarterly and yearly schedules"})
+		} else if d := *s.DayOfMonth; d == 0 || d < -31 || d > 31 {
+			errs = append(errs, FieldError{Field: "schedule.day_of_month", Message: "must be between 1 and 31, or -1 to -31 counting from month end"})
+		}
+	}
+	if s.EndDate != nil && !s.StartDate.IsZero() && dateOf(*s.EndDate).Before(dateOf(s.StartDate)) {
+		errs = append(errs, FieldError{Field: "schedule.end_date", Message: "must not be before start_date"})
+	}
+	if s.Occurrences != nil && *s.Occurrences < 1 {
+		errs = append(errs, FieldError{Field: "schedule.occurrences", Message: "must be at least 1"})
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Message: "invalid schedule", Code: ErrCodeInvalidSchedule, Errors: errs}
+	}
+	return nil
+}
+
+// Next returns the first execution date strictly after the given time. The
+// boolean is false when the schedule has no further executions or is invalid.
+func (s Schedule) Next(after time.Time) (time.Time, bool) {
+	dates := s.Upcoming(after, 1)
+	if len(dates) == 0 {
+		return time.Time{}, false
+	}
+	return dates[0], true
+}
+
+// Upcoming returns up to n execution dates strictly after the given time.
+func (s Schedule) Upcoming(after time.Time, n int) []time.Time {
+	if n <= 0 || s.Validate() != nil {
+		return nil
+	}
+	after = dateOf(after)
+	start := dateOf(s.StartDate)
+	var dates []time.Time
+	count := 0
+	for i := 0; len(dates) < n; i++ {
+		d := s.period(start, i)
+		if d.Before(start) {
+			continue
+		}
+		count++
+		if s.Occurrences != nil && count > *s.Occurrences {
+			break
+		}
+		if s.EndDate != nil && d.After(dateOf(*s.EndDate)) {
+			break
+		}
+		if d.After(after) {
+			dates = append(dates, d)
+		}
+	}
+	return dates
+}
+
+// period returns the candidate execution date of the i-th period.
+func (s Schedule) period(start time.Time, i int) time.Time {
+	interval := s.Interval
+	if interval == 0 {
+		interval = 1
+	}
+	switch s.Frequency {
+	case FrequencyDaily:
+		return start.AddDate(0, 0, i*interval)
+	case FrequencyWeekly:
+		return start.AddDate(0, 0, 7*i*interval)
+	}
+	first := time.Date(start.Year(), start.Month()+time.Month(i*interval*s.Frequency.months()), 1, 0, 0, 0, 0, start.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	day := start.Day()
+	if s.DayOfMonth != nil {
+		day = *s.DayOfMonth
+		if day < 0 {
+			day = last + day + 1
+			if day < 1 {
+				day = 1
+			}
+		}
+	}
+	if day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// dateOf truncates t to midnight, keeping its location.
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,133 @@
+package openibank
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// The interfaces below are implemented by the client's services, so that
+// code using the client can depend on the methods it calls and be tested
+// with fakes, such as those in the openibankmock package:
+//
+//	type Reporter struct {
+//	    Accounts openibank.AccountsAPI // client.Accounts, or a mock in tests
+//	}
+
+// AccountsAPI is implemented by *AccountsService.
+type AccountsAPI interface {
+	List(ctx context.Context, params *AccountListParams, opts ...RequestOption) ([]Account, error)
+	Get(ctx context.Context, accountID string, opts ...RequestOption) (*Account, error)
+	GetBalances(ctx context.Context, accountID string, opts ...RequestOption) (Balances, error)
+}
+
+// TransactionsAPI is implemented by *TransactionsService.
+type TransactionsAPI interface {
+	List(ctx context.Context, accountID string, params *TransactionListParams, opts ...RequestOption) ([]Transaction, error)
+	Get(ctx context.Context, accountID string, transactionID string, opts ...RequestOption) (*Transaction, error)
+	Iter(ctx context.Context, accountID string, params *TransactionListParams, opts ...RequestOption) *TransactionIterator
+}
+
+// PaymentsAPI is implemented by *PaymentsService.
+type PaymentsAPI interface {
+	Create(ctx context.Context, params PaymentCreateParams, opts ...RequestOption) (*Payment, error)
+	GetQuote(ctx context.Context, params QuoteParams) (*Quote, error)
+	UploadBulkFile(ctx context.Context, r io.Reader, opts ...RequestOption) (*BulkPayment, error)
+	GetBulk(ctx context.Context, bulkID string) (*BulkPayment, error)
+	GetLimits(ctx context.Context, debtorAccountID string) (*PaymentLimits, error)
+	Get(ctx context.Context, paymentID string) (*Payment, error)
+	List(ctx context.Context, params *PaymentListParams) ([]Payment, error)
+	Cancel(ctx context.Context, paymentID string) (*Payment, error)
+	Refund(ctx context.Context, paymentID string, params RefundParams, opts ...RequestOption) (*Refund, error)
+	ListRefunds(ctx context.Context, paymentID string) ([]Refund, error)
+	Watch(ctx context.Context, paymentID string, opts ...WatchOption) (<-chan PaymentEvent, error)
+}
+
+// ConsentsAPI is implemented by *ConsentsService.
+type ConsentsAPI interface {
+	Create(ctx context.Context, params ConsentCreateParams) (*Consent, error)
+	Get(ctx context.Context, consentID string) (*Consent, error)
+	Revoke(ctx context.Context, consentID string) error
+	List(ctx context.Context) ([]Consent, error)
+	Extend(ctx context.Context, consentID string, newValidUntil time.Time) (*Consent, error)
+	RenewWithSameAccess(ctx context.Context, consentID string, validUntil time.Time) (*Consent, error)
+	Reconfirm(ctx context.Context, consentID string) (*Consent, error)
+	ListDueForReconfirmation(ctx context.Context, within time.Duration) ([]Consent, error)
+	Authorize(ctx context.Context, consentID string, redirectURI string, state string) (string, error)
+	HandleCallback(ctx context.Context, r *http.Request) (*Consent, error)
+	GetHistory(ctx context.Context, consentID string) ([]ConsentHistoryEntry, error)
+	ListForPSU(ctx context.Context, psuID string) ([]Consent, error)
+	RevokeMany(ctx context.Context, ids []string) (BatchResult[struct{}], error)
+	RevokeAllForPSU(ctx context.Context, psuID string) (BatchResult[struct{}], error)
+	GetUsage(ctx context.Context, consentID string) (*ConsentUsage, error)
+}
+
+// InstitutionsAPI is implemented by *InstitutionsService.
+type InstitutionsAPI interface {
+	List(ctx context.Context, params *InstitutionListParams) ([]Institution, error)
+	Get(ctx context.Context, institutionID string) (*Institution, error)
+	LookupByBIC(ctx context.Context, code string) (*Institution, error)
+	LookupByIBAN(ctx context.Context, iban string) (*Institution, error)
+	GetSandboxCredentials(ctx context.Context, institutionID string) ([]SandboxCredential, error)
+	SupportsScheme(ctx context.Context, institutionID string, scheme Scheme) (bool, error)
+	CachedList(ctx context.Context) ([]Institution, error)
+	RefreshCatalog(ctx context.Context) error
+	DownloadLogo(ctx context.Context, institutionID string, size LogoSize) (*Logo, error)
+}
+
+// AuthAPI is implemented by *AuthService.
+type AuthAPI interface {
+	GetAuthorizationURL(redirectURI string, scopes []string, state string) string
+	ExchangeCode(ctx context.Context, params ExchangeCodeParams) (*TokenResponse, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*TokenResponse, error)
+}
+
+// RealtimeAPI is implemented by *RealtimeService.
+type RealtimeAPI interface {
+	Subscribe(ctx context.Context, params SubscribeParams) (*Subscription, error)
+	Events(ctx context.Context, params SubscribeParams) (<-chan Event, error)
+	Replay(ctx context.Context, params ReplayParams) error
+	SimulateEvent(ctx context.Context, event Event) (string, error)
+	SubscribeSSE(ctx context.Context, params SubscribeParams) (*Subscription, error)
+}
+
+// WebhooksAPI is implemented by *WebhooksService.
+type WebhooksAPI interface {
+	Create(ctx context.Context, params WebhookCreateParams) (*WebhookEndpoint, error)
+	Get(ctx context.Context, webhookID string) (*WebhookEndpoint, error)
+	List(ctx context.Context) ([]WebhookEndpoint, error)
+	Update(ctx context.Context, webhookID string, params WebhookUpdateParams) (*WebhookEndpoint, error)
+	Delete(ctx context.Context, webhookID string) error
+	RotateSecret(ctx context.Context, webhookID string) (*WebhookSecret, error)
+	ListDeliveries(ctx context.Context, webhookID string, params *WebhookDeliveryListParams) ([]WebhookDelivery, error)
+	GetDelivery(ctx context.Context, webhookID string, deliveryID string) (*WebhookDelivery, error)
+	Redeliver(ctx context.Context, webhookID string, eventID string) (*WebhookDelivery, error)
+	ReplayRange(ctx context.Context, webhookID string, from time.Time, to time.Time) (*WebhookReplay, error)
+	ListFailed(ctx context.Context, webhookID string) ([]FailedWebhookEvent, error)
+	AcknowledgeFailed(ctx context.Context, webhookID string, eventID string) error
+	CreateRelay(ctx context.Context, events []EventType) (*WebhookRelay, error)
+	NextRelayedDeliveries(ctx context.Context, relayID string, wait time.Duration) ([]RelayedDelivery, error)
+	AcknowledgeRelayedDelivery(ctx context.Context, relayID string, deliveryID string, statusCode int) error
+	DeleteRelay(ctx context.Context, relayID string) error
+}
+
+// SandboxAPI is implemented by *SandboxService.
+type SandboxAPI interface {
+	SimulatePaymentStatus(ctx context.Context, paymentID string, status PaymentStatus, reason ReasonCode) (*Payment, error)
+	ExpireConsent(ctx context.Context, consentID string) (*Consent, error)
+	RevokeConsentAsPSU(ctx context.Context, consentID string) (*Consent, error)
+	Reset(ctx context.Context) error
+}
+
+var (
+	_ AccountsAPI     = (*AccountsService)(nil)
+	_ TransactionsAPI = (*TransactionsService)(nil)
+	_ PaymentsAPI     = (*PaymentsService)(nil)
+	_ ConsentsAPI     = (*ConsentsService)(nil)
+	_ InstitutionsAPI = (*InstitutionsService)(nil)
+	_ AuthAPI         = (*AuthService)(nil)
+	_ RealtimeAPI     = (*RealtimeService)(nil)
+	_ WebhooksAPI     = (*WebhooksService)(nil)
+	_ SandboxAPI      = (*SandboxService)(nil)
+)
@@ -0,0 +1,259 @@
+// Package harness sets up end-to-end tests in one line: it starts an API to
+// test against, configures a client for it and tears both down when the test
+// ends. The API is either the in-process openibanktest.Server, the default,
+// or the OpeniBank sandbox simulator container, run with Docker.
+//
+// Example usage:
+//
+//	func TestPayments(t *testing.T) {
+//	    h := harness.Start(t)
+//	    payment, err := h.Client.Payments.Create(ctx, params)
+//	    // ...
+//	}
+//
+// The OPENIBANK_HARNESS variable selects the API, "fake" or "container",
+// so the same tests can run against either; OPENIBANK_SANDBOX_IMAGE names
+// the simulator image.
+package harness
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	openibank "github.com/openibank/sdk-go"
+	"github.com/openibank/sdk-go/openibanktest"
+)
+
+// Mode is the kind of API a harness starts.
+type Mode string
+
+const (
+	// ModeFake starts an in-process openibanktest.Server. It is the
+	// default.
+	ModeFake Mode = "fake"
+	// ModeContainer starts the sandbox simulator container with Docker.
+	ModeContainer Mode = "container"
+)
+
+// Valid reports whether m is a known mode.
+func (m Mode) Valid() bool {
+	switch m {
+	case ModeFake, ModeContainer:
+		return true
+	}
+	return false
+}
+
+// Defaults of the container mode.
+const (
+	DefaultContainerPort = 8080
+	DefaultStartTimeout  = time.Minute
+)
+
+// Option configures a harness.
+type Option func(*config)
+
+type config struct {
+	mode          Mode
+	image         string
+	containerPort int
+	startTimeout  time.Duration
+	clientID      string
+	clientSecret  string
+	clientOptions []openibank.Option
+}
+
+// WithMode sets the kind of API to start. The default is the
+// OPENIBANK_HARNESS variable, or ModeFake if it is not set.
+func WithMode(mode Mode) Option {
+	return func(c *config) {
+		c.mode = mode
+	}
+}
+
+// WithImage sets the simulator image the container mode runs. The default
+// is the OPENIBANK_SANDBOX_IMAGE variable.
+func WithImage(image string) Option {
+	return func(c *config) {
+		c.image = image
+	}
+}
+
+// WithContainerPort sets the port the simulator listens on inside its
+// container. The default is DefaultContainerPort.
+func WithContainerPort(port int) Option {
+	return func(c *config) {
+		c.containerPort = port
+	}
+}
+
+// WithStartTimeout sets how long the container mode waits for the simulator
+// to accept requests. The default is DefaultStartTimeout.
+func WithStartTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.startTimeout = timeout
+	}
+}
+
+// WithCredentials sets the client credentials the API accepts. The default
+// is openibanktest.DefaultClientID and openibanktest.DefaultClientSecret.
+func WithCredentials(clientID, clientSecret string) Option {
+	return func(c *config) {
+		c.clientID = clientID
+		c.clientSecret = clientSecret
+	}
+}
+
+// WithClientOptions adds options to the client the harness configures.
+func WithClientOptions(opts ...openibank.Option) Option {
+	return func(c *config) {
+		c.clientOptions = append(c.clientOptions, opts...)
+	}
+}
+
+// Harness is a running API and a client configured against it.
+type Harness struct {
+	// Client is configured with the API's base URL and credentials, in the
+	// sandbox environment. Retries are disabled unless client options
+	// enable them.
+	Client *openibank.Client
+	// BaseURL is the base URL of the API.
+	BaseURL string
+	// Server is the in-process API in ModeFake, for seeding data and
+	// inspecting what was sent, and nil in ModeContainer.
+	Server *openibanktest.Server
+	// Mode is the kind of API started.
+	Mode Mode
+}
+
+// Start starts the API and configures a client against it. The API is shut
+// down when the test and its subtests end. Start fails the test if the API
+// cannot be started.
+func Start(t testing.TB, opts ...Option) *Harness {
+	t.Helper()
+	cfg := config{
+		mode:          Mode(os.Getenv("OPENIBANK_HARNESS")),
+		image:         os.Getenv("OPENIBANK_SANDBOX_IMAGE"),
+		containerPort: DefaultContainerPort,
+		startTimeout:  DefaultStartTimeout,
+		clientID:      openibanktest.DefaultClientID,
+		clientSecret:  openibanktest.DefaultClientSecret,
+	}
+	if cfg.mode == "" {
+		cfg.mode = ModeFake
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	switch cfg.mode {
+	case ModeFake:
+		srv := openibanktest.NewServer()
+		t.Cleanup(srv.Close)
+		srv.ClientID, srv.ClientSecret = cfg.clientID, cfg.clientSecret
+		return &Harness{
+			Client:  newClient(srv.URL, cfg),
+			BaseURL: srv.URL,
+			Server:  srv,
+			Mode:    ModeFake,
+		}
+	case ModeContainer:
+		baseURL, err := startContainer(t, cfg)
+		if err != nil {
+			t.Fatalf("harness: %v", err)
+		}
+		return &Harness{
+			Client:  newClient(baseURL, cfg),
+			BaseURL: baseURL,
+			Mode:    ModeContainer,
+		}
+	default:
+		t.Fatalf("harness: unknown mode %q", cfg.mode)
+		return nil
+	}
+}
+
+func newClient(baseURL string, cfg config) *openibank.Client {
+	opts := append([]openibank.Option{
+		openibank.WithEnvironment(openibank.Sandbox),
+		openibank.WithBaseURL(baseURL),
+		openibank.WithClientCredentials(cfg.clientID, cfg.clientSecret),
+		openibank.WithMaxRetries(0),
+	}, cfg.clientOptions...)
+	return openibank.NewClient(opts...)
+}
+
+// startContainer runs the simulator image with its port published on the
+// loopback interface, registers its removal and waits until it accepts
+// requests. It returns the simulator's base URL.
+func startContainer(t testing.TB, cfg config) (string, error) {
+	if cfg.image == "" {
+		return "", fmt.Errorf("no simulator image; set OPENIBANK_SANDBOX_IMAGE or use WithImage")
+	}
+	port := fmt.Sprintf("%d/tcp", cfg.containerPort)
+	id, err := docker("run", "--detach", "--rm", "--publish", "127.0.0.1::"+port, cfg.image)
+	if err != nil {
+		return "", fmt.Errorf("failed to start simulator: %w", err)
+	}
+	t.Cleanup(func() {
+		if _, err := docker("rm", "--force", id); err != nil {
+			t.Logf("harness: failed to remove simulator container %s: %v", id, err)
+		}
+	})
+
+	mapped, err := docker("port", id, port)
+	if err != nil {
+		return "", fmt.Errorf("failed to find simulator port: %w", err)
+	}
+	// docker port prints one address per line, such as "127.0.0.1:49153".
+	address, _, _ := strings.Cut(mapped, "\n")
+	baseURL := "http://" + strings.TrimSpace(address)
+	if err := waitReady(baseURL, cfg.startTimeout); err != nil {
+		return "", err
+	}
+	return baseURL, nil
+}
+
+// waitReady polls baseURL until it answers, with any status, or timeout
+// passes.
+func waitReady(baseURL string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL, nil)
+		if err != nil {
+			return err
+		}
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			resp.Body.Close()
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("simulator at %s did not start within %v", baseURL, timeout)
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
+
+// docker runs the docker command with args and returns its trimmed output.
+func docker(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("docker", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("docker %s: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("docker %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
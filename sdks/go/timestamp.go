@@ -0,0 +1,119 @@
+package openibank
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// timestampLayouts are the timestamp formats returned by institutions, in
+// the order they are tried. Timestamps without a zone are taken as UTC.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	dateLayout,
+}
+
+// ParseTimestamp parses a timestamp in any of the formats institutions
+// return: RFC 3339 with an offset or "Z", RFC 3339 without a zone or with a
+// space instead of "T", or a date alone, which is taken as midnight UTC.
+func ParseTimestamp(s string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+}
+
+// DecodeJSON decodes data into v like json.Unmarshal, but accepts any of
+// the timestamp formats of ParseTimestamp for time.Time fields. The client
+// decodes responses, real-time events and webhook payloads with it. Models
+// decoded from timestamps in other formats retain the JSON with the
+// timestamps rewritten as RFC 3339.
+func DecodeJSON(data []byte, v interface{}) error {
+	err := json.Unmarshal(data, v)
+	var parseErr *time.ParseError
+	if err == nil || !errors.As(err, &parseErr) {
+		return err
+	}
+
+	// Rewrite the timestamps in other formats as RFC 3339 and decode again.
+	// Responses in the standard format never take this path.
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if decoder.Decode(&value) != nil {
+		return err
+	}
+	if !normalizeTimestamps(&value, reflect.TypeOf(v)) {
+		return err
+	}
+	normalized, marshalErr := json.Marshal(value)
+	if marshalErr != nil {
+		return err
+	}
+	return json.Unmarshal(normalized, v)
+}
+
+// normalizeTimestamps walks a decoded JSON value alongside the type it is
+// decoded into and rewrites the time.Time values it can parse as RFC 3339.
+// It reports whether any value was rewritten.
+func normalizeTimestamps(value *interface{}, t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		s, ok := (*value).(string)
+		if !ok {
+			return false
+		}
+		parsed, err := ParseTimestamp(s)
+		if err != nil || parsed.Format(time.RFC3339Nano) == s {
+			return false
+		}
+		*value = parsed.Format(time.RFC3339Nano)
+		return true
+	}
+	ptr := reflect.PointerTo(t)
+	if ptr.Implements(unmarshalerType) && !ptr.Implements(rawModelType) {
+		return false
+	}
+	changed := false
+	switch v := (*value).(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			known := structFields(t)
+			for key, item := range v {
+				if field, ok := known[strings.ToLower(key)]; ok && normalizeTimestamps(&item, field) {
+					v[key] = item
+					changed = true
+				}
+			}
+		case reflect.Map:
+			for key, item := range v {
+				if normalizeTimestamps(&item, t.Elem()) {
+					v[key] = item
+					changed = true
+				}
+			}
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i := range v {
+				if normalizeTimestamps(&v[i], t.Elem()) {
+					changed = true
+				}
+			}
+		}
+	}
+	return changed
+}
@@ -0,0 +1,50 @@
+package openibank
+
+// BankTransactionCode is an ISO 20022 bank transaction code, classifying a
+// transaction by domain, family and sub-family, such as PMNT / RCDT / ESCT
+// for a received SEPA credit transfer.
+type BankTransactionCode struct {
+	Domain    string `json:"domain"`
+	Family    string `json:"family"`
+	SubFamily string `json:"sub_family"`
+}
+
+// String returns the code in the form "PMNT-RCDT-ESCT".
+func (c BankTransactionCode) String() string {
+	return c.Domain + "-" + c.Family + "-" + c.SubFamily
+}
+
+// ProprietaryBankTransactionCode is a bank's own transaction code, such as a
+// SWIFT MT940 code or a national code. Issuer identifies the scheme the code
+// belongs to.
+type ProprietaryBankTransactionCode struct {
+	Code   string  `json:"code"`
+	Issuer *string `json:"issuer,omitempty"`
+}
+
+// RemittanceInformation is the information a debtor sent with a payment to
+// identify what it pays for. Unstructured holds free text lines; Structured
+// holds references such as an ISO 11649 creditor reference.
+type RemittanceInformation struct {
+	Unstructured []string               `json:"unstructured,omitempty"`
+	Structured   []StructuredRemittance `json:"structured,omitempty"`
+}
+
+// StructuredRemittance is a structured remittance reference. Reference is a
+// creditor reference, such as "RF18539007547034", of type ReferenceType,
+// such as "SCOR". DocumentNumber is the number of the invoice or other
+// document paid, if given.
+type StructuredRemittance struct {
+	Reference       *string `json:"reference,omitempty"`
+	ReferenceType   *string `json:"reference_type,omitempty"`
+	ReferenceIssuer *string `json:"reference_issuer,omitempty"`
+	DocumentNumber  *string `json:"document_number,omitempty"`
+}
+
+// FinancialInstitutionID identifies the bank of a transaction's creditor or
+// debtor, by BIC or by clearing system member ID, such as a sort code.
+type FinancialInstitutionID struct {
+	BIC                    *string `json:"bic,omitempty"`
+	Name                   *string `json:"name,omitempty"`
+	ClearingSystemMemberID *string `json:"clearing_system_member_id,omitempty"`
+}
@@ -0,0 +1,51 @@
+package openibank
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func FuzzUnmarshalTransaction(f *testing.F) {
+	for _, seed := range []string{
+		`{"id":"tx_1","account_id":"acc_1","amount":"-4.20","currency":"EUR","status":"pending","metadata":{"mcc":"5814"}}`,
+		`{}`,
+		`null`,
+		`[]`,
+		`""`,
+		`{"id":1}`,
+		`{"amount":-4.2}`,
+		`{"amount":"NaN","currency":"€"}`,
+		`{"booking_date":"2024-13-45"}`,
+		`{"booking_date":"not a date","value_date":""}`,
+		`{"metadata":"card"}`,
+		`{"metadata":{"mcc":{"nested":[1,2,3]}}}`,
+		`{"metadata":{"at":"2024-03-15T10:00:00Z","n":"9223372036854775808"}}`,
+		`{"counterparty_iban":"DE8","counterparty_name":null}`,
+		`{"id":"tx_1","id":"tx_2"}`,
+		`{"id":"tx_1"`,
+		"{\"description\":\"\xff\xfe\"}",
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var tx Transaction
+		if err := json.Unmarshal(data, &tx); err != nil {
+			return
+		}
+		for key := range tx.Metadata {
+			tx.MetaString(key)
+			tx.MetaInt(key)
+			tx.MetaTime(key)
+		}
+		redacted := tx.Redacted()
+		redacted.Equal(tx)
+		encoded, err := json.Marshal(tx)
+		if err != nil {
+			t.Fatalf("cannot encode decoded transaction: %v", err)
+		}
+		var again Transaction
+		if err := json.Unmarshal(encoded, &again); err != nil {
+			t.Fatalf("cannot decode encoded transaction %s: %v", encoded, err)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package openibank
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pagingExecutor serves pages of n transactions and records the query of
+// each request.
+type pagingExecutor struct {
+	n       int
+	queries []url.Values
+}
+
+func (e *pagingExecutor) Execute(ctx context.Context, call *Call) (*Response, error) {
+	e.queries = append(e.queries, call.Query)
+	var offset, limit int
+	fmt.Sscan(call.Query.Get("offset"), &offset)
+	fmt.Sscan(call.Query.Get("limit"), &limit)
+	var items []string
+	for i := offset; i < offset+limit && i < e.n; i++ {
+		items = append(items, fmt.Sprintf(`{"id":"tx_%d"}`, i))
+	}
+	return &Response{StatusCode: 200, Body: []byte(`{"transactions":[` + strings.Join(items, ",") + `]}`)}, nil
+}
+
+func TestTransactionIteratorKeepsFilters(t *testing.T) {
+	executor := &pagingExecutor{n: 5}
+	client := NewClient(WithExecutor(executor))
+	since := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	status := "booked"
+	limit := 2
+	params := &TransactionListParams{UpdatedSince: &since, BookingStatus: &status, Limit: &limit}
+
+	it := client.Transactions.Iter(context.Background(), "acc_1", params)
+	count := 0
+	for it.Next() {
+		count++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if count != 5 {
+		t.Errorf("iterated %d transactions, want 5", count)
+	}
+	if len(executor.queries) != 3 {
+		t.Fatalf("made %d requests, want 3", len(executor.queries))
+	}
+	for i, query := range executor.queries {
+		if query.Get("updated_since") == "" || query.Get("booking_status") != "booked" {
+			t.Errorf("page %d: query %q lost the filters", i+1, query.Encode())
+		}
+		if want := fmt.Sprint(i * limit); query.Get("offset") != want {
+			t.Errorf("page %d: offset %q, want %s", i+1, query.Get("offset"), want)
+		}
+	}
+	if *params.Limit != 2 || params.Offset != nil {
+		t.Errorf("iterator modified the caller's params: %+v", params)
+	}
+}
@@ -0,0 +1,72 @@
+package ukmodulus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testTable holds weight table rows, in the format of valacdos.txt, for the
+// sort codes of the test cases below: the weights of the specification's
+// worked examples, and the exceptions under test.
+const testTable = `
+089999 089999 MOD10    0    0    0    0    0    0    7    1    3    7    1    3    7    1
+107999 107999 MOD11    0    0    0    0    0    0    8    7    6    5    4    3    2    1
+202959 202959 DBLAL    2    1    2    1    2    1    2    1    2    1    2    1    2    1
+200915 200915 MOD11    0    0    0    0    0    0    8    7    6    5    4    3    2    1    6
+200915 200915 DBLAL    2    1    2    1    2    1    2    1    2    1    2    1    2    1    6
+938000 938696 MOD11    7    6    5    4    3    2    7    6    5    4    3    2    0    0    5
+938000 938696 DBLAL    2    1    2    1    2    1    2    1    2    1    2    1    2    0    5
+180002 180002 MOD11    0    0    0    0    0    0    8    7    6    5    4    3    2    1   14
+`
+
+// testSubstitutions holds the row of scsubtab.txt used by exception 5.
+const testSubstitutions = `938600 938611
+`
+
+func TestCheck(t *testing.T) {
+	table, err := ParseTable(strings.NewReader(testTable))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.LoadSubstitutions(strings.NewReader(testSubstitutions)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The valid cases and the exception 5 failures are test cases from
+	// Vocalink's modulus checking specification; the other failures change
+	// the last digit of one of them.
+	tests := []struct {
+		name          string
+		sortCode      string
+		accountNumber string
+		want          error
+	}{
+		{"modulus 10", "089999", "66374958", nil},
+		{"modulus 11", "107999", "88837491", nil},
+		{"double alternate", "202959", "63748472", nil},
+		{"modulus 10 fails", "089999", "66374959", ErrInvalidAccount},
+		{"modulus 11 fails", "107999", "88837493", ErrInvalidAccount},
+		{"double alternate fails", "202959", "63748473", ErrInvalidAccount},
+		{"exception 5 passes both checks", "938611", "07806039", nil},
+		{"exception 5 with a substituted sort code and g of 0", "938600", "42368003", nil},
+		{"exception 5 with remainders of 0", "938063", "55065200", nil},
+		{"exception 5 with the second check digit wrong", "938063", "15764273", ErrInvalidAccount},
+		{"exception 5 with the first check digit wrong", "938063", "15764264", ErrInvalidAccount},
+		{"exception 5 with a remainder of 1", "938063", "15763217", ErrInvalidAccount},
+		{"exception 6 foreign currency account", "200915", "41011166", nil},
+		{"exception 6 sterling account", "200915", "41011167", ErrInvalidAccount},
+		{"exception 14 passes after removing the last digit", "180002", "00000190", nil},
+		{"exception 14 with a last digit other than 0, 1 or 9", "180002", "00000192", ErrInvalidAccount},
+		{"sort code without a rule", "400000", "12345678", nil},
+		{"dashes in the sort code", "08-99-99", "66374958", nil},
+		{"short account number", "089999", "6637495", ErrInvalidFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := table.Check(tt.sortCode, tt.accountNumber); !errors.Is(err, tt.want) {
+				t.Errorf("Check(%s, %s) = %v, want %v", tt.sortCode, tt.accountNumber, err, tt.want)
+			}
+		})
+	}
+}
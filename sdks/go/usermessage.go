@@ -0,0 +1,168 @@
+package openibank
+
+import (
+	"errors"
+	"strings"
+)
+
+// User message keys for errors without a code of their own.
+const (
+	userMessageGeneric      = "generic"
+	userMessageNetwork      = "network"
+	userMessageUnavailable  = "unavailable"
+	userMessageNotFound     = "not_found"
+	userMessageInvalid      = "invalid"
+	userMessageBankDeclined = "bank_declined"
+)
+
+// userMessages are end-user messages by language, then by error code or
+// user message key.
+var userMessages = map[string]map[string]string{
+	"en": {
+		userMessageGeneric:            "Something went wrong. Please try again later.",
+		userMessageNetwork:            "We couldn't reach the server. Check your connection and try again.",
+		userMessageUnavailable:        "The service is busy right now. Please try again in a few minutes.",
+		userMessageNotFound:           "We couldn't find what you were looking for.",
+		userMessageInvalid:            "Some of the details you entered are not valid. Please check them and try again.",
+		userMessageBankDeclined:       "Your bank declined the request.",
+		ErrCodeInsufficientFunds:      "There are insufficient funds in your account.",
+		ErrCodeLimitExceeded:          "This payment exceeds your account's payment limit.",
+		ErrCodeAccountBlocked:         "Your account is blocked. Please contact your bank.",
+		ErrCodeAccountClosed:          "This account is closed.",
+		ErrCodeDuplicatePayment:       "This payment has already been made.",
+		ErrCodePaymentNotCancellable:  "This payment can no longer be cancelled.",
+		ErrCodeConsentExpired:         "Your bank connection has expired. Please reconnect your bank.",
+		ErrCodeConsentRevoked:         "Your bank connection was removed. Please reconnect your bank.",
+		ErrCodeConsentNotAuthorized:   "Please finish connecting your bank first.",
+		ErrCodeSCARequired:            "Your bank needs you to confirm this with your bank app or card reader.",
+		ErrCodeSCAFailed:              "Your bank could not confirm your identity. Please try again.",
+		ErrCodeInvalidIBAN:            "The IBAN is not valid. Please check it and try again.",
+		ErrCodeInvalidBIC:             "The BIC is not valid. Please check it and try again.",
+		ErrCodeInvalidAccount:         "The account details are not valid. Please check them and try again.",
+		ErrCodeInvalidAmount:          "The amount is not valid.",
+		ErrCodeInvalidAddress:         "The address is incomplete. Please check it and try again.",
+		ErrCodeUnsupportedCurrency:    "Payments in this currency are not supported.",
+		ErrCodeInstitutionUnavailable: "Your bank is temporarily unavailable. Please try again later.",
+		ErrCodeRateLimited:            "The service is busy right now. Please try again in a few minutes.",
+	},
+	"de": {
+		userMessageGeneric:            "Etwas ist schiefgelaufen. Bitte versuchen Sie es später erneut.",
+		userMessageNetwork:            "Der Server ist nicht erreichbar. Bitte prüfen Sie Ihre Verbindung und versuchen Sie es erneut.",
+		userMessageUnavailable:        "Der Dienst ist gerade ausgelastet. Bitte versuchen Sie es in einigen Minuten erneut.",
+		userMessageNotFound:           "Das Gesuchte wurde nicht gefunden.",
+		userMessageInvalid:            "Einige Angaben sind ungültig. Bitte prüfen Sie sie und versuchen Sie es erneut.",
+		userMessageBankDeclined:       "Ihre Bank hat die Anfrage abgelehnt.",
+		ErrCodeInsufficientFunds:      "Ihr Kontoguthaben reicht nicht aus.",
+		ErrCodeLimitExceeded:          "Diese Zahlung überschreitet das Zahlungslimit Ihres Kontos.",
+		ErrCodeAccountBlocked:         "Ihr Konto ist gesperrt. Bitte wenden Sie sich an Ihre Bank.",
+		ErrCodeAccountClosed:          "Dieses Konto ist geschlossen.",
+		ErrCodeDuplicatePayment:       "Diese Zahlung wurde bereits ausgeführt.",
+		ErrCodePaymentNotCancellable:  "Diese Zahlung kann nicht mehr storniert werden.",
+		ErrCodeConsentExpired:         "Ihre Bankverbindung ist abgelaufen. Bitte verbinden Sie Ihre Bank erneut.",
+		ErrCodeConsentRevoked:         "Ihre Bankverbindung wurde entfernt. Bitte verbinden Sie Ihre Bank erneut.",
+		ErrCodeConsentNotAuthorized:   "Bitte schließen Sie zuerst die Verbindung mit Ihrer Bank ab.",
+		ErrCodeSCARequired:            "Ihre Bank verlangt eine Bestätigung mit Ihrer Banking-App oder Ihrem TAN-Generator.",
+		ErrCodeSCAFailed:              "Ihre Bank konnte Ihre Identität nicht bestätigen. Bitte versuchen Sie es erneut.",
+		ErrCodeInvalidIBAN:            "Die IBAN ist ungültig. Bitte prüfen Sie sie und versuchen Sie es erneut.",
+		ErrCodeInvalidBIC:             "Die BIC ist ungültig. Bitte prüfen Sie sie und versuchen Sie es erneut.",
+		ErrCodeInvalidAccount:         "Die Kontodaten sind ungültig. Bitte prüfen Sie sie und versuchen Sie es erneut.",
+		ErrCodeInvalidAmount:          "Der Betrag ist ungültig.",
+		ErrCodeInvalidAddress:         "Die Adresse ist unvollständig. Bitte prüfen Sie sie und versuchen Sie es erneut.",
+		ErrCodeUnsupportedCurrency:    "Zahlungen in dieser Währung werden nicht unterstützt.",
+		ErrCodeInstitutionUnavailable: "Ihre Bank ist vorübergehend nicht erreichbar. Bitte versuchen Sie es später erneut.",
+		ErrCodeRateLimited:            "Der Dienst ist gerade ausgelastet. Bitte versuchen Sie es in einigen Minuten erneut.",
+	},
+	"fr": {
+		userMessageGeneric:            "Une erreur est survenue. Veuillez réessayer plus tard.",
+		userMessageNetwork:            "Impossible de joindre le serveur. Vérifiez votre connexion et réessayez.",
+		userMessageUnavailable:        "Le service est actuellement surchargé. Veuillez réessayer dans quelques minutes.",
+		userMessageNotFound:           "Nous n'avons pas trouvé ce que vous cherchez.",
+		userMessageInvalid:            "Certaines informations saisies ne sont pas valides. Veuillez les vérifier et réessayer.",
+		userMessageBankDeclined:       "Votre banque a refusé la demande.",
+		ErrCodeInsufficientFunds:      "Le solde de votre compte est insuffisant.",
+		ErrCodeLimitExceeded:          "Ce paiement dépasse le plafond de paiement de votre compte.",
+		ErrCodeAccountBlocked:         "Votre compte est bloqué. Veuillez contacter votre banque.",
+		ErrCodeAccountClosed:          "Ce compte est clôturé.",
+		ErrCodeDuplicatePayment:       "Ce paiement a déjà été effectué.",
+		ErrCodePaymentNotCancellable:  "Ce paiement ne peut plus être annulé.",
+		ErrCodeConsentExpired:         "La connexion à votre banque a expiré. Veuillez reconnecter votre banque.",
+		ErrCodeConsentRevoked:         "La connexion à votre banque a été supprimée. Veuillez reconnecter votre banque.",
+		ErrCodeConsentNotAuthorized:   "Veuillez d'abord terminer la connexion à votre banque.",
+		ErrCodeSCARequired:            "Votre banque vous demande de confirmer avec votre application bancaire.",
+		ErrCodeSCAFailed:              "Votre banque n'a pas pu confirmer votre identité. Veuillez réessayer.",
+		ErrCodeInvalidIBAN:            "L'IBAN n'est pas valide. Veuillez le vérifier et réessayer.",
+		ErrCodeInvalidBIC:             "Le BIC n'est pas valide. Veuillez le vérifier et réessayer.",
+		ErrCodeInvalidAccount:         "Les coordonnées du compte ne sont pas valides. Veuillez les vérifier et réessayer.",
+		ErrCodeInvalidAmount:          "Le montant n'est pas valide.",
+		ErrCodeInvalidAddress:         "L'adresse est incomplète. Veuillez la vérifier et réessayer.",
+		ErrCodeUnsupportedCurrency:    "Les paiements dans cette devise ne sont pas pris en charge.",
+		ErrCodeInstitutionUnavailable: "Votre banque est temporairement indisponible. Veuillez réessayer plus tard.",
+		ErrCodeRateLimited:            "Le service est actuellement surchargé. Veuillez réessayer dans quelques minutes.",
+	},
+	"es": {
+		userMessageGeneric:            "Algo salió mal. Inténtalo de nuevo más tarde.",
+		userMessageNetwork:            "No se pudo conectar con el servidor. Comprueba tu conexión e inténtalo de nuevo.",
+		userMessageUnavailable:        "El servicio está ocupado en este momento. Inténtalo de nuevo en unos minutos.",
+		userMessageNotFound:           "No encontramos lo que buscabas.",
+		userMessageInvalid:            "Algunos de los datos introducidos no son válidos. Revísalos e inténtalo de nuevo.",
+		userMessageBankDeclined:       "Tu banco rechazó la solicitud.",
+		ErrCodeInsufficientFunds:      "No hay fondos suficientes en tu cuenta.",
+		ErrCodeLimitExceeded:          "Este pago supera el límite de pagos de tu cuenta.",
+		ErrCodeAccountBlocked:         "Tu cuenta está bloqueada. Ponte en contacto con tu banco.",
+		ErrCodeAccountClosed:          "Esta cuenta está cerrada.",
+		ErrCodeDuplicatePayment:       "Este pago ya se ha realizado.",
+		ErrCodePaymentNotCancellable:  "Este pago ya no se puede cancelar.",
+		ErrCodeConsentExpired:         "La conexión con tu banco ha caducado. Vuelve a conectar tu banco.",
+		ErrCodeConsentRevoked:         "La conexión con tu banco se ha eliminado. Vuelve a conectar tu banco.",
+		ErrCodeConsentNotAuthorized:   "Primero termina de conectar tu banco.",
+		ErrCodeSCARequired:            "Tu banco necesita que lo confirmes con tu aplicación bancaria.",
+		ErrCodeSCAFailed:              "Tu banco no pudo confirmar tu identidad. Inténtalo de nuevo.",
+		ErrCodeInvalidIBAN:            "El IBAN no es válido. Revísalo e inténtalo de nuevo.",
+		ErrCodeInvalidBIC:             "El BIC no es válido. Revísalo e inténtalo de nuevo.",
+		ErrCodeInvalidAccount:         "Los datos de la cuenta no son válidos. Revísalos e inténtalo de nuevo.",
+		ErrCodeInvalidAmount:          "El importe no es válido.",
+		ErrCodeInvalidAddress:         "La dirección está incompleta. Revísala e inténtalo de nuevo.",
+		ErrCodeUnsupportedCurrency:    "No se admiten pagos en esta moneda.",
+		ErrCodeInstitutionUnavailable: "Tu banco no está disponible temporalmente. Inténtalo de nuevo más tarde.",
+		ErrCodeRateLimited:            "El servicio está ocupado en este momento. Inténtalo de nuevo en unos minutos.",
+	},
+}
+
+// UserMessage returns a message about err that is safe to show to end
+// users, in lang, such as "de" or "de-DE". Unlike the error's own message,
+// it never contains API details. Errors with a known code get a specific
+// message, and other errors a message for their kind, such as a network
+// error. Languages other than English, German, French and Spanish fall
+// back to English.
+func UserMessage(err error, lang string) string {
+	language, _, _ := strings.Cut(strings.ReplaceAll(lang, "_", "-"), "-")
+	messages, ok := userMessages[strings.ToLower(language)]
+	if !ok {
+		messages = userMessages["en"]
+	}
+	return messages[userMessageKey(err)]
+}
+
+// userMessageKey returns the key of the user message for err.
+func userMessageKey(err error) string {
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		if _, ok := userMessages["en"][apiErr.APICode()]; ok {
+			return apiErr.APICode()
+		}
+	}
+	var consentErr *ConsentAuthorizationError
+	switch {
+	case errors.As(err, &consentErr):
+		return userMessageBankDeclined
+	case errors.Is(err, ErrRateLimited), errors.Is(err, ErrServer):
+		return userMessageUnavailable
+	case errors.Is(err, ErrNetwork):
+		return userMessageNetwork
+	case errors.Is(err, ErrNotFound):
+		return userMessageNotFound
+	case errors.Is(err, ErrInvalidRequest):
+		return userMessageInvalid
+	}
+	return userMessageGeneric
+}
@@ -0,0 +1,379 @@
+package openibank
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// WebhooksService provides access to the Webhooks API.
+type WebhooksService struct {
+	client *Client
+}
+
+// WebhookEndpoint is a URL that events are delivered to. An empty Events
+// receives all event types. Secret is the key that deliveries are signed
+// with; it is only returned when the endpoint is created. APIVersion is the
+// API version payloads are rendered in.
+type WebhookEndpoint struct {
+	ID          string      `json:"id"`
+	URL         string      `json:"url"`
+	Events      []EventType `json:"events,omitempty"`
+	Description *string     `json:"description,omitempty"`
+	Enabled     bool        `json:"enabled"`
+	APIVersion  string      `json:"api_version"`
+	Secret      *string     `json:"secret,omitempty"`
+	CreatedAt   *time.Time  `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time  `json:"updated_at,omitempty"`
+}
+
+// WebhookAPIVersions returns the API versions webhook payloads can be pinned
+// to: those the webhooks package decodes into the models of this SDK.
+func WebhookAPIVersions() []string {
+	return []string{"v2"}
+}
+
+// validateWebhookAPIVersion rejects a pin to an API version whose payloads
+// the webhooks package cannot decode.
+func validateWebhookAPIVersion(version *string) error {
+	if version == nil {
+		return nil
+	}
+	supported := WebhookAPIVersions()
+	for _, v := range supported {
+		if *version == v {
+			return nil
+		}
+	}
+	return &ValidationError{
+		Message: "unsupported webhook API version",
+		Code:    ErrCodeInvalidRequest,
+		Errors: []FieldError{{
+			Field:   "api_version",
+			Message: fmt.Sprintf("%q is not one of the supported versions %q", *version, supported),
+		}},
+	}
+}
+
+// WebhookCreateParams contains parameters for creating a webhook endpoint.
+// If Secret is nil, the platform generates one. APIVersion pins the version
+// payloads are rendered in; if nil, the client's API version is used, so that
+// payloads match the models of this SDK. It must be one of
+// WebhookAPIVersions.
+type WebhookCreateParams struct {
+	URL         string      `json:"url"`
+	Events      []EventType `json:"events,omitempty"`
+	Description *string     `json:"description,omitempty"`
+	Secret      *string     `json:"secret,omitempty"`
+	APIVersion  *string     `json:"api_version,omitempty"`
+}
+
+// WebhookUpdateParams contains parameters for updating a webhook endpoint.
+// Nil fields are left unchanged; a non-nil Events replaces the event types.
+// A non-nil APIVersion must be one of WebhookAPIVersions.
+type WebhookUpdateParams struct {
+	URL         *string      `json:"url,omitempty"`
+	Events      *[]EventType `json:"events,omitempty"`
+	Description *string      `json:"description,omitempty"`
+	Enabled     *bool        `json:"enabled,omitempty"`
+	APIVersion  *string      `json:"api_version,omitempty"`
+}
+
+// Create creates a webhook endpoint. Store the returned secret to verify
+// deliveries; it cannot be retrieved later.
+func (s *WebhooksService) Create(ctx context.Context, params WebhookCreateParams) (*WebhookEndpoint, error) {
+	if params.APIVersion == nil {
+		version := s.client.config.APIVersion
+		params.APIVersion = &version
+	}
+	if err := validateWebhookAPIVersion(params.APIVersion); err != nil {
+		return nil, err
+	}
+	var endpoint WebhookEndpoint
+	if err := s.client.request(ctx, "POST", "/webhooks", nil, params, &endpoint); err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// Get gets a webhook endpoint.
+func (s *WebhooksService) Get(ctx context.Context, webhookID string) (*WebhookEndpoint, error) {
+	var endpoint WebhookEndpoint
+	if err := s.client.request(ctx, "GET", "/webhooks/"+webhookID, nil, nil, &endpoint); err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// List lists all webhook endpoints.
+func (s *WebhooksService) List(ctx context.Context) ([]WebhookEndpoint, error) {
+	var result struct {
+		Webhooks []WebhookEndpoint `json:"webhooks"`
+	}
+	if err := s.client.request(ctx, "GET", "/webhooks", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Webhooks, nil
+}
+
+// Update updates a webhook endpoint.
+func (s *WebhooksService) Update(ctx context.Context, webhookID string, params WebhookUpdateParams) (*WebhookEndpoint, error) {
+	if err := validateWebhookAPIVersion(params.APIVersion); err != nil {
+		return nil, err
+	}
+	var endpoint WebhookEndpoint
+	if err := s.client.request(ctx, "PATCH", "/webhooks/"+webhookID, nil, params, &endpoint); err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// Delete deletes a webhook endpoint. Deliveries to it stop immediately.
+func (s *WebhooksService) Delete(ctx context.Context, webhookID string) error {
+	return s.client.request(ctx, "DELETE", "/webhooks/"+webhookID, nil, nil, nil)
+}
+
+// WebhookSecret is a newly issued webhook signing secret. Until
+// PreviousSecretExpiresAt, deliveries are signed with both the new and the
+// previous secret, so that receivers can switch to the new one without
+// rejecting any delivery.
+type WebhookSecret struct {
+	Secret                  string     `json:"secret"`
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at,omitempty"`
+}
+
+// RotateSecret issues a new signing secret for a webhook endpoint. The
+// previous secret stays valid for a rotation window; verify deliveries with
+// both until it ends, for example with webhooks.WithSecrets.
+func (s *WebhooksService) RotateSecret(ctx context.Context, webhookID string) (*WebhookSecret, error) {
+	var secret WebhookSecret
+	if err := s.client.request(ctx, "POST", "/webhooks/"+webhookID+"/rotate-secret", nil, nil, &secret); err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// WebhookDeliveryStatus represents the status of a webhook delivery.
+// Statuses added to the API after this version of the SDK are kept as is;
+// check them with Valid.
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryStatusPending means the delivery has not been attempted
+	// yet.
+	WebhookDeliveryStatusPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliveryStatusSucceeded means the endpoint acknowledged the
+	// event.
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryStatusRetrying means an attempt failed and another is
+	// scheduled.
+	WebhookDeliveryStatusRetrying WebhookDeliveryStatus = "retrying"
+	// WebhookDeliveryStatusFailed means all attempts failed and no more are
+	// scheduled.
+	WebhookDeliveryStatusFailed WebhookDeliveryStatus = "failed"
+)
+
+// Valid reports whether s is one of the statuses defined by this package.
+func (s WebhookDeliveryStatus) Valid() bool {
+	switch s {
+	case WebhookDeliveryStatusPending, WebhookDeliveryStatusSucceeded,
+		WebhookDeliveryStatusRetrying, WebhookDeliveryStatusFailed:
+		return true
+	}
+	return false
+}
+
+// IsKnown reports whether s is one of the statuses defined by this package.
+// It is the same as Valid.
+func (s WebhookDeliveryStatus) IsKnown() bool {
+	return s.Valid()
+}
+
+// WebhookDelivery is the delivery of one event to a webhook endpoint.
+// Attempts are listed oldest first; NextRetryAt is set while the delivery is
+// retrying.
+type WebhookDelivery struct {
+	ID          string                   `json:"id"`
+	WebhookID   string                   `json:"webhook_id"`
+	EventID     string                   `json:"event_id"`
+	EventType   EventType                `json:"event_type"`
+	Status      WebhookDeliveryStatus    `json:"status"`
+	Attempts    []WebhookDeliveryAttempt `json:"attempts,omitempty"`
+	NextRetryAt *time.Time               `json:"next_retry_at,omitempty"`
+	CreatedAt   *time.Time               `json:"created_at,omitempty"`
+}
+
+// WebhookDeliveryAttempt is one attempt to deliver an event. StatusCode is
+// nil if no response was received, in which case Error says why.
+// ResponseBody is the start of the endpoint's response.
+type WebhookDeliveryAttempt struct {
+	AttemptedAt  time.Time `json:"attempted_at"`
+	StatusCode   *int      `json:"status_code,omitempty"`
+	DurationMs   int       `json:"duration_ms"`
+	Error        *string   `json:"error,omitempty"`
+	ResponseBody *string   `json:"response_body,omitempty"`
+}
+
+// WebhookDeliveryListParams contains parameters for listing webhook
+// deliveries.
+type WebhookDeliveryListParams struct {
+	Status    *WebhookDeliveryStatus `url:"status"`
+	EventType *EventType             `url:"event_type"`
+	Since     *time.Time             `url:"since"`
+	Limit     *int                   `url:"limit"`
+	Offset    *int                   `url:"offset"`
+}
+
+// ListDeliveries lists the deliveries to a webhook endpoint, newest first.
+func (s *WebhooksService) ListDeliveries(ctx context.Context, webhookID string, params *WebhookDeliveryListParams) ([]WebhookDelivery, error) {
+	values := encodeQuery(params)
+
+	var result struct {
+		Deliveries []WebhookDelivery `json:"deliveries"`
+	}
+	if err := s.client.request(ctx, "GET", "/webhooks/"+webhookID+"/deliveries", values, nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Deliveries, nil
+}
+
+// GetDelivery gets a delivery to a webhook endpoint with all its attempts.
+func (s *WebhooksService) GetDelivery(ctx context.Context, webhookID, deliveryID string) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	if err := s.client.request(ctx, "GET", "/webhooks/"+webhookID+"/deliveries/"+deliveryID, nil, nil, &delivery); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// Redeliver requests a new delivery of a past event to a webhook endpoint,
+// whatever the outcome of earlier deliveries.
+func (s *WebhooksService) Redeliver(ctx context.Context, webhookID, eventID string) (*WebhookDelivery, error) {
+	body := map[string]string{"event_id": eventID}
+	var delivery WebhookDelivery
+	if err := s.client.request(ctx, "POST", "/webhooks/"+webhookID+"/redeliveries", nil, body, &delivery); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// WebhookReplay is a request to deliver the events of a time range again.
+// Events are delivered in the background, oldest first; EventCount is the
+// number of events in the range.
+type WebhookReplay struct {
+	ID         string    `json:"id"`
+	WebhookID  string    `json:"webhook_id"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	EventCount int       `json:"event_count"`
+	Status     string    `json:"status"`
+}
+
+// ReplayRange requests a new delivery of all events created between from and
+// to that the webhook endpoint is subscribed to, for example to recover from
+// an outage of the receiving service.
+func (s *WebhooksService) ReplayRange(ctx context.Context, webhookID string, from, to time.Time) (*WebhookReplay, error) {
+	body := map[string]string{
+		"from": from.Format(time.RFC3339),
+		"to":   to.Format(time.RFC3339),
+	}
+	var replay WebhookReplay
+	if err := s.client.request(ctx, "POST", "/webhooks/"+webhookID+"/replays", nil, body, &replay); err != nil {
+		return nil, err
+	}
+	return &replay, nil
+}
+
+// FailedWebhookEvent is an event whose delivery to a webhook endpoint failed
+// on every attempt. It stays listed until it is acknowledged. Payload is the
+// body the event was delivered with, which can be passed to
+// webhooks.UnmarshalEvent.
+type FailedWebhookEvent struct {
+	EventID    string          `json:"event_id"`
+	EventType  EventType       `json:"event_type"`
+	DeliveryID string          `json:"delivery_id"`
+	Attempts   int             `json:"attempts"`
+	LastError  *string         `json:"last_error,omitempty"`
+	FailedAt   time.Time       `json:"failed_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// ListFailed lists the unacknowledged events whose delivery to a webhook
+// endpoint exhausted its retries, oldest first.
+func (s *WebhooksService) ListFailed(ctx context.Context, webhookID string) ([]FailedWebhookEvent, error) {
+	var result struct {
+		Events []FailedWebhookEvent `json:"events"`
+	}
+	if err := s.client.request(ctx, "GET", "/webhooks/"+webhookID+"/failed-events", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Events, nil
+}
+
+// AcknowledgeFailed removes a failed event from ListFailed, typically once
+// it has been processed some other way or redelivered.
+func (s *WebhooksService) AcknowledgeFailed(ctx context.Context, webhookID, eventID string) error {
+	return s.client.request(ctx, "POST", "/webhooks/"+webhookID+"/failed-events/"+eventID+"/acknowledge", nil, nil, nil)
+}
+
+// WebhookRelay is a temporary webhook endpoint whose deliveries are held by
+// the platform until a local listener fetches them, so that webhooks can be
+// received during development without a public URL. Deliveries are signed
+// with Secret. The relay is removed at ExpiresAt if it is not deleted first.
+type WebhookRelay struct {
+	ID        string      `json:"id"`
+	Events    []EventType `json:"events,omitempty"`
+	Secret    string      `json:"secret"`
+	ExpiresAt *time.Time  `json:"expires_at,omitempty"`
+}
+
+// RelayedDelivery is a delivery held by a webhook relay, with the headers and
+// body it would have been sent with.
+type RelayedDelivery struct {
+	ID     string            `json:"id"`
+	Header map[string]string `json:"headers"`
+	Body   string            `json:"body"`
+}
+
+// CreateRelay creates a webhook relay for the given event types, or all if
+// events is empty. See the webhooks package's Listen for a ready-made
+// listener.
+func (s *WebhooksService) CreateRelay(ctx context.Context, events []EventType) (*WebhookRelay, error) {
+	body := map[string][]EventType{"events": events}
+	var relay WebhookRelay
+	if err := s.client.request(ctx, "POST", "/webhooks/relays", nil, body, &relay); err != nil {
+		return nil, err
+	}
+	return &relay, nil
+}
+
+// NextRelayedDeliveries waits up to wait for deliveries held by a relay and
+// returns them, or none if there were none in time. Deliveries are returned
+// again until they are acknowledged.
+func (s *WebhooksService) NextRelayedDeliveries(ctx context.Context, relayID string, wait time.Duration) ([]RelayedDelivery, error) {
+	values := url.Values{}
+	values.Set("wait", strconv.Itoa(int(wait/time.Second)))
+	var result struct {
+		Deliveries []RelayedDelivery `json:"deliveries"`
+	}
+	if err := s.client.request(ctx, "GET", "/webhooks/relays/"+relayID+"/deliveries", values, nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Deliveries, nil
+}
+
+// AcknowledgeRelayedDelivery reports the status code a relayed delivery was
+// handled with. Deliveries acknowledged with an error status are retried as
+// regular deliveries are.
+func (s *WebhooksService) AcknowledgeRelayedDelivery(ctx context.Context, relayID, deliveryID string, statusCode int) error {
+	body := map[string]int{"status_code": statusCode}
+	return s.client.request(ctx, "POST", "/webhooks/relays/"+relayID+"/deliveries/"+deliveryID+"/ack", nil, body, nil)
+}
+
+// DeleteRelay deletes a webhook relay and the deliveries it holds.
+func (s *WebhooksService) DeleteRelay(ctx context.Context, relayID string) error {
+	return s.client.request(ctx, "DELETE", "/webhooks/relays/"+relayID, nil, nil, nil)
+}
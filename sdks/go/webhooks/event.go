@@ -0,0 +1,118 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	openibank "github.com/openibank/sdk-go"
+)
+
+// errMalformedPayload is returned for a delivery that cannot be decoded.
+var errMalformedPayload = errors.New("webhooks: malformed payload")
+
+// ErrUnsupportedAPIVersion is returned for a delivery rendered in an API
+// version this version of the SDK cannot decode. Pin the endpoint to one of
+// openibank.WebhookAPIVersions with WebhookUpdateParams.APIVersion.
+var ErrUnsupportedAPIVersion = errors.New("webhooks: unsupported API version")
+
+// payloadDecoders decode event data rendered in each of
+// openibank.WebhookAPIVersions into the SDK's models. Deliveries without a
+// version predate version pinning and are rendered in v2.
+var payloadDecoders = map[string]func(data json.RawMessage, v interface{}) error{
+	"":   decodeV2,
+	"v2": decodeV2,
+}
+
+// decodeV2 decodes v2 event data, which matches the SDK's models.
+func decodeV2(data json.RawMessage, v interface{}) error {
+	return openibank.DecodeJSON(data, v)
+}
+
+// Event is the envelope of a webhook delivery. Data holds the event's
+// payload, decoded by the accessor for its type. APIVersion is the version
+// the payload is rendered in.
+type Event struct {
+	ID         string              `json:"id"`
+	Type       openibank.EventType `json:"type"`
+	Created    time.Time           `json:"created"`
+	APIVersion string              `json:"api_version"`
+	Data       json.RawMessage     `json:"data"`
+}
+
+// UnmarshalEvent decodes the body of a webhook delivery. It does not verify
+// the signature; use Verify first. The event's data is decoded according to
+// its API version; deliveries in versions the SDK cannot decode are rejected
+// with ErrUnsupportedAPIVersion.
+func UnmarshalEvent(payload []byte) (Event, error) {
+	var event Event
+	if err := openibank.DecodeJSON(payload, &event); err != nil {
+		return Event{}, fmt.Errorf("%w: %v", errMalformedPayload, err)
+	}
+	if event.ID == "" || event.Type == "" {
+		return Event{}, fmt.Errorf("%w: no event ID or type", errMalformedPayload)
+	}
+	if _, ok := payloadDecoders[event.APIVersion]; !ok {
+		return Event{}, fmt.Errorf("%w: %s", ErrUnsupportedAPIVersion, event.APIVersion)
+	}
+	return event, nil
+}
+
+// AsTransaction decodes a transaction.created or transaction.updated event.
+func (e Event) AsTransaction() (openibank.TransactionEvent, error) {
+	var data openibank.Transaction
+	if err := e.decode(&data, openibank.EventTransactionCreated, openibank.EventTransactionUpdated); err != nil {
+		return openibank.TransactionEvent{}, err
+	}
+	return openibank.TransactionEvent{ID: e.ID, Type: e.Type, Data: data, Timestamp: e.Created}, nil
+}
+
+// AsBalance decodes a balance.updated event.
+func (e Event) AsBalance() (openibank.BalanceEvent, error) {
+	var data openibank.Balance
+	if err := e.decode(&data, openibank.EventBalanceUpdated); err != nil {
+		return openibank.BalanceEvent{}, err
+	}
+	return openibank.BalanceEvent{ID: e.ID, Type: e.Type, Data: data, Timestamp: e.Created}, nil
+}
+
+// AsPayment decodes a payment.status_changed event.
+func (e Event) AsPayment() (openibank.PaymentEvent, error) {
+	var data openibank.Payment
+	if err := e.decode(&data, openibank.EventPaymentStatusChanged); err != nil {
+		return openibank.PaymentEvent{}, err
+	}
+	return openibank.PaymentEvent{ID: e.ID, Type: e.Type, Data: data, Timestamp: e.Created}, nil
+}
+
+// AsConsent decodes a consent.revoked event.
+func (e Event) AsConsent() (openibank.ConsentEvent, error) {
+	var data openibank.ConsentEventData
+	if err := e.decode(&data, openibank.EventConsentRevoked); err != nil {
+		return openibank.ConsentEvent{}, err
+	}
+	return openibank.ConsentEvent{ID: e.ID, Type: e.Type, Data: data, Timestamp: e.Created}, nil
+}
+
+// decode decodes the event's data into v if the event is of one of types.
+func (e Event) decode(v interface{}, types ...openibank.EventType) error {
+	matches := false
+	for _, t := range types {
+		if e.Type == t {
+			matches = true
+			break
+		}
+	}
+	if !matches {
+		return fmt.Errorf("webhooks: event %s is of type %s", e.ID, e.Type)
+	}
+	decode, ok := payloadDecoders[e.APIVersion]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedAPIVersion, e.APIVersion)
+	}
+	if err := decode(e.Data, v); err != nil {
+		return fmt.Errorf("%w: %v", errMalformedPayload, err)
+	}
+	return nil
+}
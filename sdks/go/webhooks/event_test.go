@@ -0,0 +1,40 @@
+package webhooks
+
+import (
+	"errors"
+	"testing"
+
+	openibank "github.com/openibank/sdk-go"
+)
+
+func TestEveryWebhookAPIVersionHasADecoder(t *testing.T) {
+	for _, version := range openibank.WebhookAPIVersions() {
+		if _, ok := payloadDecoders[version]; !ok {
+			t.Errorf("no payload decoder for webhook API version %s", version)
+		}
+	}
+}
+
+func TestUnmarshalEventVersions(t *testing.T) {
+	for _, version := range append(openibank.WebhookAPIVersions(), "") {
+		payload := `{"id":"evt_1","type":"payment.status_changed","api_version":"` + version + `","data":{"id":"pay_1","status":"ACSC"}}`
+		event, err := UnmarshalEvent([]byte(payload))
+		if err != nil {
+			t.Errorf("version %q: %v", version, err)
+			continue
+		}
+		payment, err := event.AsPayment()
+		if err != nil {
+			t.Errorf("version %q: %v", version, err)
+			continue
+		}
+		if payment.Data.ID != "pay_1" {
+			t.Errorf("version %q: decoded payment %q, want pay_1", version, payment.Data.ID)
+		}
+	}
+
+	payload := `{"id":"evt_1","type":"payment.status_changed","api_version":"v3-preview","data":{"id":"pay_1"}}`
+	if _, err := UnmarshalEvent([]byte(payload)); !errors.Is(err, ErrUnsupportedAPIVersion) {
+		t.Errorf("err = %v, want ErrUnsupportedAPIVersion", err)
+	}
+}
@@ -0,0 +1,239 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	openibank "github.com/openibank/sdk-go"
+)
+
+// maxPayloadSize is the largest delivery body a handler accepts.
+const maxPayloadSize = 1 << 20
+
+// Handlers contains handlers for webhook events. Returning an error fails
+// the delivery, so that it is retried later; handlers should therefore be
+// idempotent. OnUnknownEvent receives events of types this version of the
+// SDK does not know, with the raw delivery body. Events without a handler
+// are acknowledged and dropped.
+type Handlers struct {
+	OnTransactionCreated   func(context.Context, openibank.TransactionEvent) error
+	OnTransactionUpdated   func(context.Context, openibank.TransactionEvent) error
+	OnBalanceUpdated       func(context.Context, openibank.BalanceEvent) error
+	OnPaymentStatusChanged func(context.Context, openibank.PaymentEvent) error
+	OnConsentRevoked       func(context.Context, openibank.ConsentEvent) error
+	OnUnknownEvent         func(ctx context.Context, eventType string, raw json.RawMessage) error
+}
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithTolerance sets how old a delivery's signature may be. The default is
+// DefaultTolerance; zero disables the check.
+func WithTolerance(tolerance time.Duration) Option {
+	return func(h *Handler) {
+		h.tolerance = tolerance
+	}
+}
+
+// WithSecrets adds secrets that deliveries may also be signed with, such as
+// the previous secret while it is being rotated.
+func WithSecrets(secrets ...string) Option {
+	return func(h *Handler) {
+		h.secrets = append(h.secrets, secrets...)
+	}
+}
+
+// WithJWKS verifies deliveries that carry a JWS signature with the keys in
+// keys. Deliveries signed with an HMAC are still verified with the secrets,
+// if the handler has any, so that endpoints can be switched to asymmetric
+// signing one at a time.
+func WithJWKS(keys *JWKS) Option {
+	return func(h *Handler) {
+		h.jwks = keys
+	}
+}
+
+// RequireJWS rejects deliveries without a JWS signature, even if the handler
+// has secrets, once every endpoint signs with the keys set WithJWKS.
+func RequireJWS() Option {
+	return func(h *Handler) {
+		h.requireJWS = true
+	}
+}
+
+// WithStore skips events already recorded in store and records events once
+// they are handled, so that events delivered more than once are handled
+// once.
+func WithStore(store Store) Option {
+	return func(h *Handler) {
+		h.store = store
+	}
+}
+
+// Handler is an http.Handler that receives webhook deliveries. It responds
+// with 200 once the event has been handled, 401 if the signature is invalid,
+// 400 if the delivery cannot be decoded, 413 if it is too large and 500 if a
+// handler fails or the signing keys or the store cannot be reached. Events
+// already processed are acknowledged with 200 without being handled.
+type Handler struct {
+	secrets    []string
+	jwks       *JWKS
+	requireJWS bool
+	store      Store
+	handlers   Handlers
+	tolerance  time.Duration
+}
+
+// NewHandler returns a Handler that verifies deliveries with the endpoint's
+// secret and passes their events to handlers. The secret may only be empty
+// for endpoints signing with a JWS, verified with the keys set WithJWKS;
+// such a handler rejects deliveries without a JWS signature. NewHandler
+// panics if the handler has neither a secret nor keys, or RequireJWS
+// without keys, as it could not verify any delivery.
+func NewHandler(secret string, handlers Handlers, opts ...Option) *Handler {
+	h := &Handler{
+		secrets:   []string{secret},
+		handlers:  handlers,
+		tolerance: DefaultTolerance,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	// An empty secret would accept deliveries signed with an empty key.
+	secrets := h.secrets[:0]
+	for _, s := range h.secrets {
+		if s != "" {
+			secrets = append(secrets, s)
+		}
+	}
+	h.secrets = secrets
+	if h.jwks == nil && (len(h.secrets) == 0 || h.requireJWS) {
+		panic("webhooks: NewHandler needs a secret, or keys set WithJWKS")
+	}
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "failed to read payload", http.StatusBadRequest)
+		return
+	}
+	if err := h.verify(r, payload); err != nil {
+		if errors.Is(err, ErrInvalidSignature) || errors.Is(err, ErrTimestampOutOfTolerance) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		http.Error(w, "failed to verify signature", http.StatusInternalServerError)
+		return
+	}
+
+	event, err := UnmarshalEvent(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if h.store != nil {
+		processed, err := h.store.Processed(r.Context(), event.ID)
+		if err != nil {
+			http.Error(w, "failed to check event", http.StatusInternalServerError)
+			return
+		}
+		if processed {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+	if err := h.dispatch(r.Context(), event, payload); err != nil {
+		if errors.Is(err, errMalformedPayload) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "failed to handle event", http.StatusInternalServerError)
+		return
+	}
+	if h.store != nil {
+		// The event has been handled, so it is acknowledged even if it cannot
+		// be recorded; at worst a later delivery is handled again.
+		_ = h.store.MarkProcessed(r.Context(), event.ID)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// verify checks the JWS signature of a delivery if it has one and keys are
+// configured, and its HMAC signature otherwise, unless a JWS is required.
+func (h *Handler) verify(r *http.Request, payload []byte) error {
+	if signature := r.Header.Get(JWSSignatureHeader); signature != "" && h.jwks != nil {
+		return VerifyJWS(r.Context(), payload, signature, h.jwks, h.tolerance)
+	}
+	if h.requireJWS || len(h.secrets) == 0 {
+		return fmt.Errorf("%w: JWS signature required", ErrInvalidSignature)
+	}
+	return VerifyAny(payload, r.Header.Get(SignatureHeader), h.secrets, h.tolerance)
+}
+
+// dispatch passes an event to the matching handler.
+func (h *Handler) dispatch(ctx context.Context, event Event, payload []byte) error {
+	switch event.Type {
+	case openibank.EventTransactionCreated, openibank.EventTransactionUpdated:
+		handle := h.handlers.OnTransactionCreated
+		if event.Type == openibank.EventTransactionUpdated {
+			handle = h.handlers.OnTransactionUpdated
+		}
+		if handle == nil {
+			return nil
+		}
+		e, err := event.AsTransaction()
+		if err != nil {
+			return err
+		}
+		return handle(ctx, e)
+	case openibank.EventBalanceUpdated:
+		if h.handlers.OnBalanceUpdated == nil {
+			return nil
+		}
+		e, err := event.AsBalance()
+		if err != nil {
+			return err
+		}
+		return h.handlers.OnBalanceUpdated(ctx, e)
+	case openibank.EventPaymentStatusChanged:
+		if h.handlers.OnPaymentStatusChanged == nil {
+			return nil
+		}
+		e, err := event.AsPayment()
+		if err != nil {
+			return err
+		}
+		return h.handlers.OnPaymentStatusChanged(ctx, e)
+	case openibank.EventConsentRevoked:
+		if h.handlers.OnConsentRevoked == nil {
+			return nil
+		}
+		e, err := event.AsConsent()
+		if err != nil {
+			return err
+		}
+		return h.handlers.OnConsentRevoked(ctx, e)
+	default:
+		if h.handlers.OnUnknownEvent == nil {
+			return nil
+		}
+		return h.handlers.OnUnknownEvent(ctx, string(event.Type), payload)
+	}
+}
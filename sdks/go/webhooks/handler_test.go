@@ -0,0 +1,69 @@
+package webhooks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPayload = `{"id":"evt_1","type":"payment.status_changed","data":{"id":"pay_1","status":"ACSC"}}`
+
+func deliver(h *Handler, header string) int {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(testPayload))
+	if header != "" {
+		req.Header.Set(SignatureHeader, header)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestHandlerVerifiesHMAC(t *testing.T) {
+	h := NewHandler("whsec_test", Handlers{})
+	if code := deliver(h, Sign([]byte(testPayload), "whsec_test", time.Now())); code != http.StatusOK {
+		t.Errorf("signed delivery: status %d, want 200", code)
+	}
+	if code := deliver(h, Sign([]byte(testPayload), "whsec_other", time.Now())); code != http.StatusUnauthorized {
+		t.Errorf("delivery signed with another secret: status %d, want 401", code)
+	}
+}
+
+func TestHandlerRejectsEmptyKeyDowngrade(t *testing.T) {
+	keys := NewJWKS("https://example.invalid/jwks.json", nil)
+	forged := Sign([]byte(testPayload), "", time.Now())
+
+	for name, h := range map[string]*Handler{
+		"no secret":   NewHandler("", Handlers{}, WithJWKS(keys)),
+		"require JWS": NewHandler("whsec_test", Handlers{}, WithJWKS(keys), RequireJWS()),
+	} {
+		if code := deliver(h, forged); code != http.StatusUnauthorized {
+			t.Errorf("%s: delivery signed with an empty key: status %d, want 401", name, code)
+		}
+		if code := deliver(h, Sign([]byte(testPayload), "whsec_test", time.Now())); code != http.StatusUnauthorized {
+			t.Errorf("%s: delivery without a JWS: status %d, want 401", name, code)
+		}
+	}
+}
+
+func TestNewHandlerPanicsWithoutKeys(t *testing.T) {
+	for name, opts := range map[string][]Option{
+		"empty secret":       nil,
+		"empty extra secret": {WithSecrets("")},
+		"require JWS":        {RequireJWS()},
+	} {
+		secret := ""
+		if name == "require JWS" {
+			secret = "whsec_test"
+		}
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: NewHandler did not panic", name)
+				}
+			}()
+			NewHandler(secret, Handlers{}, opts...)
+		}()
+	}
+}
@@ -0,0 +1,283 @@
+package webhooks
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JWSSignatureHeader is the header that carries the detached JWS signature
+// of a delivery to an endpoint with asymmetric signing. Deliveries to other
+// endpoints are signed with an HMAC in SignatureHeader.
+const JWSSignatureHeader = "OpeniBank-JWS-Signature"
+
+// JWKS URLs of the platform's webhook signing keys.
+const (
+	ProductionJWKSURL = "https://api.openibank.com/.well-known/webhooks/jwks.json"
+	SandboxJWKSURL    = "https://sandbox.openibank.com/.well-known/webhooks/jwks.json"
+)
+
+// jwksTTL is how long fetched keys are used before they are fetched again.
+const jwksTTL = time.Hour
+
+// jwksMinRefresh is how often at most keys are fetched again because a
+// delivery was signed with an unknown key, or because the last fetch failed.
+const jwksMinRefresh = time.Minute
+
+// jwksFetchTimeout bounds each fetch of the key set.
+const jwksFetchTimeout = 10 * time.Second
+
+// JWKS is the platform's set of webhook signing keys, fetched from a JSON Web
+// Key Set URL and cached. Keys are fetched again after an hour, or sooner
+// when a delivery is signed with a key not in the set, so that key rotations
+// are picked up. Concurrent deliveries share a single fetch, and a failed
+// fetch is not retried for a minute. A JWKS is safe for concurrent use.
+type JWKS struct {
+	url        string
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetched   time.Time
+	attempted time.Time
+	lastErr   error
+	// inflight is closed when the running fetch ends, or nil.
+	inflight chan struct{}
+}
+
+// NewJWKS returns a key set fetched from url with httpClient, or with a
+// client that times out after 10 seconds if it is nil.
+func NewJWKS(url string, httpClient *http.Client) *JWKS {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: jwksFetchTimeout}
+	}
+	return &JWKS{url: url, httpClient: httpClient, now: time.Now}
+}
+
+// key returns the public key with the given ID. The key set is fetched
+// without holding the lock; callers arriving meanwhile wait for that fetch
+// instead of starting their own.
+func (k *JWKS) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	for {
+		k.mu.Lock()
+		now := k.now()
+		key, ok := k.keys[kid]
+		if ok && now.Sub(k.fetched) < jwksTTL {
+			k.mu.Unlock()
+			return key, nil
+		}
+		if inflight := k.inflight; inflight != nil {
+			k.mu.Unlock()
+			select {
+			case <-inflight:
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		if now.Sub(k.attempted) < jwksMinRefresh {
+			err := k.lastErr
+			k.mu.Unlock()
+			switch {
+			case ok:
+				// A known key stays usable while the key set is unreachable.
+				return key, nil
+			case err != nil:
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidSignature, kid)
+		}
+		inflight := make(chan struct{})
+		k.inflight = inflight
+		k.attempted = now
+		k.mu.Unlock()
+
+		// The fetch is shared, so it is not cancelled with this caller's
+		// context.
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jwksFetchTimeout)
+		keys, err := k.fetch(fetchCtx)
+		cancel()
+
+		k.mu.Lock()
+		if err == nil {
+			k.keys = keys
+			k.fetched = k.now()
+		}
+		k.lastErr = err
+		k.inflight = nil
+		close(inflight)
+		k.mu.Unlock()
+	}
+}
+
+// fetch returns the keys at the key set URL.
+func (k *JWKS) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", k.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("webhooks: failed to create key set request: %w", err)
+	}
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("webhooks: failed to fetch key set: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("webhooks: failed to fetch key set: %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("webhooks: failed to decode key set: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		// Keys of unsupported types are skipped, so that new key types can
+		// be added to the set.
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+// jwk is a public key in a JSON Web Key Set.
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (j jwk) publicKey() (crypto.PublicKey, error) {
+	switch {
+	case j.Kty == "RSA":
+		n, err1 := base64.RawURLEncoding.DecodeString(j.N)
+		e, err2 := base64.RawURLEncoding.DecodeString(j.E)
+		if err1 != nil || err2 != nil || len(e) > 4 {
+			return nil, errors.New("invalid RSA key")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case j.Kty == "EC" && j.Crv == "P-256":
+		x, err1 := base64.RawURLEncoding.DecodeString(j.X)
+		y, err2 := base64.RawURLEncoding.DecodeString(j.Y)
+		if err1 != nil || err2 != nil {
+			return nil, errors.New("invalid EC key")
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("invalid EC key")
+		}
+		return key, nil
+	case j.Kty == "OKP" && j.Crv == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", j.Kty)
+}
+
+// jwsHeader is the protected header of a delivery's JWS. B64 false means
+// the payload is signed as is (RFC 7797) rather than base64url-encoded. Iat
+// is when the delivery was signed.
+type jwsHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	B64 *bool  `json:"b64"`
+	Iat int64  `json:"iat"`
+}
+
+// VerifyJWS checks the detached JWS signature of a delivery, in the form
+// "<protected header>..<signature>", with the key set, rejecting signatures
+// older than tolerance. The protected header must then say when the
+// delivery was signed in its iat; a tolerance of zero skips the age check.
+// RS256, PS256, ES256 and EdDSA signatures are supported.
+func VerifyJWS(ctx context.Context, payload []byte, signature string, keys *JWKS, tolerance time.Duration) error {
+	encodedHeader, encodedSignature, ok := strings.Cut(signature, "..")
+	if !ok {
+		return ErrInvalidSignature
+	}
+	rawHeader, err := base64.RawURLEncoding.DecodeString(encodedHeader)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	var header jwsHeader
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return ErrInvalidSignature
+	}
+
+	key, err := keys.key(ctx, header.Kid)
+	if err != nil {
+		return err
+	}
+	signingInput := encodedHeader + "."
+	if header.B64 != nil && !*header.B64 {
+		signingInput += string(payload)
+	} else {
+		signingInput += base64.RawURLEncoding.EncodeToString(payload)
+	}
+	if !verifyJWSSignature(header.Alg, key, []byte(signingInput), sig) {
+		return ErrInvalidSignature
+	}
+
+	if tolerance > 0 {
+		if header.Iat == 0 {
+			return fmt.Errorf("%w: no iat in the protected header", ErrTimestampOutOfTolerance)
+		}
+		age := time.Since(time.Unix(header.Iat, 0))
+		if age > tolerance || age < -tolerance {
+			return ErrTimestampOutOfTolerance
+		}
+	}
+	return nil
+}
+
+// verifyJWSSignature reports whether sig is a valid signature of input with
+// key under the given algorithm.
+func verifyJWSSignature(alg string, key crypto.PublicKey, input, sig []byte) bool {
+	digest := sha256.Sum256(input)
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		switch alg {
+		case "RS256":
+			return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil
+		case "PS256":
+			return rsa.VerifyPSS(k, crypto.SHA256, digest[:], sig, nil) == nil
+		}
+	case *ecdsa.PublicKey:
+		if alg == "ES256" && len(sig) == 64 {
+			r := new(big.Int).SetBytes(sig[:32])
+			s := new(big.Int).SetBytes(sig[32:])
+			return ecdsa.Verify(k, digest[:], r, s)
+		}
+	case ed25519.PublicKey:
+		if alg == "EdDSA" {
+			return ed25519.Verify(k, input, sig)
+		}
+	}
+	return false
+}
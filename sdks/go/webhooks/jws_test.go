@@ -0,0 +1,284 @@
+package webhooks
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var b64 = base64.RawURLEncoding.EncodeToString
+
+// serveJWKS serves set as a JSON Web Key Set and returns its URL.
+func serveJWKS(t *testing.T, set interface{}) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// signJWS returns the detached JWS of payload with header, signed by sign.
+func signJWS(t *testing.T, header map[string]interface{}, payload []byte, sign func(input []byte) []byte) string {
+	t.Helper()
+	rawHeader, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodedHeader := base64.RawURLEncoding.EncodeToString(rawHeader)
+	input := encodedHeader + "."
+	if b64, ok := header["b64"].(bool); ok && !b64 {
+		input += string(payload)
+	} else {
+		input += base64.RawURLEncoding.EncodeToString(payload)
+	}
+	return encodedHeader + ".." + base64.RawURLEncoding.EncodeToString(sign([]byte(input)))
+}
+
+func TestVerifyJWSRequiresIat(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := NewJWKS(serveJWKS(t, map[string]interface{}{"keys": []map[string]string{{
+		"kid": "ed_1", "kty": "OKP", "crv": "Ed25519", "x": b64(public),
+	}}}), nil)
+	sign := func(input []byte) []byte { return ed25519.Sign(private, input) }
+	payload := []byte(testPayload)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		header    map[string]interface{}
+		tolerance time.Duration
+		want      error
+	}{
+		{"recent", map[string]interface{}{"alg": "EdDSA", "kid": "ed_1", "iat": time.Now().Unix()}, DefaultTolerance, nil},
+		{"old", map[string]interface{}{"alg": "EdDSA", "kid": "ed_1", "iat": time.Now().Add(-time.Hour).Unix()}, DefaultTolerance, ErrTimestampOutOfTolerance},
+		{"no iat", map[string]interface{}{"alg": "EdDSA", "kid": "ed_1"}, DefaultTolerance, ErrTimestampOutOfTolerance},
+		{"no iat without tolerance", map[string]interface{}{"alg": "EdDSA", "kid": "ed_1"}, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyJWS(ctx, payload, signJWS(t, tt.header, payload, sign), keys, tt.tolerance)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("VerifyJWS = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyJWSAlgorithms(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublic, edPrivate, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := NewJWKS(serveJWKS(t, map[string]interface{}{"keys": []map[string]string{
+		{"kid": "rsa_1", "kty": "RSA", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kid": "ec_1", "kty": "EC", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+		{"kid": "ed_1", "kty": "OKP", "crv": "Ed25519", "x": b64(edPublic)},
+	}}), nil)
+
+	signRS256 := func(input []byte) []byte {
+		digest := sha256.Sum256(input)
+		sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+	signES256 := func(input []byte) []byte {
+		digest := sha256.Sum256(input)
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	signEdDSA := func(input []byte) []byte { return ed25519.Sign(edPrivate, input) }
+
+	payload := []byte(testPayload)
+	tests := []struct {
+		name    string
+		header  map[string]interface{}
+		sign    func([]byte) []byte
+		payload []byte
+		valid   bool
+	}{
+		{"RS256", map[string]interface{}{"alg": "RS256", "kid": "rsa_1"}, signRS256, payload, true},
+		{"ES256", map[string]interface{}{"alg": "ES256", "kid": "ec_1"}, signES256, payload, true},
+		{"EdDSA", map[string]interface{}{"alg": "EdDSA", "kid": "ed_1"}, signEdDSA, payload, true},
+		{"unencoded payload", map[string]interface{}{"alg": "EdDSA", "kid": "ed_1", "b64": false, "crit": []string{"b64"}}, signEdDSA, payload, true},
+		{"tampered payload", map[string]interface{}{"alg": "RS256", "kid": "rsa_1"}, signRS256, []byte(`{"id":"evt_2"}`), false},
+		{"algorithm not matching the key", map[string]interface{}{"alg": "RS256", "kid": "ec_1"}, signES256, payload, false},
+		{"unknown key", map[string]interface{}{"alg": "EdDSA", "kid": "ed_2"}, signEdDSA, payload, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature := signJWS(t, tt.header, payload, tt.sign)
+			err := VerifyJWS(context.Background(), tt.payload, signature, keys, 0)
+			if tt.valid && err != nil {
+				t.Errorf("VerifyJWS = %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("VerifyJWS = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestJWKPublicKey(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := b64(ecKey.X.FillBytes(make([]byte, 32)))
+	y := b64(ecKey.Y.FillBytes(make([]byte, 32)))
+	edPublic, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		key   jwk
+		valid bool
+	}{
+		{"RSA", jwk{Kty: "RSA", N: b64([]byte{0xc5, 0x01}), E: "AQAB"}, true},
+		{"RSA with malformed modulus", jwk{Kty: "RSA", N: "***", E: "AQAB"}, false},
+		{"RSA with oversized exponent", jwk{Kty: "RSA", N: b64([]byte{0xc5}), E: b64([]byte{1, 0, 0, 0, 1})}, false},
+		{"P-256", jwk{Kty: "EC", Crv: "P-256", X: x, Y: y}, true},
+		{"P-256 point not on the curve", jwk{Kty: "EC", Crv: "P-256", X: x, Y: x}, false},
+		{"P-384", jwk{Kty: "EC", Crv: "P-384", X: x, Y: y}, false},
+		{"Ed25519", jwk{Kty: "OKP", Crv: "Ed25519", X: b64(edPublic)}, true},
+		{"Ed25519 of the wrong size", jwk{Kty: "OKP", Crv: "Ed25519", X: b64(edPublic[:31])}, false},
+		{"unsupported type", jwk{Kty: "oct"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.key.publicKey()
+			if (err == nil) != tt.valid {
+				t.Errorf("publicKey() error = %v, want valid %t", err, tt.valid)
+			}
+		})
+	}
+}
+
+// keySetServer serves a key set with one Ed25519 key, counting requests.
+// While fail is set it answers 503.
+type keySetServer struct {
+	requests atomic.Int32
+	fail     atomic.Bool
+	release  chan struct{}
+	public   ed25519.PublicKey
+}
+
+func (s *keySetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests.Add(1)
+	if s.release != nil {
+		<-s.release
+	}
+	if s.fail.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{
+		{"kid": "ed_1", "kty": "OKP", "crv": "Ed25519", "x": b64(s.public)},
+	}})
+}
+
+func newKeySetServer(t *testing.T) (*keySetServer, string) {
+	t.Helper()
+	public, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &keySetServer{public: public}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return s, server.URL
+}
+
+func TestJWKSSharesFetches(t *testing.T) {
+	s, url := newKeySetServer(t)
+	s.release = make(chan struct{})
+	keys := NewJWKS(url, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := keys.key(context.Background(), "ed_1"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	for s.requests.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(s.release)
+	wg.Wait()
+	if n := s.requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want 1", n)
+	}
+}
+
+func TestJWKSBacksOffAfterFailure(t *testing.T) {
+	s, url := newKeySetServer(t)
+	keys := NewJWKS(url, nil)
+	now := time.Date(2030, 1, 31, 12, 0, 0, 0, time.UTC)
+	keys.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	s.fail.Store(true)
+	for i := 0; i < 3; i++ {
+		if _, err := keys.key(ctx, "ed_1"); err == nil {
+			t.Fatal("key() succeeded although the key set is unavailable")
+		}
+	}
+	if n := s.requests.Load(); n != 1 {
+		t.Fatalf("made %d requests within a minute of a failure, want 1", n)
+	}
+
+	s.fail.Store(false)
+	now = now.Add(jwksMinRefresh)
+	if _, err := keys.key(ctx, "ed_1"); err != nil {
+		t.Fatalf("key() after the back-off: %v", err)
+	}
+
+	// A known key stays usable when a refresh after its TTL fails.
+	s.fail.Store(true)
+	now = now.Add(jwksTTL)
+	for i := 0; i < 3; i++ {
+		if _, err := keys.key(ctx, "ed_1"); err != nil {
+			t.Fatalf("key() with a failed refresh: %v", err)
+		}
+	}
+	if n := s.requests.Load(); n != 3 {
+		t.Errorf("made %d requests, want 3", n)
+	}
+}
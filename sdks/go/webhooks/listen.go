@@ -0,0 +1,113 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	openibank "github.com/openibank/sdk-go"
+)
+
+// listenWait is how long each request for relayed deliveries waits for one.
+const listenWait = 20 * time.Second
+
+// Listen receives webhooks on a development machine without a public URL. It
+// creates a temporary relay endpoint on the platform for the event types that
+// have a handler, then fetches the deliveries made to it and handles them as
+// a Handler would, including signature verification, until ctx is done. The
+// relay is then deleted and ctx.Err() is returned. Any other error ends
+// Listen as well.
+//
+// Listen is meant for development; in production, serve a Handler.
+func Listen(ctx context.Context, client *openibank.Client, handlers Handlers, opts ...Option) error {
+	relay, err := client.Webhooks.CreateRelay(ctx, handlers.eventTypes())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = client.Webhooks.DeleteRelay(cleanupCtx, relay.ID)
+	}()
+
+	if relay.Secret == "" {
+		return errors.New("webhooks: relay has no signing secret")
+	}
+	handler := NewHandler(relay.Secret, handlers, opts...)
+	for {
+		deliveries, err := client.Webhooks.NextRelayedDeliveries(ctx, relay.ID, listenWait)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		for _, delivery := range deliveries {
+			status := serveRelayed(ctx, handler, delivery)
+			if err := client.Webhooks.AcknowledgeRelayedDelivery(ctx, relay.ID, delivery.ID, status); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return err
+			}
+		}
+	}
+}
+
+// serveRelayed passes a relayed delivery to handler as if it had been
+// received over HTTP and returns the status code of the response.
+func serveRelayed(ctx context.Context, handler http.Handler, delivery openibank.RelayedDelivery) int {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", strings.NewReader(delivery.Body))
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	for key, value := range delivery.Header {
+		req.Header.Set(key, value)
+	}
+	w := &statusRecorder{header: http.Header{}, status: http.StatusOK}
+	handler.ServeHTTP(w, req)
+	return w.status
+}
+
+// statusRecorder is a ResponseWriter that only keeps the status code.
+type statusRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusRecorder) Header() http.Header { return w.header }
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return len(b), nil
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+}
+
+// eventTypes returns the event types that have a handler, or nil for all if
+// unknown events are handled too.
+func (h Handlers) eventTypes() []openibank.EventType {
+	if h.OnUnknownEvent != nil {
+		return nil
+	}
+	var types []openibank.EventType
+	add := func(set bool, t openibank.EventType) {
+		if set {
+			types = append(types, t)
+		}
+	}
+	add(h.OnTransactionCreated != nil, openibank.EventTransactionCreated)
+	add(h.OnTransactionUpdated != nil, openibank.EventTransactionUpdated)
+	add(h.OnBalanceUpdated != nil, openibank.EventBalanceUpdated)
+	add(h.OnPaymentStatusChanged != nil, openibank.EventPaymentStatusChanged)
+	add(h.OnConsentRevoked != nil, openibank.EventConsentRevoked)
+	return types
+}
@@ -0,0 +1,163 @@
+package webhooks
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Store records the IDs of processed events, so that a Handler configured
+// with WithStore skips events the platform delivers more than once. An event
+// is recorded only once its handler succeeds, so failed events are still
+// retried. Two deliveries of one event that arrive at the same time may both
+// be processed; handlers that cannot tolerate that need their own locking.
+type Store interface {
+	// Processed reports whether the event with the given ID was recorded.
+	Processed(ctx context.Context, eventID string) (bool, error)
+	// MarkProcessed records the event with the given ID.
+	MarkProcessed(ctx context.Context, eventID string) error
+}
+
+// NewMemoryStore returns a Store that remembers the last size event IDs in
+// memory. It only deduplicates deliveries to one process; use a shared store
+// when several processes receive deliveries.
+func NewMemoryStore(size int) Store {
+	if size < 1 {
+		size = 1
+	}
+	return &memoryStore{
+		size:  size,
+		order: list.New(),
+		ids:   make(map[string]*list.Element, size),
+	}
+}
+
+// memoryStore is a least-recently-used set of event IDs.
+type memoryStore struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	ids   map[string]*list.Element
+}
+
+func (m *memoryStore) Processed(ctx context.Context, eventID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	elem, ok := m.ids[eventID]
+	if ok {
+		m.order.MoveToFront(elem)
+	}
+	return ok, nil
+}
+
+func (m *memoryStore) MarkProcessed(ctx context.Context, eventID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if elem, ok := m.ids[eventID]; ok {
+		m.order.MoveToFront(elem)
+		return nil
+	}
+	m.ids[eventID] = m.order.PushFront(eventID)
+	if m.order.Len() > m.size {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.ids, oldest.Value.(string))
+	}
+	return nil
+}
+
+// RedisClient is the part of a Redis client a Redis store uses. Adapt the
+// client of your choice to it, so that the SDK does not depend on one.
+type RedisClient interface {
+	// Exists reports whether key exists.
+	Exists(ctx context.Context, key string) (bool, error)
+	// Set sets key, expiring it after ttl.
+	Set(ctx context.Context, key string, ttl time.Duration) error
+}
+
+// NewRedisStore returns a Store that records event IDs in Redis under keys
+// starting with prefix. IDs expire after ttl, which should exceed the
+// platform's retry window.
+func NewRedisStore(client RedisClient, prefix string, ttl time.Duration) Store {
+	return &redisStore{client: client, prefix: prefix, ttl: ttl}
+}
+
+type redisStore struct {
+	client RedisClient
+	prefix string
+	ttl    time.Duration
+}
+
+func (r *redisStore) Processed(ctx context.Context, eventID string) (bool, error) {
+	return r.client.Exists(ctx, r.prefix+eventID)
+}
+
+func (r *redisStore) MarkProcessed(ctx context.Context, eventID string) error {
+	return r.client.Set(ctx, r.prefix+eventID, r.ttl)
+}
+
+// SQLDialect is the SQL dialect of a database a SQL store uses.
+type SQLDialect int
+
+const (
+	// SQLDialectPostgres is PostgreSQL.
+	SQLDialectPostgres SQLDialect = iota
+	// SQLDialectMySQL is MySQL and MariaDB.
+	SQLDialectMySQL
+	// SQLDialectSQLite is SQLite.
+	SQLDialectSQLite
+)
+
+// NewSQLStore returns a Store that records event IDs in a table of db. The
+// table must exist, with a unique event_id column and a processed_at column:
+//
+//	CREATE TABLE webhook_events (
+//	    event_id     VARCHAR(255) PRIMARY KEY,
+//	    processed_at TIMESTAMP NOT NULL
+//	)
+//
+// table is used in queries as is and must not come from untrusted input.
+// Rows are never deleted; prune old ones as needed.
+func NewSQLStore(db *sql.DB, table string, dialect SQLDialect) Store {
+	s := &sqlStore{db: db}
+	switch dialect {
+	case SQLDialectMySQL:
+		s.selectQuery = fmt.Sprintf("SELECT 1 FROM %s WHERE event_id = ?", table)
+		s.insertQuery = fmt.Sprintf("INSERT IGNORE INTO %s (event_id, processed_at) VALUES (?, ?)", table)
+	case SQLDialectSQLite:
+		s.selectQuery = fmt.Sprintf("SELECT 1 FROM %s WHERE event_id = ?", table)
+		s.insertQuery = fmt.Sprintf("INSERT INTO %s (event_id, processed_at) VALUES (?, ?) ON CONFLICT DO NOTHING", table)
+	default:
+		s.selectQuery = fmt.Sprintf("SELECT 1 FROM %s WHERE event_id = $1", table)
+		s.insertQuery = fmt.Sprintf("INSERT INTO %s (event_id, processed_at) VALUES ($1, $2) ON CONFLICT DO NOTHING", table)
+	}
+	return s
+}
+
+type sqlStore struct {
+	db          *sql.DB
+	selectQuery string
+	insertQuery string
+}
+
+func (s *sqlStore) Processed(ctx context.Context, eventID string) (bool, error) {
+	var one int
+	err := s.db.QueryRowContext(ctx, s.selectQuery, eventID).Scan(&one)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("webhooks: failed to look up event: %w", err)
+	}
+	return true, nil
+}
+
+func (s *sqlStore) MarkProcessed(ctx context.Context, eventID string) error {
+	if _, err := s.db.ExecContext(ctx, s.insertQuery, eventID, time.Now().UTC()); err != nil {
+		return fmt.Errorf("webhooks: failed to record event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Package webhooks receives OpeniBank webhook deliveries: it verifies their
+// signatures, decodes their events and dispatches them to typed handlers.
+//
+// Example usage:
+//
+//	handler := webhooks.NewHandler(secret, webhooks.Handlers{
+//	    OnPaymentStatusChanged: func(ctx context.Context, event openibank.PaymentEvent) error {
+//	        return orders.MarkPaid(ctx, event.Data.ID)
+//	    },
+//	})
+//	http.Handle("/webhooks/openibank", handler)
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader is the header that carries a delivery's signature, in the
+// form "t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">". During a
+// secret rotation a delivery may carry several v1 signatures.
+const SignatureHeader = "OpeniBank-Signature"
+
+// DefaultTolerance is how old a delivery's signature may be before it is
+// rejected as a possible replay.
+const DefaultTolerance = 5 * time.Minute
+
+var (
+	// ErrInvalidSignature is returned for a delivery whose signature is
+	// missing, malformed or does not match the secret.
+	ErrInvalidSignature = errors.New("webhooks: invalid signature")
+	// ErrTimestampOutOfTolerance is returned for a delivery signed too long
+	// ago, or too far in the future.
+	ErrTimestampOutOfTolerance = errors.New("webhooks: timestamp outside tolerance")
+)
+
+// Verify checks the signature header of a delivery against its body and the
+// endpoint secret, rejecting signatures older than tolerance. A tolerance of
+// zero skips the age check.
+func Verify(payload []byte, header, secret string, tolerance time.Duration) error {
+	return VerifyAny(payload, header, []string{secret}, tolerance)
+}
+
+// VerifyAny is like Verify but accepts a delivery signed with any of
+// secrets. Empty secrets are ignored, so that a missing secret never
+// verifies a delivery. Use it while rotating a secret, with both the old and the new one,
+// so that deliveries are accepted whichever secret they were signed with.
+func VerifyAny(payload []byte, header string, secrets []string, tolerance time.Duration) error {
+	timestamp, signatures, err := parseSignatureHeader(header)
+	if err != nil {
+		return err
+	}
+	valid := false
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		expected := sign(payload, secret, timestamp)
+		for _, signature := range signatures {
+			if hmac.Equal(signature, expected) {
+				valid = true
+			}
+		}
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 {
+		age := time.Since(time.Unix(timestamp, 0))
+		if age > tolerance || age < -tolerance {
+			return ErrTimestampOutOfTolerance
+		}
+	}
+	return nil
+}
+
+// Sign returns a signature header for payload, signed at the given time.
+// Use it to test webhook handlers.
+func Sign(payload []byte, secret string, at time.Time) string {
+	timestamp := at.Unix()
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(sign(payload, secret, timestamp)))
+}
+
+func sign(payload []byte, secret string, timestamp int64) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// parseSignatureHeader returns the timestamp and v1 signatures of a
+// signature header. Unknown schemes are ignored.
+func parseSignatureHeader(header string) (int64, [][]byte, error) {
+	var timestamp int64
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			t, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return 0, nil, ErrInvalidSignature
+			}
+			timestamp = t
+		case "v1":
+			signature, err := hex.DecodeString(value)
+			if err != nil {
+				continue
+			}
+			signatures = append(signatures, signature)
+		}
+	}
+	if timestamp == 0 || len(signatures) == 0 {
+		return 0, nil, ErrInvalidSignature
+	}
+	return timestamp, signatures, nil
+}
@@ -0,0 +1,48 @@
+package webhooks
+
+import (
+	"testing"
+	"time"
+)
+
+func FuzzWebhookVerify(f *testing.F) {
+	payload := []byte(`{"id":"evt_1","type":"payment.status_changed","data":{}}`)
+	valid := Sign(payload, "whsec_test", time.Unix(1710496800, 0))
+	for _, seed := range []struct {
+		payload []byte
+		header  string
+	}{
+		{payload, valid},
+		{payload, ""},
+		{payload, "t="},
+		{payload, "v1="},
+		{payload, "t=abc,v1=00"},
+		{payload, "t=1710496800"},
+		{payload, "t=1710496800,v1=zz"},
+		{payload, "t=1710496800,v1=" + valid[len(valid)-10:]},
+		{payload, "t=-1,v1=00,v1=,=,,"},
+		{payload, "t=99999999999999999999,v1=00"},
+		{[]byte{}, valid},
+		{[]byte("\xff\xfe"), "t=1710496800,,,v1=" + valid[16:]},
+	} {
+		f.Add(seed.payload, seed.header, "whsec_test")
+	}
+	f.Fuzz(func(t *testing.T, payload []byte, header, secret string) {
+		err := Verify(payload, header, secret, 0)
+		errAny := VerifyAny(payload, header, []string{"whsec_other", secret}, 0)
+		if err == nil && errAny != nil {
+			t.Fatalf("Verify accepted %q but VerifyAny did not: %v", header, errAny)
+		}
+		Verify(payload, header, secret, DefaultTolerance)
+		if secret == "" {
+			if err == nil {
+				t.Fatalf("empty secret verified %q", header)
+			}
+			return
+		}
+		// A signature made now must verify.
+		if err := Verify(payload, Sign(payload, secret, time.Now()), secret, DefaultTolerance); err != nil {
+			t.Fatalf("fresh signature rejected: %v", err)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+// Package webhookstest builds signed webhook deliveries, so that webhook
+// handlers can be tested without the platform sending them.
+//
+// Example usage:
+//
+//	handler := webhooks.NewHandler(secret, handlers)
+//	req := webhookstest.NewSignedEvent(openibank.EventPaymentStatusChanged, payment, secret)
+//	rec := httptest.NewRecorder()
+//	handler.ServeHTTP(rec, req)
+package webhookstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	openibank "github.com/openibank/sdk-go"
+	"github.com/openibank/sdk-go/webhooks"
+)
+
+// Path is the path deliveries are sent to.
+const Path = "/webhooks/openibank"
+
+// Option configures a delivery built by NewSignedEvent.
+type Option func(*delivery)
+
+type delivery struct {
+	id         string
+	apiVersion string
+	signedAt   time.Time
+	created    time.Time
+}
+
+// WithEventID sets the event's ID, such as to test that a handler handles a
+// redelivered event once. The default is a new ID, such as
+// "evt_test_000001".
+func WithEventID(id string) Option {
+	return func(d *delivery) {
+		d.id = id
+	}
+}
+
+// WithAPIVersion sets the API version the event's data is rendered in. The
+// default is "v2".
+func WithAPIVersion(version string) Option {
+	return func(d *delivery) {
+		d.apiVersion = version
+	}
+}
+
+// WithSignedAt sets the time the delivery is signed and the event created,
+// such as to test that stale deliveries are rejected. The default is now.
+func WithSignedAt(at time.Time) Option {
+	return func(d *delivery) {
+		d.signedAt = at
+		d.created = at
+	}
+}
+
+// lastID numbers the event IDs NewSignedEvent assigns.
+var lastID atomic.Int64
+
+// NewSignedEvent returns a webhook delivery of an event of eventType, signed
+// with secret as the platform signs them, as an incoming request for an
+// http.Handler such as webhooks.Handler. data is the event's payload, such
+// as an openibank.Payment; it is encoded to JSON unless it is already JSON,
+// as a []byte or json.RawMessage. NewSignedEvent panics if data cannot be
+// encoded, as a test cannot continue without it.
+func NewSignedEvent(eventType openibank.EventType, data interface{}, secret string, opts ...Option) *http.Request {
+	now := time.Now().UTC().Truncate(time.Second)
+	d := delivery{apiVersion: "v2", signedAt: now, created: now}
+	for _, opt := range opts {
+		opt(&d)
+	}
+	if d.id == "" {
+		d.id = fmt.Sprintf("evt_test_%06d", lastID.Add(1))
+	}
+
+	var raw json.RawMessage
+	switch v := data.(type) {
+	case json.RawMessage:
+		raw = v
+	case []byte:
+		raw = v
+	default:
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			panic(fmt.Sprintf("webhookstest: cannot encode event data: %v", err))
+		}
+		raw = encoded
+	}
+	payload, err := json.Marshal(webhooks.Event{
+		ID:         d.id,
+		Type:       eventType,
+		Created:    d.created,
+		APIVersion: d.apiVersion,
+		Data:       raw,
+	})
+	if err != nil {
+		panic(fmt.Sprintf("webhookstest: cannot encode event: %v", err))
+	}
+
+	req := httptest.NewRequest(http.MethodPost, Path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhooks.SignatureHeader, webhooks.Sign(payload, secret, d.signedAt))
+	return req
+}
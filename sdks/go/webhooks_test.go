@@ -0,0 +1,48 @@
+package openibank
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// countingExecutor answers every call with 200 and an empty object.
+type countingExecutor struct {
+	calls int
+}
+
+func (e *countingExecutor) Execute(ctx context.Context, call *Call) (*Response, error) {
+	e.calls++
+	return &Response{StatusCode: 200, Body: []byte(`{}`)}, nil
+}
+
+func TestWebhookAPIVersionPins(t *testing.T) {
+	ctx := context.Background()
+	executor := &countingExecutor{}
+	client := NewClient(WithExecutor(executor), WithAPIKey("test_key"))
+
+	if _, err := client.Webhooks.Create(ctx, WebhookCreateParams{URL: "https://example.com/hook"}); err != nil {
+		t.Fatalf("Create with the default pin: %v", err)
+	}
+	if _, err := client.Webhooks.Update(ctx, "wh_1", WebhookUpdateParams{APIVersion: String("v2")}); err != nil {
+		t.Fatalf("Update to v2: %v", err)
+	}
+
+	var verr *ValidationError
+	_, err := client.Webhooks.Create(ctx, WebhookCreateParams{URL: "https://example.com/hook", APIVersion: String("v3-preview")})
+	if !errors.As(err, &verr) || verr.Errors[0].Field != "api_version" {
+		t.Errorf("Create with v3-preview: err = %v, want a ValidationError for api_version", err)
+	}
+	_, err = client.Webhooks.Update(ctx, "wh_1", WebhookUpdateParams{APIVersion: String("v1")})
+	if !errors.As(err, &verr) {
+		t.Errorf("Update to v1: err = %v, want a ValidationError", err)
+	}
+
+	pinned := NewClient(WithExecutor(executor), WithAPIKey("test_key"), WithAPIVersion("v3-preview"))
+	if _, err := pinned.Webhooks.Create(ctx, WebhookCreateParams{URL: "https://example.com/hook"}); !errors.As(err, &verr) {
+		t.Errorf("Create on a v3-preview client: err = %v, want a ValidationError", err)
+	}
+	if executor.calls != 2 {
+		t.Errorf("made %d requests, want only the 2 valid ones", executor.calls)
+	}
+}